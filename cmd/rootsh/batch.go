@@ -0,0 +1,67 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+	"github.com/0xrootAnon/0xRootShell/internal/engine"
+)
+
+type jsonResult struct {
+	Command string `json:"command"`
+	Output  string `json:"output"`
+	Error   string `json:"error,omitempty"`
+	Code    int    `json:"code"`
+}
+
+// runBatch runs src as a script without the TUI and returns the process
+// exit code: that of the last command run, or the one given to `exit`.
+// Each command's result is printed as soon as it completes.
+func runBatch(eng *engine.Engine, name, src string, args []string, asJSON bool) int {
+	enc := json.NewEncoder(os.Stdout)
+	res := eng.RunScript(name, src, args, func(line string, res commands.Result) {
+		if asJSON {
+			jr := jsonResult{Command: line, Output: res.Output, Code: res.Code}
+			if res.Err != nil {
+				jr.Error = res.Err.Error()
+			}
+			_ = enc.Encode(jr)
+			return
+		}
+		if res.Output == "" {
+			return
+		}
+		out := os.Stdout
+		if res.Failed() {
+			out = os.Stderr
+		}
+		fmt.Fprintln(out, res.Output)
+	})
+	if c, ok := eng.ExitRequested(); ok {
+		return c
+	}
+	return res.Code
+}
+
+// stdinIsPiped reports whether stdin is a file or pipe rather than a
+// terminal.
+func stdinIsPiped() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice == 0
+}
@@ -0,0 +1,129 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the tests run rootsh itself: with ROOTSH_TEST_MAIN set,
+// the test binary is rootsh.
+func TestMain(m *testing.M) {
+	if os.Getenv("ROOTSH_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// rootsh runs rootsh with args in dir, feeding it stdin, and returns
+// what it printed and its exit code.
+func rootsh(t *testing.T, dir, stdin string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "ROOTSH_TEST_MAIN=1", "HOME="+dir, "XDG_DATA_HOME="+filepath.Join(dir, "xdg"))
+	cmd.Stdin = strings.NewReader(stdin)
+	var out, errOut strings.Builder
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		t.Fatal(err)
+	}
+	return out.String(), errOut.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestBatchCommand(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		cmd, stdout, stderr string
+		code                int
+	}{
+		{"echo hi", "hi\n", "", 0},
+		{"echo a; echo b", "a\nb\n", "", 0},
+		{"cat missing.txt", "", "cat: missing.txt", 1},
+		{"nosuchcommand", "", "nosuchcommand", 127},
+		{"echo a\ncat missing.txt\necho b", "a\nb\n", "cat: missing.txt", 0},
+		{"exit 3", "", "", 3},
+		{"echo a\nexit 4\necho b", "a\n", "", 4},
+		{"cat missing.txt || exit 5", "cat: missing.txt", "", 5},
+	} {
+		stdout, stderr, code := rootsh(t, dir, "", "-c", tc.cmd)
+		if !strings.HasPrefix(stdout, tc.stdout) || (tc.stdout == "" && stdout != "") || !strings.Contains(stderr, tc.stderr) || code != tc.code {
+			t.Errorf("-c %q: stdout %q, stderr %q, code %d; want %q, %q, %d", tc.cmd, stdout, stderr, code, tc.stdout, tc.stderr, tc.code)
+		}
+	}
+}
+
+func TestBatchJSON(t *testing.T) {
+	dir := t.TempDir()
+	stdout, _, code := rootsh(t, dir, "", "--json", "-c", "echo hi\ncat missing.txt\nexit 2")
+	if code != 2 {
+		t.Errorf("exit code %d, want 2", code)
+	}
+	var got []map[string]any
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	for dec.More() {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			t.Fatalf("%v in %q", err, stdout)
+		}
+		got = append(got, m)
+	}
+	if len(got) != 3 {
+		t.Fatalf("%d results, want 3: %q", len(got), stdout)
+	}
+	if want := map[string]any{"command": "echo hi", "output": "hi", "code": 0.0}; !reflect.DeepEqual(got[0], want) {
+		t.Errorf("first result %v, want %v", got[0], want)
+	}
+	if got[1]["command"] != "cat missing.txt" || got[1]["code"] != 1.0 || !strings.HasPrefix(got[1]["error"].(string), "cat: missing.txt") || got[1]["output"] != got[1]["error"] {
+		t.Errorf("second result %v", got[1])
+	}
+	if got[2]["command"] != "exit 2" || got[2]["code"] != 0.0 {
+		t.Errorf("third result %v", got[2])
+	}
+}
+
+func TestBatchScript(t *testing.T) {
+	dir := t.TempDir()
+	script := "echo $1 $#\nfunc greet {\n  echo hello $1\n}\ngreet $2\nexit 6\necho not reached\n"
+	if err := os.WriteFile(filepath.Join(dir, "s.rsh"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code := rootsh(t, dir, "", "s.rsh", "one", "two")
+	if stdout != "one 2\nhello two\n" || code != 6 {
+		t.Errorf("script: stdout %q, stderr %q, code %d", stdout, stderr, code)
+	}
+
+	// The script is read from stdin when no file is named, or for -.
+	stdout, _, code = rootsh(t, dir, "echo piped $#\ncat missing.txt\n")
+	if stdout != "piped 0\n" || code != 1 {
+		t.Errorf("stdin: stdout %q, code %d; want the code of the last command, 1", stdout, code)
+	}
+	stdout, _, code = rootsh(t, dir, "echo piped $1\n", "-", "arg")
+	if stdout != "piped arg\n" || code != 0 {
+		t.Errorf("stdin with -: stdout %q, code %d", stdout, code)
+	}
+
+	_, stderr, code = rootsh(t, dir, "", "missing.rsh")
+	if code != 1 || !strings.Contains(stderr, "missing.rsh") {
+		t.Errorf("missing script: stderr %q, code %d", stderr, code)
+	}
+}
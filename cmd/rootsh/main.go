@@ -13,10 +13,13 @@ package main
 
 import (
 	_ "embed"
+	"flag"
+	"fmt"
 	"io"
 	"log"
 	"os"
 
+	"github.com/0xrootAnon/0xRootShell/internal/engine"
 	"github.com/0xrootAnon/0xRootShell/internal/store"
 	"github.com/0xrootAnon/0xRootShell/internal/ui"
 	tea "github.com/charmbracelet/bubbletea"
@@ -26,6 +29,14 @@ import (
 var embeddedAscii string
 
 func main() {
+	command := flag.String("c", "", "run `command` and exit")
+	asJSON := flag.Bool("json", false, "print results as JSON (one object per command)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: rootsh [-c command] [--json] [script.rsh | -] [args...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	if _, err := os.Stat("data"); os.IsNotExist(err) {
 		_ = os.Mkdir("data", 0755)
 	}
@@ -60,9 +71,35 @@ func main() {
 	}
 	defer st.Close()
 
+	if *command != "" || flag.NArg() > 0 || stdinIsPiped() {
+		name, src, args := "-c", *command, flag.Args()
+		if *command == "" {
+			name = "stdin"
+			var b []byte
+			if flag.NArg() > 0 && flag.Arg(0) != "-" {
+				name = flag.Arg(0)
+				b, err = os.ReadFile(name)
+			} else {
+				b, err = io.ReadAll(os.Stdin)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "rootsh:", err)
+				st.Close()
+				os.Exit(1)
+			}
+			src = string(b)
+			if len(args) > 0 {
+				args = args[1:]
+			}
+		}
+		code := runBatch(engine.NewEngine(st, nil), name, src, args, *asJSON)
+		st.Close()
+		os.Exit(code)
+	}
+
 	m := ui.NewModel(st, asciiArt)
 
-	prog := tea.NewProgram(m, tea.WithAltScreen())
+	prog := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := prog.Run(); err != nil {
 		log.Fatalf("program failed: %v", err)
 	}
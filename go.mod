@@ -7,6 +7,7 @@ require (
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/lipgloss v1.1.0
 	github.com/itchyny/volume-go v0.2.2
+	github.com/muesli/termenv v0.16.0
 	github.com/skratchdot/open-golang v0.0.0-20200116055534-eef842397966
 	go.etcd.io/bbolt v1.4.3
 )
@@ -28,7 +29,6 @@ require (
 	github.com/moutend/go-wca v0.2.0 // indirect
 	github.com/muesli/ansi v0.0.0-20230316100256-276c6243b2f6 // indirect
 	github.com/muesli/cancelreader v0.2.2 // indirect
-	github.com/muesli/termenv v0.16.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	golang.org/x/sys v0.37.0 // indirect
@@ -15,6 +15,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strconv"
@@ -23,20 +24,32 @@ import (
 	"github.com/itchyny/volume-go"
 )
 
-func CmdAudio(args []string) string {
+func init() {
+	Register(Command{
+		Name:        "audio",
+		Aliases:     []string{"vol"},
+		Subcommands: []string{"vol", "volume", "mute", "unmute"},
+		Usage:       "audio <vol <0-100>|mute|unmute>",
+		Summary:     "Set volume, mute/unmute",
+		Examples:    []string{"audio vol 40", "audio mute"},
+		Run:         CmdAudio,
+	})
+}
+
+func CmdAudio(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "audio: expected subcommand e.g. 'audio vol 50' or 'audio mute'"
+		return Fail("audio: expected subcommand e.g. 'audio vol 50' or 'audio mute'")
 	}
 	sub := strings.ToLower(args[0])
 
 	switch sub {
 	case "vol", "volume":
 		if len(args) < 2 {
-			return "audio vol: expected 0-100"
+			return Fail("audio vol: expected 0-100")
 		}
 		n, err := strconv.Atoi(args[1])
 		if err != nil {
-			return "audio vol: invalid number"
+			return Fail("audio vol: invalid number")
 		}
 		if n < 0 {
 			n = 0
@@ -45,40 +58,40 @@ func CmdAudio(args []string) string {
 			n = 100
 		}
 		if err := volume.SetVolume(n); err == nil {
-			return fmt.Sprintf("Volume set to %d%%", n)
+			return OK(fmt.Sprintf("Volume set to %d%%", n))
 		}
 		if p, err := exec.LookPath("nircmd.exe"); err == nil {
 			abs := int(float64(n) / 100.0 * 65535.0)
 			cmd := exec.Command(p, "setsysvolume", strconv.Itoa(abs))
 			if err := cmd.Run(); err == nil {
-				return fmt.Sprintf("Volume set to %d%% (nircmd)", n)
+				return OK(fmt.Sprintf("Volume set to %d%% (nircmd)", n))
 			}
 		}
-		return "audio vol: failed to set volume. Install volume-go support or nircmd (https://www.nirsoft.net/utils/nircmd.html)."
+		return Fail("audio vol: failed to set volume. Install volume-go support or nircmd (https://www.nirsoft.net/utils/nircmd.html).")
 
 	case "mute":
 		if err := volume.Mute(); err == nil {
-			return "Audio muted"
+			return OK("Audio muted")
 		}
 		if p, err := exec.LookPath("nircmd.exe"); err == nil {
 			_ = exec.Command(p, "mutesysvolume", "1").Run()
-			return "Audio muted (nircmd)"
+			return OK("Audio muted (nircmd)")
 		}
 		_ = exec.Command("powershell", "-Command", "Set-AudioDevice -Mute $true").Run()
-		return "Audio mute attempted (PowerShell fallback)."
+		return OK("Audio mute attempted (PowerShell fallback).")
 
 	case "unmute":
 		if err := volume.Unmute(); err == nil {
-			return "Audio unmuted"
+			return OK("Audio unmuted")
 		}
 		if p, err := exec.LookPath("nircmd.exe"); err == nil {
 			_ = exec.Command(p, "mutesysvolume", "0").Run()
-			return "Audio unmuted (nircmd)"
+			return OK("Audio unmuted (nircmd)")
 		}
 		_ = exec.Command("powershell", "-Command", "Set-AudioDevice -Mute $false").Run()
-		return "Audio unmute attempted (PowerShell fallback)."
+		return OK("Audio unmute attempted (PowerShell fallback).")
 
 	default:
-		return "audio: unknown subcommand. Try 'audio vol <0-100>' or 'audio mute'"
+		return Fail("audio: unknown subcommand. Try 'audio vol <0-100>' or 'audio mute'")
 	}
 }
@@ -11,6 +11,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -19,6 +20,35 @@ import (
 	"time"
 )
 
+func init() {
+	Register(Command{
+		Name:        "clear",
+		Subcommands: []string{"browser"},
+		Usage:       "clear browser history",
+		Summary:     "Clear browser history",
+		Run: func(ctx context.Context, args []string) Result {
+			if len(args) >= 2 && strings.ToLower(args[0]) == "browser" && strings.ToLower(args[1]) == "history" {
+				return CmdClearBrowserHistory(ctx, args[2:])
+			}
+			return Fail("clear: unknown target. Try 'clear browser history' or use your terminal to clear the screen.")
+		},
+		DryRun: true,
+	})
+	Register(Command{
+		Name:        "browse",
+		Subcommands: []string{"private"},
+		Usage:       "browse private [url]",
+		Summary:     "Open a private browser window",
+		Examples:    []string{"browse private", "browse private example.com"},
+		Run: func(ctx context.Context, args []string) Result {
+			if len(args) > 0 && strings.ToLower(args[0]) == "private" {
+				return CmdBrowsePrivate(ctx, args[1:])
+			}
+			return Fail("browse: unknown target. Try 'browse private'.")
+		},
+	})
+}
+
 func killBrowserProcesses(names []string) {
 	for _, n := range names {
 		exec.Command("taskkill", "/F", "/IM", n).Run()
@@ -26,22 +56,6 @@ func killBrowserProcesses(names []string) {
 	time.Sleep(400 * time.Millisecond)
 }
 
-func tryRemove(path string) error {
-	if _, err := os.Stat(path); err != nil {
-		return err
-	}
-	if err := os.Remove(path); err == nil {
-		return nil
-	} else {
-		f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0644)
-		if err != nil {
-			return err
-		}
-		f.Close()
-		return nil
-	}
-}
-
 func expandVars(p string) string {
 	return os.ExpandEnv(p)
 }
@@ -111,28 +125,16 @@ func findExecutableCandidates(cands []string) string {
 	return ""
 }
 
-func removeFiles(paths []string) (deleted []string, failed []string) {
-	for _, p := range paths {
-		if p == "" {
-			continue
-		}
-		if tryRemove(p) == nil {
-			deleted = append(deleted, p)
-			continue
-		}
-		if !fileExists(p) {
-			continue
-		}
-		failed = append(failed, p)
-	}
-	return
-}
-
-func CmdClearBrowserHistory(args []string) string {
+// CmdClearBrowserHistory moves the history databases of the browsers it
+// knows to the trash, stopping the browsers first so the files are free.
+func CmdClearBrowserHistory(ctx context.Context, args []string) Result {
 	var out []string
-	browsersKilled := []string{"chrome.exe", "msedge.exe", "brave.exe", "vivaldi.exe", "opera.exe", "firefox.exe"}
-	killBrowserProcesses(browsersKilled)
-	out = append(out, "attempted to stop browser processes")
+	op := beginOp(ctx)
+	if !op.dry {
+		browsersKilled := []string{"chrome.exe", "msedge.exe", "brave.exe", "vivaldi.exe", "opera.exe", "firefox.exe"}
+		killBrowserProcesses(browsersKilled)
+		out = append(out, "attempted to stop browser processes")
+	}
 
 	local := os.Getenv("LOCALAPPDATA")
 	app := os.Getenv("APPDATA")
@@ -144,28 +146,33 @@ func CmdClearBrowserHistory(args []string) string {
 	profileCandidates = append(profileCandidates, gatherChromeLikeHistoryPaths(filepath.Join(app, "Opera Software", "Opera Stable"))...)
 	firefoxPaths := gatherFirefoxHistoryPaths(filepath.Join(app, "Mozilla", "Firefox", "Profiles"))
 	profileCandidates = append(profileCandidates, firefoxPaths...)
-	deleted, failed := removeFiles(profileCandidates)
-	if len(deleted) > 0 {
-		out = append(out, "removed:")
-		for _, d := range deleted {
-			out = append(out, "  "+d)
+
+	var removed, failed []string
+	for _, p := range profileCandidates {
+		if p == "" || !fileExists(p) {
+			continue
 		}
-	} else {
-		out = append(out, "no history files removed")
-	}
-	if len(failed) > 0 {
-		out = append(out, "failed to remove:")
-		for _, f := range failed {
-			out = append(out, "  "+f)
+		if err := op.remove(p); err != nil {
+			failed = append(failed, "  "+p+": "+err.Error())
+			continue
 		}
+		removed = append(removed, "  "+p)
 	}
-	if len(deleted) == 0 && len(failed) == 0 {
+	if len(removed) == 0 && len(failed) == 0 {
 		out = append(out, "no supported browser history files found")
 	}
-	return strings.Join(out, "\n")
+	if len(removed) > 0 && !op.dry {
+		out = append(out, "moved to the trash ('trash empty' erases them for good):")
+		out = append(out, removed...)
+	}
+	if len(failed) > 0 {
+		out = append(out, "failed to remove:")
+		out = append(out, failed...)
+	}
+	return op.done(out, len(failed) > 0)
 }
 
-func CmdBrowsePrivate(args []string) string {
+func CmdBrowsePrivate(ctx context.Context, args []string) Result {
 	url := ""
 	if len(args) > 0 {
 		url = args[0]
@@ -270,21 +277,21 @@ func CmdBrowsePrivate(args []string) string {
 		cmd := exec.Command(exe, p.args(url)...)
 		if err := cmd.Start(); err == nil {
 			if url == "" {
-				return fmt.Sprintf("Launched %s in private mode", p.name)
+				return OK(fmt.Sprintf("Launched %s in private mode", p.name))
 			}
-			return fmt.Sprintf("Launched %s in private mode with %s", p.name, url)
+			return OK(fmt.Sprintf("Launched %s in private mode with %s", p.name, url))
 		}
 	}
 
 	if url == "" {
 		if err := exec.Command("cmd", "/C", "start", "msedge", "--inprivate").Start(); err == nil {
-			return "Launched default browser in private mode"
+			return OK("Launched default browser in private mode")
 		}
-		return "Could not launch a private browser window"
+		return Fail("Could not launch a private browser window")
 	}
 
 	if err := exec.Command("cmd", "/C", "start", url).Start(); err == nil {
-		return "Opened URL in default browser (private mode not available)"
+		return OK("Opened URL in default browser (private mode not available)")
 	}
-	return "Could not open URL"
+	return Fail("Could not open URL")
 }
@@ -12,6 +12,7 @@
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -26,6 +27,36 @@ import (
 	"github.com/skratchdot/open-golang/open"
 )
 
+func init() {
+	Register(Command{
+		Name:     "launch",
+		Aliases:  []string{"openapp", "start"},
+		Usage:    "launch <app>",
+		Summary:  "Launch application or URL",
+		Examples: []string{"launch chrome", "launch https://example.com"},
+		Run:      CmdLaunch,
+	})
+	Register(Command{
+		Name:     "open",
+		Usage:    "open <file|url>",
+		Summary:  "Open file or URL",
+		Examples: []string{"open ~/Downloads", "open reddit.com"},
+		Run:      CmdOpen,
+	})
+	Register(Command{
+		Name:     "find",
+		Aliases:  []string{"searchfile"},
+		Usage:    "find <pattern> [--all]",
+		Summary:  "Fuzzy file search",
+		Examples: []string{"find resume", "find invoice --all", "find '*.log'"},
+		Async:    true,
+		Pending:  "Searching... results will appear below when ready.",
+		Flags:    []string{"--all"},
+		Start:    StartFind,
+		Run:      CmdFind,
+	})
+}
+
 /*func expandPath(p string) string {
 	if p == "" {
 		return p
@@ -86,86 +117,88 @@ func safeExists(path string) bool {
 	return err == nil
 }
 
-func CmdLaunch(args []string) string {
+func CmdLaunch(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "launch: expected an app name or URL, e.g. `launch chrome` or `launch https://example.com`"
+		return Fail("launch: expected an app name or URL, e.g. `launch chrome` or `launch https://example.com`")
 	}
 	target := strings.Join(args, " ")
 
 	if looksLikeURL(target) {
 		target = prependHTTPSIfNeeded(target)
 		if err := runOpen(target); err != nil {
-			return "launch error: " + err.Error()
+			return Fail("launch error: " + err.Error())
 		}
-		return fmt.Sprintf("Launching %s...", target)
+		return OK(fmt.Sprintf("Launching %s...", target))
 	}
 
-	targetExpanded := expandPath(target)
+	targetExpanded, err := expandPath(ctx, target)
+	if err != nil {
+		return Fail("launch: " + err.Error())
+	}
 
 	if strings.ContainsAny(targetExpanded, `/\`) || safeExists(targetExpanded) {
-		if !filepath.IsAbs(targetExpanded) {
-			if wd, err := os.Getwd(); err == nil {
-				targetExpanded = filepath.Join(wd, targetExpanded)
-			}
-		}
 		if safeExists(targetExpanded) {
 			if err := runOpen(targetExpanded); err != nil {
-				return "launch error: " + err.Error()
+				return Fail("launch error: " + err.Error())
 			}
-			return fmt.Sprintf("Launching %s...", targetExpanded)
+			return OK(fmt.Sprintf("Launching %s...", targetExpanded))
 		}
 	}
 
 	parts := strings.Fields(target)
-	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd := command(ctx, parts[0], parts[1:]...)
 	if err := cmd.Start(); err != nil {
 		if err2 := runOpen(target); err2 == nil {
-			return fmt.Sprintf("Launching %s...", target)
+			return OK(fmt.Sprintf("Launching %s...", target))
 		}
-		return "launch error: " + err.Error()
+		return Fail("launch error: " + err.Error())
 	}
-	return fmt.Sprintf("Launching %s...", target)
+	return OK(fmt.Sprintf("Launching %s...", target))
 }
 
-func CmdOpen(args []string) string {
+func CmdOpen(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "open: expected a file or url, e.g. `open ~/Downloads` or `open reddit.com`"
+		return Fail("open: expected a file or url, e.g. `open ~/Downloads` or `open reddit.com`")
 	}
 	target := strings.Join(args, " ")
-	target = expandPath(target)
+	if p, err := expandPath(ctx, target); err == nil {
+		target = p
+	} else if !looksLikeURL(target) {
+		return Fail("open: " + err.Error())
+	}
 
 	if strings.Contains(target, "://") {
 		if err := runOpen(target); err != nil {
-			return "open error: " + err.Error()
+			return Fail("open error: " + err.Error())
 		}
-		return fmt.Sprintf("Opened %s", target)
+		return OK(fmt.Sprintf("Opened %s", target))
 	}
 
 	if filepath.IsAbs(target) || strings.ContainsAny(target, `/\`) {
-		if !filepath.IsAbs(target) {
-			if wd, err := os.Getwd(); err == nil {
-				target = filepath.Join(wd, target)
-			}
-		}
 		if safeExists(target) {
 			if err := runOpen(target); err != nil {
-				return "open error: " + err.Error()
+				return Fail("open error: " + err.Error())
 			}
-			return fmt.Sprintf("Opened %s", target)
+			return OK(fmt.Sprintf("Opened %s", target))
 		}
-		return "open error: target not found"
+		return Fail("open error: target not found")
 	}
 
-	if wd, err := os.Getwd(); err == nil {
+	if wd := EnvOf(ctx).Dir; wd != "" {
 		try := filepath.Join(wd, target)
 		if safeExists(try) {
 			if err := runOpen(try); err != nil {
-				return "open error: " + err.Error()
+				return Fail("open error: " + err.Error())
 			}
-			return fmt.Sprintf("Opened %s", try)
+			return OK(fmt.Sprintf("Opened %s", try))
 		}
 	}
 
+	// A sandboxed session only opens what it was given a path to.
+	if EnvOf(ctx).Policy.Sandboxed() {
+		return Failf("open: '%s' not found", target)
+	}
+
 	if home, err := os.UserHomeDir(); err == nil {
 		common := []string{
 			filepath.Join(home, "Desktop"),
@@ -176,9 +209,9 @@ func CmdOpen(args []string) string {
 			try := filepath.Join(d, target)
 			if safeExists(try) {
 				if err := runOpen(try); err != nil {
-					return "open error: " + err.Error()
+					return Fail("open error: " + err.Error())
 				}
-				return fmt.Sprintf("Opened %s", try)
+				return OK(fmt.Sprintf("Opened %s", try))
 			}
 		}
 		if od := os.Getenv("OneDrive"); od != "" {
@@ -190,9 +223,9 @@ func CmdOpen(args []string) string {
 			for _, try := range tries {
 				if safeExists(try) {
 					if err := runOpen(try); err != nil {
-						return "open error: " + err.Error()
+						return Fail("open error: " + err.Error())
 					}
-					return fmt.Sprintf("Opened %s", try)
+					return OK(fmt.Sprintf("Opened %s", try))
 				}
 			}
 		}
@@ -214,9 +247,9 @@ func CmdOpen(args []string) string {
 			})
 			if found != "" {
 				if err := runOpen(found); err != nil {
-					return "open error: " + err.Error()
+					return Fail("open error: " + err.Error())
 				}
-				return fmt.Sprintf("Opened %s", found)
+				return OK(fmt.Sprintf("Opened %s", found))
 			}
 		}
 	}
@@ -230,25 +263,48 @@ func CmdOpen(args []string) string {
 	l := strings.ToLower(target)
 	if p, ok := aliases[l]; ok && safeExists(p) {
 		if err := runOpen(p); err != nil {
-			return "open error: " + err.Error()
+			return Fail("open error: " + err.Error())
 		}
-		return fmt.Sprintf("Opened %s", p)
+		return OK(fmt.Sprintf("Opened %s", p))
 	}
 
 	if looksLikeURL(target) {
 		target = prependHTTPSIfNeeded(target)
 		if err := runOpen(target); err != nil {
-			return "open error: " + err.Error()
+			return Fail("open error: " + err.Error())
 		}
-		return fmt.Sprintf("Opened %s", target)
+		return OK(fmt.Sprintf("Opened %s", target))
+	}
+
+	return Failf("open: '%s' not found. Try `find %s` or provide a full/relative path.", target, target)
+}
+
+func StartFind(ctx context.Context, args []string, ch chan string) error {
+	query := strings.Join(args, " ")
+	if query == "" {
+		query = "(empty)"
+	}
+	ch <- sanitizeOutput(fmt.Sprintf("Searching for: %s", query))
+	res := findFiles(ctx, args)
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
+	if res.Failed() {
+		return res.Err
+	}
+	ch <- sanitizeOutput(fmt.Sprintf("=== Search results for: %s ===\n%s\n=== End results ===", query, res.Output))
+	return nil
+}
 
-	return fmt.Sprintf("open: '%s' not found. Try `find %s` or provide a full/relative path.", target, target)
+func CmdFind(ctx context.Context, args []string) Result {
+	return findFiles(ctx, args)
 }
 
-func CmdFind(args []string) string {
+// findFiles walks the working directory, then the home directory (or the
+// whole disk with --all), stopping early when ctx is cancelled.
+func findFiles(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "find: expected search pattern, e.g. `find resume`"
+		return Fail("find: expected search pattern, e.g. `find resume`")
 	}
 
 	all := false
@@ -262,17 +318,26 @@ func CmdFind(args []string) string {
 	}
 	pattern := strings.ToLower(strings.Join(parts, " "))
 	if pattern == "" {
-		return "find: empty pattern"
+		return Fail("find: empty pattern")
+	}
+	// A pattern with wildcards must match the whole name; any other is
+	// looked for inside names.
+	match := func(name string) bool { return strings.Contains(name, pattern) }
+	if strings.ContainsAny(pattern, "*?[") {
+		match = func(name string) bool {
+			ok, _ := filepath.Match(pattern, name)
+			return ok
+		}
 	}
 
-	wd, _ := os.Getwd()
+	wd := EnvOf(ctx).Dir
 	if len(parts) > 0 && strings.ContainsAny(parts[0], `/\`) {
-		candidate := expandPath(parts[0])
-		if !filepath.IsAbs(candidate) {
-			candidate = filepath.Join(wd, candidate)
+		candidate, err := expandPath(ctx, parts[0])
+		if err != nil {
+			return Fail("find: " + err.Error())
 		}
 		if safeExists(candidate) {
-			return candidate
+			return OK(candidate)
 		}
 	}
 
@@ -281,6 +346,9 @@ func CmdFind(args []string) string {
 		limit := 200
 		start := time.Now()
 		_ = filepath.WalkDir(wd, func(path string, d os.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return filepath.SkipAll
+			}
 			if time.Since(start) > 3*time.Second {
 				return filepath.SkipDir
 			}
@@ -288,7 +356,7 @@ func CmdFind(args []string) string {
 				return nil
 			}
 			name := strings.ToLower(d.Name())
-			if strings.Contains(name, pattern) {
+			if match(name) {
 				quickResults = append(quickResults, path)
 				if len(quickResults) >= limit {
 					return filepath.SkipDir
@@ -303,7 +371,7 @@ func CmdFind(args []string) string {
 			if len(quickResults) > 100 {
 				quickResults = quickResults[:100]
 			}
-			return strings.Join(quickResults, "\n")
+			return OK(strings.Join(quickResults, "\n"))
 		}
 	}
 
@@ -319,7 +387,15 @@ func CmdFind(args []string) string {
 			root = h
 		}
 		if len(parts) > 0 && strings.ContainsAny(parts[0], `/\`) {
-			root = expandPath(parts[0])
+			root, _ = expandPath(ctx, parts[0])
+		}
+	}
+	// In a sandbox the search covers the allowed roots instead.
+	roots := []string{root}
+	if pol := EnvOf(ctx).Policy; pol.Sandboxed() && (all || pol.CheckRoots(root) != nil) {
+		roots = roots[:0]
+		for _, r := range pol.Roots {
+			roots = append(roots, policyPath(r))
 		}
 	}
 
@@ -331,35 +407,43 @@ func CmdFind(args []string) string {
 	results := []string{}
 	limit := 500
 	start := time.Now()
-	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if time.Since(start) > timeout {
-			return filepath.SkipDir
-		}
-		if err != nil {
-			return nil
-		}
-		name := strings.ToLower(d.Name())
-		if strings.Contains(name, pattern) {
-			results = append(results, path)
-			if len(results) >= limit {
+	for _, root := range roots {
+		_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return filepath.SkipAll
+			}
+			if time.Since(start) > timeout {
 				return filepath.SkipDir
 			}
-		}
-		if utf8.RuneCountInString(path) > 400 {
+			if err != nil {
+				return nil
+			}
+			name := strings.ToLower(d.Name())
+			if match(name) {
+				results = append(results, path)
+				if len(results) >= limit {
+					return filepath.SkipDir
+				}
+			}
+			if utf8.RuneCountInString(path) > 400 {
+				return nil
+			}
 			return nil
-		}
-		return nil
-	})
+		})
+	}
 
+	if ctx.Err() != nil {
+		return Fail("find: cancelled")
+	}
 	if len(results) == 0 {
 		if !all {
-			return "No results found. Try: `find <pattern> --all` to search entire disk (may be slow)."
+			return Fail("No results found. Try: `find <pattern> --all` to search entire disk (may be slow).")
 		}
-		return "No results found."
+		return Fail("No results found.")
 	}
 
 	if len(results) > 200 {
 		results = results[:200]
 	}
-	return strings.Join(results, "\n")
+	return OK(strings.Join(results, "\n"))
 }
@@ -11,6 +11,7 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -35,15 +36,26 @@ func parseAmountAndToken(tok string) (float64, string, bool) {
 	return 0, "", false
 }
 
-func CmdConvert(args []string) string {
+func init() {
+	Register(Command{
+		Name:     "convert",
+		Aliases:  []string{"currency"},
+		Usage:    "convert <amount><from> to <to>",
+		Summary:  "Currency / unit conversions",
+		Examples: []string{"convert 100usd to eur"},
+		Run:      CmdConvert,
+	})
+}
+
+func CmdConvert(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "convert: usage: convert <amount> <from> to <to>  e.g. `convert 100 usd to inr`"
+		return Fail("convert: usage: convert <amount> <from> to <to>  e.g. `convert 100 usd to inr`")
 	}
 
 	joined := strings.ToLower(strings.Join(args, " "))
 	toks := strings.Fields(joined)
 	if len(toks) == 0 {
-		return "convert: empty input"
+		return Fail("convert: empty input")
 	}
 
 	amount := 1.0
@@ -63,7 +75,7 @@ func CmdConvert(args []string) string {
 			to = toks[start]
 		}
 		if to == "" {
-			return "convert: could not determine target currency"
+			return Fail("convert: could not determine target currency")
 		}
 		return convertWithMultiFallback(amount, cur, strings.ToUpper(strings.Trim(to, " ,.")))
 	}
@@ -92,7 +104,7 @@ func CmdConvert(args []string) string {
 			from = toks[start]
 			to = toks[start+1]
 		} else {
-			return "convert: could not parse currencies. Usage: convert 100 usd to inr"
+			return Fail("convert: could not parse currencies. Usage: convert 100 usd to inr")
 		}
 	}
 
@@ -105,13 +117,13 @@ func CmdConvert(args []string) string {
 	to = strings.ToUpper(strings.Trim(to, " ,."))
 
 	if len(from) < 3 || len(to) < 3 {
-		return "convert: currency codes must be at least 3 letters (e.g. USD, INR)"
+		return Fail("convert: currency codes must be at least 3 letters (e.g. USD, INR)")
 	}
 
 	return convertWithMultiFallback(amount, from, to)
 }
 
-func convertWithMultiFallback(amount float64, from, to string) string {
+func convertWithMultiFallback(amount float64, from, to string) Result {
 	cacheKey := fmt.Sprintf("rate:%s:%s", from, to)
 
 	if data, ok := readCache(cacheKey); ok {
@@ -120,7 +132,7 @@ func convertWithMultiFallback(amount float64, from, to string) string {
 		}
 		if err := json.Unmarshal(data, &cached); err == nil && cached.Rate > 0 {
 			result := amount * cached.Rate
-			return fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g) (cached)", amount, from, result, to, cached.Rate)
+			return OK(fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g) (cached)", amount, from, result, to, cached.Rate))
 		}
 	}
 
@@ -152,7 +164,7 @@ func convertWithMultiFallback(amount float64, from, to string) string {
 			if jb, err := json.Marshal(cached); err == nil {
 				_ = writeCache(cacheKey, jb, 3600)
 			}
-			return fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g)", res.Query.Amount, strings.ToUpper(res.Query.From), res.Result, strings.ToUpper(res.Query.To), res.Info.Rate)
+			return OK(fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g)", res.Query.Amount, strings.ToUpper(res.Query.From), res.Result, strings.ToUpper(res.Query.To), res.Info.Rate))
 		}
 		diags = append(diags, diag{Provider: "exchangerate.convert", Status: sc, Err: "parse/falsy-success"})
 	} else {
@@ -180,7 +192,7 @@ func convertWithMultiFallback(amount float64, from, to string) string {
 					_ = writeCache(cacheKey, jb, 3600)
 				}
 				result := amount * rate
-				return fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g)", amount, from, result, to, rate)
+				return OK(fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g)", amount, from, result, to, rate))
 			}
 		}
 		diags = append(diags, diag{Provider: "exchangerate.latest", Status: sc2, Err: "parse/no-rate"})
@@ -208,7 +220,7 @@ func convertWithMultiFallback(amount float64, from, to string) string {
 					_ = writeCache(cacheKey, jb, 3600)
 				}
 				result := amount * rate
-				return fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g)", amount, from, result, to, rate)
+				return OK(fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g)", amount, from, result, to, rate))
 			}
 		}
 		diags = append(diags, diag{Provider: "frankfurter", Status: sc3, Err: "parse/no-rate"})
@@ -235,7 +247,7 @@ func convertWithMultiFallback(amount float64, from, to string) string {
 					_ = writeCache(cacheKey, jb, 3600)
 				}
 				result := amount * rate
-				return fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g)", amount, from, result, to, rate)
+				return OK(fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g)", amount, from, result, to, rate))
 			}
 		}
 		diags = append(diags, diag{Provider: "open.er-api", Status: sc4, Err: "parse/no-rate"})
@@ -253,7 +265,7 @@ func convertWithMultiFallback(amount float64, from, to string) string {
 		}
 		if err := json.Unmarshal(data, &cached); err == nil && cached.Rate > 0 {
 			result := amount * cached.Rate
-			return fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g) (cached)", amount, from, result, to, cached.Rate)
+			return OK(fmt.Sprintf("%.6g %s = %.6g %s  (rate = %.6g) (cached)", amount, from, result, to, cached.Rate))
 		}
 	}
 
@@ -262,7 +274,7 @@ func convertWithMultiFallback(amount float64, from, to string) string {
 		parts = append(parts, fmt.Sprintf("%s(status=%d err=%s)", d.Provider, d.Status, truncate(d.Err, 120)))
 	}
 	parts = append(parts, "Try again or enable DEBUG to see http logs in data/debug.log")
-	return strings.Join(parts, " ")
+	return OK(strings.Join(parts, " "))
 }
 
 func truncate(s string, n int) string {
@@ -12,6 +12,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"runtime"
@@ -19,61 +20,73 @@ import (
 	"strings"
 )
 
-func CmdDisplay(args []string) string {
+func init() {
+	Register(Command{
+		Name:        "display",
+		Aliases:     []string{"brightness", "screen"},
+		Subcommands: []string{"bright", "brightness"},
+		Usage:       "display bright <0-100>",
+		Summary:     "Set screen brightness",
+		Examples:    []string{"display bright 60"},
+		Run:         CmdDisplay,
+	})
+}
+
+func CmdDisplay(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "display: expected subcommand 'bright <0-100>'"
+		return Fail("display: expected subcommand 'bright <0-100>'")
 	}
 	sub := strings.ToLower(args[0])
 	switch sub {
 	case "bright", "brightness":
 		if len(args) < 2 {
-			return "display bright: expected value 0-100"
+			return Fail("display bright: expected value 0-100")
 		}
 		v, err := strconv.Atoi(args[1])
 		if err != nil || v < 0 || v > 100 {
-			return "display bright: value must be 0-100"
+			return Fail("display bright: value must be 0-100")
 		}
 		return setBrightness(v)
 	default:
-		return "display: unknown subcommand. Try `display bright <0-100>`."
+		return Fail("display: unknown subcommand. Try `display bright <0-100>`.")
 	}
 }
 
-func setBrightness(percent int) string {
+func setBrightness(percent int) Result {
 	switch runtime.GOOS {
 	case "windows":
 		script := fmt.Sprintf("(Get-WmiObject -Namespace root/WMI -Class WmiMonitorBrightnessMethods).WmiSetBrightness(1,%d)", percent)
 		cmd := exec.Command("powershell", "-NoProfile", "-Command", script)
 		if out, err := cmd.CombinedOutput(); err == nil {
-			return fmt.Sprintf("Brightness set to %d%% (PowerShell).", percent)
+			return OK(fmt.Sprintf("Brightness set to %d%% (PowerShell).", percent))
 		} else {
-			return "display bright error: " + err.Error() + " — " + strings.TrimSpace(string(out)) + ". If this fails, consider vendor utilities or run with elevated privileges."
+			return Fail("display bright error: " + err.Error() + " — " + strings.TrimSpace(string(out)) + ". If this fails, consider vendor utilities or run with elevated privileges.")
 		}
 	case "darwin":
 		if p, _ := exec.LookPath("brightness"); p != "" {
 			val := fmt.Sprintf("%f", float64(percent)/100.0)
 			cmd := exec.Command(p, val)
 			if out, err := cmd.CombinedOutput(); err == nil {
-				return fmt.Sprintf("Brightness set to %d%% (brightness).", percent)
+				return OK(fmt.Sprintf("Brightness set to %d%% (brightness).", percent))
 			} else {
-				return "display bright error: " + err.Error() + " — " + strings.TrimSpace(string(out))
+				return Fail("display bright error: " + err.Error() + " — " + strings.TrimSpace(string(out)))
 			}
 		}
-		return "display bright: macOS requires a helper (try `brew install brightness`) or use System Settings."
+		return Fail("display bright: macOS requires a helper (try `brew install brightness`) or use System Settings.")
 	default:
 		if p, _ := exec.LookPath("brightnessctl"); p != "" {
 			val := fmt.Sprintf("%d%%", percent)
 			cmd := exec.Command(p, "set", val)
 			if out, err := cmd.CombinedOutput(); err == nil {
-				return fmt.Sprintf("Brightness set to %d%% (brightnessctl).", percent)
+				return OK(fmt.Sprintf("Brightness set to %d%% (brightnessctl).", percent))
 			} else {
-				return "display bright error: " + err.Error() + " — " + strings.TrimSpace(string(out))
+				return Fail("display bright error: " + err.Error() + " — " + strings.TrimSpace(string(out)))
 			}
 		}
 		if p, _ := exec.LookPath("xrandr"); p != "" {
 			out, err := exec.Command(p, "--query").CombinedOutput()
 			if err != nil {
-				return "display bright error: cannot query displays: " + err.Error()
+				return Fail("display bright error: cannot query displays: " + err.Error())
 			}
 			lines := strings.Split(string(out), "\n")
 			var outName string
@@ -85,17 +98,17 @@ func setBrightness(percent int) string {
 				}
 			}
 			if outName == "" {
-				return "display bright: cannot detect output via xrandr"
+				return Fail("display bright: cannot detect output via xrandr")
 			}
 			f := float64(percent) / 100.0
 			cmd := exec.Command(p, "--output", outName, "--brightness", fmt.Sprintf("%f", f))
 			if o, err := cmd.CombinedOutput(); err == nil {
 				_ = o
-				return fmt.Sprintf("Brightness set to %d%% (xrandr on %s).", percent, outName)
+				return OK(fmt.Sprintf("Brightness set to %d%% (xrandr on %s).", percent, outName))
 			} else {
-				return "display bright error: " + err.Error() + " — " + strings.TrimSpace(string(o))
+				return Fail("display bright error: " + err.Error() + " — " + strings.TrimSpace(string(o)))
 			}
 		}
-		return "display bright: no supported tool found (install brightnessctl or use xrandr)."
+		return Fail("display bright: no supported tool found (install brightnessctl or use xrandr).")
 	}
 }
@@ -0,0 +1,137 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Env is what a command runs in: its working directory, the environment
+// given to programs it starts, and where to print lines before its
+// result. The engine attaches one to the context of every command, so
+// several engines can share a process and a later `cd` never affects a
+// command that is already running. Commands must not use os.Getwd or
+// os.Chdir.
+type Env struct {
+	Dir     string
+	Environ []string
+	Stdout  chan<- string
+
+	// Line is the command line being run and Journal, if set, records
+	// file changes so they can be undone. With DryRun, commands that
+	// change files only report what they would change. Policy, if set,
+	// says which paths may be used and changed, and Audit records where
+	// it was applied. Files, if set, collects the paths commands change.
+	// Ask, if set, puts a question to the user; use the Ask function.
+	// Confirmed is set when the user has confirmed the line, with
+	// --confirm or when asked, so the policy need not ask again.
+	Line      string
+	Journal   *Journal
+	DryRun    bool
+	Policy    *Policy
+	Audit     func(kind, detail string)
+	Files     *FileLog
+	Ask       func(ctx context.Context, p Prompt) (string, error)
+	Confirmed bool
+}
+
+// FileLog is the list of paths a command line changed, in the order it
+// first changed them. It is safe for concurrent use, and a nil FileLog
+// ignores what it is given.
+type FileLog struct {
+	mu    sync.Mutex
+	paths []string
+	seen  map[string]bool
+}
+
+func (l *FileLog) Add(paths ...string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.seen == nil {
+		l.seen = map[string]bool{}
+	}
+	for _, p := range paths {
+		if p != "" && !l.seen[p] {
+			l.seen[p] = true
+			l.paths = append(l.paths, p)
+		}
+	}
+}
+
+func (l *FileLog) Paths() []string {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.paths...)
+}
+
+type envKey struct{}
+
+func WithEnv(ctx context.Context, env *Env) context.Context {
+	return context.WithValue(ctx, envKey{}, env)
+}
+
+// EnvOf returns the Env attached to ctx, or one for the process's own
+// directory and environment.
+func EnvOf(ctx context.Context) *Env {
+	if env, ok := ctx.Value(envKey{}).(*Env); ok {
+		return env
+	}
+	wd, _ := os.Getwd()
+	return &Env{Dir: wd, Environ: os.Environ()}
+}
+
+// Getenv looks name up in the environment of env.
+func (env *Env) Getenv(name string) string {
+	prefix := name + "="
+	for i := len(env.Environ) - 1; i >= 0; i-- {
+		if strings.HasPrefix(env.Environ[i], prefix) {
+			return env.Environ[i][len(prefix):]
+		}
+	}
+	return ""
+}
+
+// Print shows line ahead of the command's result, if the caller has a
+// sink for it.
+func (env *Env) Print(line string) {
+	if env.Stdout != nil {
+		env.Stdout <- line
+	}
+}
+
+// audit records a policy event if the caller keeps an audit trail.
+func (env *Env) audit(kind, detail string) {
+	if env.Audit != nil {
+		env.Audit(kind, detail)
+	}
+}
+
+// command prepares an external program to run in the directory and
+// environment of the command that starts it. The program is not tied to
+// ctx, so apps that are launched keep running after the command returns.
+func command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	env := EnvOf(ctx)
+	cmd := exec.Command(name, args...)
+	cmd.Dir = env.Dir
+	cmd.Env = env.Environ
+	return cmd
+}
@@ -13,64 +13,97 @@ package commands
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
-func CmdFile(args []string) string {
+func init() {
+	Register(Command{
+		Name:        "file",
+		Aliases:     []string{"files"},
+		Subcommands: []string{"move", "rename", "clean", "open"},
+		Usage:       "file <move|rename|clean|open> ...",
+		Summary:     "File operations",
+		Examples:    []string{"file move a.txt docs/", "file rename *.jpg photo_# --dry-run", "file clean temp", "file clean temp --apply --days 30"},
+		Run:         CmdFile,
+		DryRun:      true,
+	})
+	Register(Command{
+		Name:     "compress",
+		Aliases:  []string{"zip"},
+		Usage:    "compress <out.zip> <src>",
+		Summary:  "Create zip archive",
+		Examples: []string{"compress site.zip public"},
+		Run: func(ctx context.Context, args []string) Result {
+			return CmdCompressArchive(ctx, append([]string{"compress"}, args...))
+		},
+		DryRun: true,
+	})
+	Register(Command{
+		Name:     "extract",
+		Aliases:  []string{"unzip"},
+		Usage:    "extract <in.zip> <dst>",
+		Summary:  "Extract zip",
+		Examples: []string{"extract site.zip out", "extract site.zip out --dry-run"},
+		Run: func(ctx context.Context, args []string) Result {
+			return CmdCompressArchive(ctx, append([]string{"extract"}, args...))
+		},
+		DryRun: true,
+	})
+}
+
+func CmdFile(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "file: expected subcommand (move, rename, clean, open)"
+		return Fail("file: expected subcommand (move, rename, clean, open)")
 	}
 	sub := strings.ToLower(args[0])
 	switch sub {
 	case "move":
 		if len(args) < 3 {
-			return "file move: usage: file move <src> <dst>"
+			return Fail("file move: usage: file move <src> <dst>")
+		}
+		ps, err := expandPaths(ctx, args[1], args[2])
+		if err != nil {
+			return Fail("file move: " + err.Error())
 		}
-		return fileMove(args[1], args[2])
+		return fileMove(ctx, ps[0], ps[1])
 	case "rename":
 		if len(args) < 3 {
-			return "file rename: usage: file rename <pattern> <replacement>"
+			return Fail("file rename: usage: file rename <files...> <replacement>")
+		}
+		files, err := expandPaths(ctx, args[1:len(args)-1]...)
+		if err != nil {
+			return Fail("file rename: " + err.Error())
 		}
-		return fileRenameBulk(args[1], args[2])
+		return fileRenameBulk(ctx, files, args[len(args)-1])
 	case "clean":
 		if len(args) >= 2 && args[1] == "temp" {
-			return fileCleanTemp()
+			return fileCleanTemp(ctx, args[2:])
 		}
-		return "file clean: supported targets: temp"
+		return Fail("file clean: supported targets: temp")
 	case "open":
 		if len(args) < 2 {
-			return "file open <path>"
+			return Fail("file open: usage: file open <path>")
 		}
-		return CmdOpen(args[1:])
+		return CmdOpen(ctx, args[1:])
 	default:
-		return "file: unknown subcommand"
+		return Fail("file: unknown subcommand")
 	}
 }
 
-func fileMove(src, dst string) string {
-	if strings.HasPrefix(src, "~") {
-		if home, err := os.UserHomeDir(); err == nil {
-			src = filepath.Join(home, src[2:])
-		}
-	}
-	if strings.HasPrefix(dst, "~") {
-		if home, err := os.UserHomeDir(); err == nil {
-			dst = filepath.Join(home, dst[2:])
-		}
-	}
-
-	if err := os.Rename(src, dst); err != nil {
-		if err := copyFileOrDir(src, dst); err == nil {
-			_ = os.RemoveAll(src)
-			return fmt.Sprintf("Moved %s -> %s", src, dst)
-		}
-		return "file move error: " + err.Error()
+func fileMove(ctx context.Context, src, dst string) Result {
+	op := beginOp(ctx)
+	dst, err := op.move(src, dst)
+	if err != nil {
+		return op.done([]string{"file move error: " + err.Error()}, true)
 	}
-	return fmt.Sprintf("Moved %s -> %s", src, dst)
+	return op.done(op.log(nil, "Moved %s -> %s", src, dst), false)
 }
 
 /*func copyFileOrDir(src, dst string) error {
@@ -109,75 +142,151 @@ func fileMove(src, dst string) string {
 	return out.Sync()
 }*/
 
-func fileRenameBulk(pattern, repl string) string {
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		return "rename: invalid pattern"
-	}
-	if len(matches) == 0 {
-		return "rename: no files match pattern"
-	}
+// fileRenameBulk renames files, usually the expansion of a glob, in
+// order. In repl, # is the 1-based position and {name} the old base name.
+// Every new name is worked out and checked first, so a batch that would
+// collide, with itself or with existing files, changes nothing.
+func fileRenameBulk(ctx context.Context, matches []string, repl string) Result {
+	targets := make([]string, len(matches))
+	seen := map[string]string{}
 	for i, p := range matches {
+		if _, err := os.Stat(p); err != nil {
+			return Fail("rename: no such file: " + p)
+		}
 		dir := filepath.Dir(p)
 		ext := filepath.Ext(p)
 		base := strings.TrimSuffix(filepath.Base(p), ext)
 		target := repl
 		target = strings.ReplaceAll(target, "#", fmt.Sprintf("%d", i+1))
 		target = strings.ReplaceAll(target, "{name}", base)
-		target = target + ext
-		if err := os.Rename(p, filepath.Join(dir, target)); err != nil {
-			return "rename error: " + err.Error()
+		target = filepath.Join(dir, target+ext)
+		if prev, ok := seen[target]; ok {
+			return Failf("rename: %s and %s would both become %s", prev, p, target)
 		}
+		seen[target] = p
+		if target == p {
+			continue
+		}
+		if _, err := os.Lstat(target); err == nil {
+			return Fail("rename: " + target + " already exists")
+		}
+		targets[i] = target
 	}
-	return fmt.Sprintf("Renamed %d files", len(matches))
+	op := beginOp(ctx)
+	renamed := 0
+	for i, p := range matches {
+		if targets[i] == "" {
+			continue
+		}
+		if _, err := op.move(p, targets[i]); err != nil {
+			return op.done([]string{"rename error: " + err.Error()}, true)
+		}
+		renamed++
+	}
+	return op.done(op.log(nil, "Renamed %d files", renamed), false)
 }
 
-func fileCleanTemp() string {
+// tempCleanDays is how old, in days, temp entries must be by default
+// before file clean temp takes them.
+const tempCleanDays = 7
+
+// fileCleanTemp lists the files and directories in the temp directory
+// that belong to the user and have not changed for some days; with
+// --apply it moves them to the trash. Sockets, pipes and other users'
+// entries are left alone, as is anything that cannot be moved.
+func fileCleanTemp(ctx context.Context, args []string) Result {
+	apply, days := false, tempCleanDays
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--apply":
+			apply = true
+		case "--days":
+			if i+1 >= len(args) {
+				return Fail("clean temp: --days expects a number")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				return Failf("clean temp: invalid --days '%s'", args[i+1])
+			}
+			days = n
+			i++
+		default:
+			return Failf("clean temp: unknown option '%s'", args[i])
+		}
+	}
 	tmp := os.TempDir()
 	entries, err := os.ReadDir(tmp)
 	if err != nil {
-		return "clean temp: " + err.Error()
+		return Fail("clean temp: " + err.Error())
 	}
-	out := []string{"Files in temp (first 20):"}
-	n := 0
+	cutoff := time.Now().AddDate(0, 0, -days)
+	op := beginOp(ctx)
+	preview := !apply && !op.dry
+	op.dry = op.dry || !apply
+	removed, skipped := 0, 0
 	for _, e := range entries {
-		out = append(out, " - "+e.Name())
-		n++
-		if n >= 20 {
-			break
+		info, err := e.Info()
+		if err != nil || !(info.Mode().IsRegular() || info.IsDir()) {
+			continue
+		}
+		if !ownedByUser(info) || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := op.remove(filepath.Join(tmp, e.Name())); err != nil {
+			skipped++
+			continue
 		}
+		removed++
+	}
+	out := op.log(nil, "Trashed %d entries from %s", removed, tmp)
+	if skipped > 0 {
+		out = append(out, fmt.Sprintf("Skipped %d that could not be moved", skipped))
 	}
-	out = append(out, "\nTo actually delete temp files run: file clean temp --confirm")
-	return strings.Join(out, "\n")
+	res := op.done(out, false)
+	if preview && removed > 0 {
+		res.Output += fmt.Sprintf("\nRun 'file clean temp --apply --days %d' to move them to the trash.", days)
+	}
+	return res
 }
 
-func CmdCompressArchive(args []string) string {
+func CmdCompressArchive(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "compress: usage: compress <out.zip> <src-dir-or-file> | extract <in.zip> <dst>"
+		return Fail("compress: usage: compress <out.zip> <src-dir-or-file> | extract <in.zip> <dst>")
 	}
 	verb := args[0]
-	if verb == "compress" || verb == "zip" || verb == "compress" {
+	if verb == "compress" || verb == "zip" {
 		if len(args) < 3 {
-			return "compress: usage: compress <out.zip> <src>"
+			return Fail("compress: usage: compress <out.zip> <src>")
+		}
+		ps, err := expandPaths(ctx, args[1], args[2])
+		if err != nil {
+			return Fail("compress: " + err.Error())
+		}
+		out, src := ps[0], ps[1]
+		if _, err := os.Stat(src); err != nil {
+			return Fail("compress error: " + err.Error())
 		}
-		out := args[1]
-		src := args[2]
-		if err := zipPath(src, out); err != nil {
-			return "compress error: " + err.Error()
+		op := beginOp(ctx)
+		if err := op.write(out, func() error { return zipPath(src, out) }); err != nil {
+			return op.done([]string{"compress error: " + err.Error()}, true)
 		}
-		return "Created " + out
+		return op.done(op.log(nil, "Created %s", out), false)
 	} else if verb == "extract" || verb == "unzip" {
 		if len(args) < 3 {
-			return "extract: usage: extract <in.zip> <dst>"
+			return Fail("extract: usage: extract <in.zip> <dst>")
 		}
-		in := args[1]
-		dst := args[2]
-		if err := unzip(in, dst); err != nil {
-			return "extract error: " + err.Error()
+		ps, err := expandPaths(ctx, args[1], args[2])
+		if err != nil {
+			return Fail("extract: " + err.Error())
+		}
+		in, dst := ps[0], ps[1]
+		op := beginOp(ctx)
+		if err := unzip(op, in, dst); err != nil {
+			return op.done([]string{"extract error: " + err.Error()}, true)
 		}
-		return "Extracted to " + dst
+		return op.done(op.log(nil, "Extracted to %s", dst), false)
 	}
-	return "compress: unknown subcommand"
+	return Fail("compress: unknown subcommand")
 }
 
 func zipPath(src, out string) error {
@@ -225,37 +334,49 @@ func zipPath(src, out string) error {
 	})
 }
 
-func unzip(in, dst string) error {
+// unzip extracts in under dst through op. Entries that would land
+// outside dst are refused.
+func unzip(op *fileOp, in, dst string) error {
 	r, err := zip.OpenReader(in)
 	if err != nil {
 		return err
 	}
 	defer r.Close()
+	root := filepath.Clean(dst) + string(filepath.Separator)
 	for _, f := range r.File {
 		fpath := filepath.Join(dst, f.Name)
+		if !strings.HasPrefix(fpath+string(filepath.Separator), root) {
+			return fmt.Errorf("%s: path outside %s", f.Name, dst)
+		}
 		if f.FileInfo().IsDir() {
-			os.MkdirAll(fpath, f.Mode())
+			if err := op.mkdirAll(fpath); err != nil {
+				return err
+			}
 			continue
 		}
-		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		if err := op.mkdirAll(filepath.Dir(fpath)); err != nil {
 			return err
 		}
-		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
+		err := op.write(fpath, func() error {
+			outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+			if err != nil {
+				return err
+			}
+			rc, err := f.Open()
+			if err != nil {
+				outFile.Close()
+				return err
+			}
+			_, err = io.Copy(outFile, rc)
+			rc.Close()
+			if cerr := outFile.Close(); err == nil {
+				err = cerr
+			}
 			return err
-		}
-		rc, err := f.Open()
+		})
 		if err != nil {
-			outFile.Close()
-			return err
-		}
-		if _, err := io.Copy(outFile, rc); err != nil {
-			rc.Close()
-			outFile.Close()
 			return err
 		}
-		rc.Close()
-		outFile.Close()
 	}
 	return nil
 }
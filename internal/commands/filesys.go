@@ -12,6 +12,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
@@ -21,27 +22,34 @@ import (
 	"time"
 )
 
-func CmdLS(args []string) string {
+func init() {
+	Register(Command{
+		Name:    "ls",
+		Usage:   "ls [dir]",
+		Summary: "List directory contents",
+		Run:     CmdLS,
+	})
+}
+
+func CmdLS(ctx context.Context, args []string) Result {
 	dir := "."
 	if len(args) > 0 && args[0] != "" {
 		dir = args[0]
 	}
-	dir = expandPath(dir)
-	if !filepath.IsAbs(dir) {
-		if wd, err := os.Getwd(); err == nil {
-			dir = filepath.Join(wd, dir)
-		}
+	dir, err := expandPath(ctx, dir)
+	if err != nil {
+		return Fail("ls: " + err.Error())
 	}
 	info, err := os.Stat(dir)
 	if err != nil {
-		return "ls: " + err.Error()
+		return Fail("ls: " + err.Error())
 	}
 	if !info.IsDir() {
-		return dir
+		return OK(dir)
 	}
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return "ls: " + err.Error()
+		return Fail("ls: " + err.Error())
 	}
 	type ent struct {
 		Name string
@@ -71,7 +79,7 @@ func CmdLS(args []string) string {
 			fmt.Fprintf(&b, "      %s\t%d bytes\t%s\n", e.Name, e.Size, e.Time.Format("2006-01-02 15:04"))
 		}
 	}
-	return b.String()
+	return OK(b.String())
 }
 
 func SafeWalk(root, pattern string, timeoutSecs int) ([]string, error) {
@@ -11,7 +11,9 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -25,6 +27,19 @@ type focusState struct {
 	Label   string `json:"label,omitempty"`
 }
 
+func init() {
+	Register(Command{
+		Name:        "focus",
+		Subcommands: []string{"end"},
+		Usage:       "focus <duration>|end",
+		Summary:     "Start a focus session",
+		Examples:    []string{"focus 25m", "focus 90 min", "focus end"},
+		Async:       true,
+		Pending:     "Focus started...",
+		Start:       StartFocus,
+	})
+}
+
 func focusStatePath() string {
 	_ = os.MkdirAll("data", 0755)
 	return filepath.Join("data", "focus_state.json")
@@ -94,43 +109,37 @@ func parseDurationLike(tokens []string) (time.Duration, string, error) {
 	return 0, "", fmt.Errorf("could not parse duration: %s", joined)
 }
 
-func StartFocus(args []string, ch chan string) {
+func StartFocus(ctx context.Context, args []string, ch chan string) error {
 	if len(args) == 0 {
-		ch <- "focus: expected duration (e.g. focus 25m or focus 90 min) or 'focus end'."
-		return
+		return errors.New("focus: expected duration (e.g. focus 25m or focus 90 min) or 'focus end'.")
 	}
 	if strings.ToLower(args[0]) == "end" {
 		if err := removeFocusStateFile(); err != nil {
-			ch <- "focus: failed to end: " + err.Error()
-			return
+			return errors.New("focus: failed to end: " + err.Error())
 		}
 		ch <- "Focus ended."
-		return
+		return nil
 	}
 
 	dur, norm, err := parseDurationLike(args)
 	if err != nil {
-		ch <- "focus: " + err.Error()
-		return
+		return errors.New("focus: " + err.Error())
 	}
 	if dur <= 0 {
-		ch <- "focus: duration must be > 0"
-		return
+		return errors.New("focus: duration must be > 0")
 	}
 
 	if st, ok, _ := readFocusState(); ok {
 		et := time.Unix(st.EndUnix, 0)
 		if time.Now().Before(et) {
-			ch <- fmt.Sprintf("A focus session is already running and ends at %s (use 'focus end' to stop it).", et.Local().Format("2006-01-02 15:04"))
-			return
+			return fmt.Errorf("A focus session is already running and ends at %s (use 'focus end' to stop it).", et.Local().Format("2006-01-02 15:04"))
 		}
 	}
 
 	end := time.Now().Add(dur)
 	state := focusState{EndUnix: end.Unix()}
 	if err := writeFocusState(state); err != nil {
-		ch <- "focus: failed to create state: " + err.Error()
-		return
+		return errors.New("focus: failed to create state: " + err.Error())
 	}
 
 	ch <- fmt.Sprintf("Focus started for %s — ends at %s", norm, end.Local().Format("15:04"))
@@ -139,31 +148,49 @@ func StartFocus(args []string, ch chan string) {
 	defer ticker.Stop()
 	for {
 		select {
+		case <-ctx.Done():
+			// Closing the tab leaves the session running; only
+			// cancelling it ends it.
+			if errors.Is(context.Cause(ctx), ErrSessionClosed) {
+				return nil
+			}
+			_ = removeFocusStateFile()
+			ch <- "Focus ended (cancelled)."
+			return nil
 		case <-ticker.C:
 			if st, ok, _ := readFocusState(); !ok {
 				ch <- "Focus ended (cancelled)."
-				return
+				return nil
 			} else {
 				if time.Now().Unix() >= st.EndUnix {
 					_ = removeFocusStateFile() // cleanup
 					ch <- "Focus session complete! Great job."
-					return
+					return nil
 				}
 			}
 		}
 	}
 }
 
-func EndFocus() string {
+// FocusEnd returns when the focus session in progress ends, if one is.
+func FocusEnd() (time.Time, bool) {
+	st, ok, err := readFocusState()
+	if err != nil || !ok || time.Now().Unix() >= st.EndUnix {
+		return time.Time{}, false
+	}
+	return time.Unix(st.EndUnix, 0), true
+}
+
+func EndFocus() Result {
 	if err := removeFocusStateFile(); err != nil {
-		return "focus: failed to end: " + err.Error()
+		return Fail("focus: failed to end: " + err.Error())
 	}
-	return "Focus ended."
+	return OK("Focus ended.")
 }
 
-func CmdFocus(args []string) string {
+func CmdFocus(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "focus: usage: focus <duration>  (e.g. focus 25m, focus 90 min) or focus end"
+		return Fail("focus: usage: focus <duration>  (e.g. focus 25m, focus 90 min) or focus end")
 	}
 	if strings.ToLower(args[0]) == "end" {
 		return EndFocus()
@@ -171,16 +198,16 @@ func CmdFocus(args []string) string {
 
 	dur, norm, err := parseDurationLike(args)
 	if err != nil {
-		return "focus: " + err.Error()
+		return Fail("focus: " + err.Error())
 	}
 	if dur <= 0 {
-		return "focus: duration must be > 0"
+		return Fail("focus: duration must be > 0")
 	}
 
 	end := time.Now().Add(dur)
 	state := focusState{EndUnix: end.Unix()}
 	if err := writeFocusState(state); err != nil {
-		return "focus: failed to create state: " + err.Error()
+		return Fail("focus: failed to create state: " + err.Error())
 	}
-	return fmt.Sprintf("Focus started for %s — ends at %s", norm, end.Local().Format("15:04"))
+	return OK(fmt.Sprintf("Focus started for %s — ends at %s", norm, end.Local().Format("15:04")))
 }
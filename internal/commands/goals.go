@@ -11,6 +11,7 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -27,6 +28,41 @@ type Goal struct {
 	Done    bool   `json:"done"`
 }
 
+func init() {
+	Register(Command{
+		Name:        "goal",
+		Subcommands: []string{"add", "list", "done", "remove", "rm", "delete", "clear"},
+		Usage:       "goal <add|list|done|remove|clear>",
+		Summary:     "Goal tracking helper",
+		Examples:    []string{"goal add \"learn go\"", "goal done 1"},
+		Run:         CmdGoal,
+		Complete:    completeGoal,
+	})
+}
+
+// completeGoal offers the IDs of goals to done and remove.
+func completeGoal(ctx context.Context, args []string) []Candidate {
+	if len(args) != 1 {
+		return nil
+	}
+	sub := strings.ToLower(args[0])
+	if sub != "done" && sub != "remove" && sub != "rm" && sub != "delete" {
+		return nil
+	}
+	gs, err := loadGoals()
+	if err != nil {
+		return nil
+	}
+	var out []Candidate
+	for _, g := range gs {
+		if sub == "done" && g.Done {
+			continue
+		}
+		out = append(out, Candidate{Text: strconv.Itoa(g.ID), Note: g.Text})
+	}
+	return out
+}
+
 func goalsFilePath() string {
 	_ = os.MkdirAll("data", 0755)
 	return filepath.Join("data", "goals.json")
@@ -67,15 +103,15 @@ func nextGoalID(gs []Goal) int {
 	return max + 1
 }
 
-func CmdGoal(args []string) string {
+func CmdGoal(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return `goal: subcommands: add, list, done, remove, clear
+		return OK(`goal: subcommands: add, list, done, remove, clear
 Examples:
   goal add "learn go"
   goal list
   goal done 1
   goal remove 2
-  goal clear --confirm`
+  goal clear --confirm`)
 	}
 
 	sub := strings.ToLower(args[0])
@@ -83,32 +119,32 @@ Examples:
 	switch sub {
 	case "add":
 		if len(args) < 2 {
-			return "goal add: expected text, e.g. goal add \"learn go\""
+			return Fail("goal add: expected text, e.g. goal add \"learn go\"")
 		}
 		text := strings.Join(args[1:], " ")
 		text = strings.TrimSpace(text)
 		if text == "" {
-			return "goal add: empty text"
+			return Fail("goal add: empty text")
 		}
 		gs, err := loadGoals()
 		if err != nil {
-			return "goal add: load error: " + err.Error()
+			return Fail("goal add: load error: " + err.Error())
 		}
 		id := nextGoalID(gs)
 		g := Goal{ID: id, Text: text, Created: time.Now().UTC().Format(time.RFC3339), Done: false}
 		gs = append(gs, g)
 		if err := saveGoals(gs); err != nil {
-			return "goal add: save error: " + err.Error()
+			return Fail("goal add: save error: " + err.Error())
 		}
-		return fmt.Sprintf("Added goal #%d: %s", id, text)
+		return OK(fmt.Sprintf("Added goal #%d: %s", id, text))
 
 	case "list":
 		gs, err := loadGoals()
 		if err != nil {
-			return "goal list: load error: " + err.Error()
+			return Fail("goal list: load error: " + err.Error())
 		}
 		if len(gs) == 0 {
-			return "No goals. Add one with: goal add \"learn go\""
+			return OK("No goals. Add one with: goal add \"learn go\"")
 		}
 		sb := &strings.Builder{}
 		for _, g := range gs {
@@ -122,19 +158,19 @@ Examples:
 			}
 			fmt.Fprintf(sb, "%d) %s %s  — %s\n", g.ID, check, g.Text, created)
 		}
-		return strings.TrimSpace(sb.String())
+		return OK(strings.TrimSpace(sb.String()))
 
 	case "done":
 		if len(args) < 2 {
-			return "goal done: expected id, e.g. goal done 1"
+			return Fail("goal done: expected id, e.g. goal done 1")
 		}
 		id, err := strconv.Atoi(args[1])
 		if err != nil {
-			return "goal done: invalid id"
+			return Fail("goal done: invalid id")
 		}
 		gs, err := loadGoals()
 		if err != nil {
-			return "goal done: load error: " + err.Error()
+			return Fail("goal done: load error: " + err.Error())
 		}
 		found := false
 		for i := range gs {
@@ -145,24 +181,24 @@ Examples:
 			}
 		}
 		if !found {
-			return fmt.Sprintf("goal done: id %d not found", id)
+			return Failf("goal done: id %d not found", id)
 		}
 		if err := saveGoals(gs); err != nil {
-			return "goal done: save error: " + err.Error()
+			return Fail("goal done: save error: " + err.Error())
 		}
-		return fmt.Sprintf("Marked goal %d done.", id)
+		return OK(fmt.Sprintf("Marked goal %d done.", id))
 
 	case "remove", "rm", "delete":
 		if len(args) < 2 {
-			return "goal remove: expected id, e.g. goal remove 1"
+			return Fail("goal remove: expected id, e.g. goal remove 1")
 		}
 		id, err := strconv.Atoi(args[1])
 		if err != nil {
-			return "goal remove: invalid id"
+			return Fail("goal remove: invalid id")
 		}
 		gs, err := loadGoals()
 		if err != nil {
-			return "goal remove: load error: " + err.Error()
+			return Fail("goal remove: load error: " + err.Error())
 		}
 		newGs := make([]Goal, 0, len(gs))
 		found := false
@@ -174,22 +210,19 @@ Examples:
 			newGs = append(newGs, g)
 		}
 		if !found {
-			return fmt.Sprintf("goal remove: id %d not found", id)
+			return Failf("goal remove: id %d not found", id)
 		}
 		if err := saveGoals(newGs); err != nil {
-			return "goal remove: save error: " + err.Error()
+			return Fail("goal remove: save error: " + err.Error())
 		}
-		return fmt.Sprintf("Removed goal %d.", id)
+		return OK(fmt.Sprintf("Removed goal %d.", id))
 
 	case "clear":
-		if len(args) >= 2 && (args[1] == "--confirm" || args[1] == "confirm") {
-			fp := goalsFilePath()
-			_ = os.Remove(fp)
-			return "All goals cleared."
-		}
-		return "goal clear: destructive. confirm with: goal clear --confirm"
+		fp := goalsFilePath()
+		_ = os.Remove(fp)
+		return OK("All goals cleared.")
 
 	default:
-		return "goal: unknown subcommand. Try 'goal add', 'goal list', 'goal done <id>'"
+		return Fail("goal: unknown subcommand. Try 'goal add', 'goal list', 'goal done <id>'")
 	}
 }
@@ -0,0 +1,741 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xrootAnon/0xRootShell/internal/store"
+)
+
+func init() {
+	Register(Command{
+		Name:     "undo",
+		Usage:    "undo [list | <id>]",
+		Summary:  "Reverse the last file operation, or a given one",
+		Examples: []string{"undo", "undo list", "undo 12"},
+		Run:      CmdUndo,
+		Complete: completeUndo,
+		DryRun:   true,
+	})
+}
+
+// completeUndo offers list and the operations that can still be undone.
+func completeUndo(ctx context.Context, args []string) []Candidate {
+	j := EnvOf(ctx).Journal
+	if len(args) > 0 || j == nil {
+		return nil
+	}
+	out := Words("list")
+	ops, _ := j.store.Operations(20)
+	for _, op := range ops {
+		if !op.Undone {
+			out = append(out, Candidate{Text: strconv.FormatUint(op.ID, 10), Note: op.Command})
+		}
+	}
+	return out
+}
+
+// Journal records the file changes commands make so `undo` can reverse
+// them. Deleted files go to the trash, from where undo takes them back.
+type Journal struct {
+	store *store.Store
+}
+
+func NewJournal(st *store.Store) *Journal {
+	return &Journal{store: st}
+}
+
+// fileOp is the one way commands change files: it performs the changes
+// of one command and records them in the journal. Without a journal in
+// the Env it still performs them, but records nothing. On a dry run it
+// performs nothing and only lists what it would do.
+type fileOp struct {
+	ctx       context.Context
+	env       *Env
+	j         *Journal
+	line      string
+	permanent bool
+	dry       bool
+	plan      []string
+	planSeen  map[string]bool
+	steps     []store.Step
+}
+
+func beginOp(ctx context.Context) *fileOp {
+	env := EnvOf(ctx)
+	return &fileOp{ctx: ctx, env: env, j: env.Journal, line: env.Line, dry: env.DryRun}
+}
+
+// guard refuses changes to protected paths and to paths outside the
+// sandbox, on dry runs too.
+func (op *fileOp) guard(paths ...string) error {
+	pol := op.env.Policy
+	if pol == nil {
+		return nil
+	}
+	for _, p := range paths {
+		if err := pol.CheckRoots(p); err != nil {
+			op.env.audit("sandbox", err.Error())
+			return err
+		}
+		if err := pol.CheckPath(p); err != nil {
+			op.env.audit("protected", err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+func (op *fileOp) planned(verb, path string) {
+	line := fmt.Sprintf("  %-9s %s", verb, path)
+	if op.planSeen[line] {
+		return
+	}
+	if op.planSeen == nil {
+		op.planSeen = map[string]bool{}
+	}
+	op.planSeen[line] = true
+	op.plan = append(op.plan, line)
+}
+
+func (op *fileOp) record(kind, from, to, staged string) {
+	op.recordStep(store.Step{Kind: kind, From: from, To: to, Staged: staged})
+}
+
+func (op *fileOp) recordStep(st store.Step) {
+	op.steps = append(op.steps, st)
+	op.env.Files.Add(changedPaths(st)...)
+}
+
+// changedPaths lists the paths a step changes; a copy leaves its source
+// alone.
+func changedPaths(st store.Step) []string {
+	if st.Kind == "copy" {
+		return []string{st.To}
+	}
+	return []string{st.From, st.To}
+}
+
+// remove moves path to the trash, or deletes it for good when the op is
+// permanent.
+func (op *fileOp) remove(path string) error {
+	if err := op.guard(path); err != nil {
+		return err
+	}
+	if op.dry {
+		if op.permanent {
+			op.planned("delete", path)
+		} else {
+			op.planned("trash", path)
+		}
+		return nil
+	}
+	if op.permanent {
+		op.env.Files.Add(path)
+		return os.RemoveAll(path)
+	}
+	trashed, err := trashPut(op.ctx, path)
+	if err != nil {
+		return err
+	}
+	op.record("delete", path, "", trashed)
+	return nil
+}
+
+// removed is the verb for what remove did, for command output.
+func (op *fileOp) removed() string {
+	if op.permanent {
+		return "Deleted"
+	}
+	return "Trashed"
+}
+
+// move renames src to dst, or into dst when it is a directory, and
+// returns where src ended up. A file it replaces goes to the trash first.
+func (op *fileOp) move(src, dst string) (string, error) {
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		dst = filepath.Join(dst, filepath.Base(src))
+	}
+	if _, err := os.Lstat(src); err != nil {
+		return dst, err
+	}
+	if err := op.guard(src, dst); err != nil {
+		return dst, err
+	}
+	if err := op.replace(dst); err != nil {
+		return dst, err
+	}
+	if op.dry {
+		op.planned("move", src+" -> "+dst)
+		return dst, nil
+	}
+	if err := moveFile(op.ctx, src, dst); err != nil {
+		return dst, err
+	}
+	op.record("move", src, dst, "")
+	return dst, nil
+}
+
+// replace moves a file about to be overwritten at path to the trash.
+func (op *fileOp) replace(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+	if op.dry {
+		op.planned("overwrite", path)
+		return nil
+	}
+	return op.remove(path)
+}
+
+// copy copies src to dst, or into dst when it is a directory. A copy
+// merged into an existing directory is not undoable. Missing parent
+// directories are created and recorded, so undo removes them too.
+func (op *fileOp) copy(src, dst string) error {
+	final := dst
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		final = filepath.Join(dst, filepath.Base(src))
+	}
+	merged := false
+	if info, err := os.Lstat(final); err == nil && info.IsDir() {
+		merged = true
+	}
+	if err := op.guard(final); err != nil {
+		return err
+	}
+	if err := op.replace(final); err != nil {
+		return err
+	}
+	if err := op.mkdirAll(filepath.Dir(final)); err != nil {
+		return err
+	}
+	if op.dry {
+		op.planned("copy", src+" -> "+final)
+		return nil
+	}
+	if err := copyFileOrDir(op.ctx, src, dst); err != nil {
+		return err
+	}
+	if !merged {
+		op.record("copy", src, final, "")
+	} else {
+		op.env.Files.Add(final)
+	}
+	return nil
+}
+
+// write creates the file at path with fn, first moving any file it
+// replaces to the trash.
+func (op *fileOp) write(path string, fn func() error) error {
+	if err := op.guard(path); err != nil {
+		return err
+	}
+	exists := false
+	if _, err := os.Lstat(path); err == nil {
+		exists = true
+	}
+	if op.dry {
+		if !exists {
+			op.planned("create", path)
+		}
+		return op.replace(path)
+	}
+	if err := op.replace(path); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	op.record("create", "", path, "")
+	return nil
+}
+
+// overwrite changes the file at path in place with fn, first putting a
+// copy of it in the staging area so undo can bring the old contents
+// back.
+func (op *fileOp) overwrite(path string, fn func() error) error {
+	info, exists, err := op.inPlace(path, "write")
+	if err != nil || op.dry {
+		return err
+	}
+	if !exists {
+		return op.create(path, fn)
+	}
+	backup, err := stageCopy(path, info)
+	if err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		os.Remove(backup)
+		return err
+	}
+	op.record("overwrite", path, "", backup)
+	return nil
+}
+
+// appendTo adds to the file at path with fn. Undo cuts the file back to
+// the size it had, so nothing needs to be kept.
+func (op *fileOp) appendTo(path string, fn func() error) error {
+	info, exists, err := op.inPlace(path, "append to")
+	if err != nil || op.dry {
+		return err
+	}
+	if !exists {
+		return op.create(path, fn)
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	op.recordStep(store.Step{Kind: "append", From: path, Size: info.Size()})
+	return nil
+}
+
+// inPlace checks path before overwrite or appendTo changes it, and on a
+// dry run plans the change, described by verb.
+func (op *fileOp) inPlace(path, verb string) (os.FileInfo, bool, error) {
+	if err := op.guard(path); err != nil {
+		return nil, false, err
+	}
+	info, err := os.Stat(path)
+	exists := err == nil
+	if exists && info.IsDir() {
+		return nil, false, fmt.Errorf("%s is a directory", path)
+	}
+	if op.dry {
+		if !exists {
+			verb = "create"
+		}
+		op.planned(verb, path)
+	}
+	return info, exists, nil
+}
+
+// create makes the new file at path with fn.
+func (op *fileOp) create(path string, fn func() error) error {
+	if err := fn(); err != nil {
+		return err
+	}
+	op.record("create", "", path, "")
+	return nil
+}
+
+// restore takes the trash item staged back to path, where it was
+// deleted from; undo sends it to the trash again.
+func (op *fileOp) restore(staged, path string) error {
+	if err := op.guard(path); err != nil {
+		return err
+	}
+	if err := mustBeFree(path); err != nil {
+		return err
+	}
+	if err := op.mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if op.dry {
+		op.planned("restore", path)
+		return nil
+	}
+	if err := moveFile(op.ctx, staged, path); err != nil {
+		return err
+	}
+	trashForget(staged)
+	op.record("restore", "", path, "")
+	return nil
+}
+
+// mkdirAll is os.MkdirAll that records each directory it creates.
+func (op *fileOp) mkdirAll(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil || filepath.Dir(d) == d {
+			break
+		}
+		missing = append(missing, d)
+	}
+	if err := op.guard(missing...); err != nil {
+		return err
+	}
+	if op.dry {
+		for i := len(missing) - 1; i >= 0; i-- {
+			op.planned("create", missing[i]+string(filepath.Separator))
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		op.record("create", "", missing[i], "")
+	}
+	return nil
+}
+
+// commit adds the collected steps to the journal as one operation. The
+// returned note is meant to be appended to the command's output.
+func (op *fileOp) commit() string {
+	if op.dry || op.j == nil || len(op.steps) == 0 {
+		return ""
+	}
+	id, err := op.j.store.AddOperation(store.Operation{Time: time.Now(), Command: op.line, Steps: op.steps})
+	if err != nil {
+		return "undo journal: " + err.Error()
+	}
+	return fmt.Sprintf("(undo with 'undo %d')", id)
+}
+
+// log adds a message about a change that was made to out. A dry run
+// made none, so its output is the plan instead; see done.
+func (op *fileOp) log(out []string, format string, a ...any) []string {
+	if op.dry {
+		return out
+	}
+	return append(out, fmt.Sprintf(format, a...))
+}
+
+// done commits the op and builds the command's result from out. On a dry
+// run the result lists the planned changes, followed by any problems the
+// command reported.
+func (op *fileOp) done(out []string, failed bool) Result {
+	if !op.dry {
+		return okIf(withNote(strings.Join(out, "\n"), op.commit()), failed)
+	}
+	lines := []string{"Dry run, nothing was changed. Would:"}
+	if len(op.plan) == 0 {
+		lines[0] = "Dry run: nothing to change."
+	}
+	lines = append(append(lines, op.plan...), out...)
+	return okIf(strings.Join(lines, "\n"), failed)
+}
+
+// withNote appends a journal note from commit to a command's output.
+func withNote(out, note string) string {
+	if note == "" {
+		return out
+	}
+	if out == "" {
+		return note
+	}
+	return out + "\n" + note
+}
+
+// stageDir is the journal's staging area, where the old contents of
+// files changed in place are kept for undo. Unlike the trash it is not
+// for the user to browse; copies older than stageKeep are removed as new
+// ones are made.
+func stageDir() string {
+	d, err := filepath.Abs(filepath.Join("data", "undo"))
+	if err != nil {
+		return filepath.Join("data", "undo")
+	}
+	return d
+}
+
+const stageKeep = 30 * 24 * time.Hour
+
+// stageCopy copies the file at path, described by info, into the
+// staging area and returns where the copy lives.
+func stageCopy(path string, info os.FileInfo) (string, error) {
+	dir := stageDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	pruneStage(dir)
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	out, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(out.Name(), info.Mode().Perm())
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return "", err
+	}
+	return out.Name(), nil
+}
+
+func pruneStage(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > stageKeep {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+func moveFile(ctx context.Context, from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	if err := copyFileOrDir(ctx, from, to); err != nil {
+		return err
+	}
+	return os.RemoveAll(from)
+}
+
+func CmdUndo(ctx context.Context, args []string) Result {
+	j := EnvOf(ctx).Journal
+	if j == nil {
+		return Fail("undo: no journal in this session")
+	}
+	if len(args) > 0 && strings.ToLower(args[0]) == "list" {
+		return j.list()
+	}
+	var op store.Operation
+	if len(args) == 0 {
+		ops, err := j.store.Operations(200)
+		if err != nil {
+			return Fail("undo: " + err.Error())
+		}
+		found := false
+		for _, o := range ops {
+			if !o.Undone {
+				op, found = o, true
+				break
+			}
+		}
+		if !found {
+			return Fail("undo: nothing to undo")
+		}
+	} else {
+		id, err := strconv.ParseUint(strings.TrimPrefix(args[0], "#"), 10, 64)
+		if err != nil {
+			return Failf("undo: invalid id '%s'", args[0])
+		}
+		o, found, err := j.store.Operation(id)
+		if err != nil {
+			return Fail("undo: " + err.Error())
+		}
+		if !found {
+			return Failf("undo: no operation %d", id)
+		}
+		if o.Undone {
+			return Failf("undo: operation %d was already undone", id)
+		}
+		op = o
+	}
+	return j.undo(ctx, op)
+}
+
+// undo reverses op's steps, last first. Steps that cannot be reversed,
+// for example because the path has been reused since or the policy
+// refuses it, are reported and the operation stays in the journal so it
+// can be retried. A dry run only lists the steps.
+func (j *Journal) undo(ctx context.Context, op store.Operation) Result {
+	fo := beginOp(ctx)
+	if fo.dry {
+		out := []string{fmt.Sprintf("(operation %d: %s)", op.ID, op.Command)}
+		for i := len(op.Steps) - 1; i >= 0; i-- {
+			st := op.Steps[i]
+			if err := fo.guard(stepPaths(st)...); err != nil {
+				out = append(out, fmt.Sprintf("undo: %s %s: %v", st.Kind, firstNonEmpty(st.From, st.To), err))
+				continue
+			}
+			fo.planned(undoVerb(st))
+		}
+		return fo.done(out, len(out) > 1)
+	}
+	out := []string{fmt.Sprintf("Undoing %d: %s", op.ID, op.Command)}
+	var left []store.Step
+	for i := len(op.Steps) - 1; i >= 0; i-- {
+		st := op.Steps[i]
+		msg, err := undoStep(fo, st)
+		if err != nil {
+			out = append(out, fmt.Sprintf("undo: %s %s: %v", st.Kind, firstNonEmpty(st.From, st.To), err))
+			left = append([]store.Step{st}, left...)
+			continue
+		}
+		out = append(out, msg)
+		fo.env.Files.Add(changedPaths(st)...)
+	}
+	failed := len(left) > 0
+	if failed {
+		op.Steps = left
+	} else {
+		op.Undone = true
+	}
+	if err := j.store.SaveOperation(op); err != nil {
+		out = append(out, "undo journal: "+err.Error())
+		failed = true
+	}
+	return okIf(strings.Join(out, "\n"), failed)
+}
+
+// undoStep reverses one step, after checking the paths it changes
+// against the policy, and describes what it did. What undo takes away
+// goes to the trash, since it may have been edited since.
+func undoStep(fo *fileOp, st store.Step) (string, error) {
+	if err := fo.guard(stepPaths(st)...); err != nil {
+		return "", err
+	}
+	ctx := fo.ctx
+	switch st.Kind {
+	case "move":
+		if err := mustBeFree(st.From); err != nil {
+			return "", err
+		}
+		if err := moveFile(ctx, st.To, st.From); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Moved back %s -> %s", st.To, st.From), nil
+	case "delete":
+		if err := mustBeFree(st.From); err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(st.From), 0755); err != nil {
+			return "", err
+		}
+		if err := moveFile(ctx, st.Staged, st.From); err != nil {
+			return "", err
+		}
+		trashForget(st.Staged)
+		return "Restored " + st.From, nil
+	case "overwrite":
+		// Entries from before the staging area keep their copy in the
+		// trash, which trashForget then tidies up.
+		if _, err := os.Stat(st.Staged); err != nil {
+			return "", err
+		}
+		if _, err := trashPut(ctx, st.From); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if err := moveFile(ctx, st.Staged, st.From); err != nil {
+			return "", err
+		}
+		trashForget(st.Staged)
+		return "Restored the previous contents of " + st.From, nil
+	case "append":
+		info, err := os.Stat(st.From)
+		if err != nil {
+			return "", err
+		}
+		if info.Size() < st.Size {
+			return "", fmt.Errorf("%s is shorter than before the append; it has changed since", st.From)
+		}
+		if err := os.Truncate(st.From, st.Size); err != nil {
+			return "", err
+		}
+		return "Removed what was appended to " + st.From, nil
+	case "restore":
+		if _, err := trashPut(ctx, st.To); err != nil {
+			return "", err
+		}
+		return "Moved back to the trash " + st.To, nil
+	case "create", "copy":
+		// A directory that was created is only removed while empty.
+		info, err := os.Lstat(st.To)
+		if err != nil {
+			return "", err
+		}
+		if st.Kind == "create" && info.IsDir() {
+			if err := os.Remove(st.To); err != nil {
+				return "", err
+			}
+			return "Removed " + st.To, nil
+		}
+		if _, err := trashPut(ctx, st.To); err != nil {
+			return "", err
+		}
+		return "Moved to the trash " + st.To, nil
+	}
+	return "", fmt.Errorf("unknown step %q", st.Kind)
+}
+
+// stepPaths is changedPaths without the empty ones.
+func stepPaths(st store.Step) []string {
+	var out []string
+	for _, p := range changedPaths(st) {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// undoVerb describes what undoing st does, for a dry run's plan.
+func undoVerb(st store.Step) (string, string) {
+	switch st.Kind {
+	case "move":
+		return "move", st.To + " -> " + st.From
+	case "delete":
+		return "restore", st.From
+	case "overwrite":
+		return "restore", st.From + " (previous contents)"
+	case "append":
+		return "truncate", fmt.Sprintf("%s (to %d bytes)", st.From, st.Size)
+	case "create":
+		if info, err := os.Lstat(st.To); err == nil && info.IsDir() {
+			return "remove", st.To
+		}
+		return "trash", st.To
+	case "restore":
+		return "trash", st.To
+	case "copy":
+		return "trash", st.To
+	}
+	return st.Kind, firstNonEmpty(st.From, st.To)
+}
+
+func mustBeFree(path string) error {
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	return nil
+}
+
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+func (j *Journal) list() Result {
+	ops, err := j.store.Operations(20)
+	if err != nil {
+		return Fail("undo list: " + err.Error())
+	}
+	if len(ops) == 0 {
+		return OK("Journal is empty.")
+	}
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "%-5s %-16s %-7s %-8s %s\n", "ID", "WHEN", "CHANGES", "STATUS", "COMMAND")
+	for _, op := range ops {
+		status := "done"
+		if op.Undone {
+			status = "undone"
+		}
+		fmt.Fprintf(sb, "%-5d %-16s %-7d %-8s %s\n", op.ID, op.Time.Local().Format("2006-01-02 15:04"), len(op.Steps), status, op.Command)
+	}
+	return OK(strings.TrimRight(sb.String(), "\n"))
+}
@@ -12,6 +12,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -19,41 +20,60 @@ import (
 	"github.com/skratchdot/open-golang/open"
 )
 
-func CmdPlay(args []string) string {
+func init() {
+	Register(Command{
+		Name:        "play",
+		Subcommands: []string{"youtube", "yt", "music"},
+		Usage:       "play <youtube|music|file|url>",
+		Summary:     "Play media / open URL",
+		Examples:    []string{"play youtube lofi beats", "play music daft punk"},
+		Run:         CmdPlay,
+	})
+	Register(Command{
+		Name:     "search",
+		Aliases:  []string{"web"},
+		Usage:    "search <query>",
+		Summary:  "Open browser with Google search",
+		Examples: []string{"search golang generics"},
+		Run:      CmdSearch,
+	})
+}
+
+func CmdPlay(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "play: expected 'music <name>' or 'youtube <query>' or a file/url"
+		return Fail("play: expected 'music <name>' or 'youtube <query>' or a file/url")
 	}
 	sub := strings.ToLower(args[0])
 	rest := strings.Join(args[1:], " ")
 	switch sub {
 	case "youtube", "yt":
 		if rest == "" {
-			return "play youtube: expected search query"
+			return Fail("play youtube: expected search query")
 		}
 		q := url.QueryEscape(rest)
 		open.Run("https://www.youtube.com/results?search_query=" + q)
-		return fmt.Sprintf("Searching YouTube for: %s", rest)
+		return OK(fmt.Sprintf("Searching YouTube for: %s", rest))
 	case "music":
 		if rest == "" {
-			return "play music: expected query"
+			return Fail("play music: expected query")
 		}
 		q := url.QueryEscape(rest)
 		open.Run("https://open.spotify.com/search/" + q)
-		return fmt.Sprintf("Opening Spotify search: %s", rest)
+		return OK(fmt.Sprintf("Opening Spotify search: %s", rest))
 	default:
 		target := strings.Join(args, " ")
 		if err := open.Run(target); err == nil {
-			return "Playing/opening: " + target
+			return OK("Playing/opening: " + target)
 		}
-		return "play: couldn't open target. If it's a local file, provide full path."
+		return Fail("play: couldn't open target. If it's a local file, provide full path.")
 	}
 }
 
-func CmdSearch(args []string) string {
+func CmdSearch(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "search: expected query"
+		return Fail("search: expected query")
 	}
 	q := url.QueryEscape(strings.Join(args, " "))
 	open.Run("https://www.google.com/search?q=" + q)
-	return "Opened browser search."
+	return OK("Opened browser search.")
 }
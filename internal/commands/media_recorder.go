@@ -12,21 +12,36 @@
 package commands
 
 import (
+	"context"
 	"strings"
 )
 
-func CmdMediaControl(args []string) string {
+func init() {
+	for _, verb := range []string{"pause", "next", "prev"} {
+		v := verb
+		Register(Command{
+			Name:    v,
+			Usage:   v,
+			Summary: "Media control: " + v,
+			Run: func(ctx context.Context, args []string) Result {
+				return CmdMediaControl(ctx, append([]string{v}, args...))
+			},
+		})
+	}
+}
+
+func CmdMediaControl(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "media control: expected pause/next/prev"
+		return Fail("media control: expected pause/next/prev")
 	}
 	verb := strings.ToLower(args[0])
 	switch verb {
 	case "pause", "play", "toggle":
-		return "media control: use your player-specific controls (no universal control implemented)."
+		return Fail("media control: use your player-specific controls (no universal control implemented).")
 	case "next", "prev":
-		return "media control: next/prev not implemented universally. Use your media player controls."
+		return Fail("media control: next/prev not implemented universally. Use your media player controls.")
 	default:
-		return "media control: unknown subcommand"
+		return Fail("media control: unknown subcommand")
 	}
 }
 
@@ -12,22 +12,40 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 	"unicode"
 )
 
-func CmdCalc(args []string) string {
+func init() {
+	Register(Command{
+		Name:     "calc",
+		Usage:    "calc <expression>",
+		Summary:  "Calculator",
+		Examples: []string{"calc 2+2*3"},
+		Run:      CmdCalc,
+	})
+	Register(Command{
+		Name:     "echo",
+		Usage:    "echo <text>",
+		Summary:  "Print text",
+		Examples: []string{"echo hello $name"},
+		Run:      func(ctx context.Context, args []string) Result { return OK(strings.Join(args, " ")) },
+	})
+}
+
+func CmdCalc(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "calc: expected expression, e.g. `calc 2+2*3`"
+		return Fail("calc: expected expression, e.g. `calc 2+2*3`")
 	}
 	expr := strings.Join(args, " ")
 	val, err := evalSimple(expr)
 	if err != nil {
-		return "calc error: " + err.Error()
+		return Fail("calc error: " + err.Error())
 	}
-	return fmt.Sprintf("%s = %g", expr, val)
+	return OK(fmt.Sprintf("%s = %g", expr, val))
 }
 
 func evalSimple(s string) (float64, error) {
@@ -15,21 +15,45 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
 )
 
-func CmdNet(args []string) string {
+func init() {
+	Register(Command{
+		Name:        "net",
+		Aliases:     []string{"network"},
+		Subcommands: []string{"wifi", "wireless"},
+		Usage:       "net wifi <list|on|off>",
+		Summary:     "Manage wifi (nmcli / netsh)",
+		Examples:    []string{"net wifi list", "net wifi off"},
+		Run:         CmdNet,
+		Complete: func(ctx context.Context, args []string) []Candidate {
+			if len(args) == 1 && isWifi(args[0]) {
+				return Words("list", "on", "off")
+			}
+			return nil
+		},
+	})
+}
+
+func isWifi(s string) bool {
+	s = strings.ToLower(s)
+	return s == "wifi" || s == "wireless"
+}
+
+func CmdNet(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "net: expected subcommand, e.g. `net wifi list|on|off`"
+		return Fail("net: expected subcommand, e.g. `net wifi list|on|off`")
 	}
 	sub := strings.ToLower(args[0])
 	switch sub {
 	case "wifi", "wireless":
 		if len(args) < 2 {
-			return "net wifi: expected `list`, `on`, or `off`"
+			return Fail("net wifi: expected `list`, `on`, or `off`")
 		}
 		op := strings.ToLower(args[1])
 		switch op {
@@ -40,53 +64,53 @@ func CmdNet(args []string) string {
 		case "off":
 			return wifiToggle(false)
 		default:
-			return "net wifi: unknown op. Use list|on|off"
+			return Fail("net wifi: unknown op. Use list|on|off")
 		}
 	default:
-		return "net: unknown subcommand. Try `net wifi list|on|off`"
+		return Fail("net: unknown subcommand. Try `net wifi list|on|off`")
 	}
 }
 
-func wifiList() string {
+func wifiList() Result {
 	switch runtime.GOOS {
 	case "windows":
 		if p, _ := exec.LookPath("netsh"); p != "" {
 			out, err := exec.Command(p, "wlan", "show", "networks").CombinedOutput()
 			if err != nil {
-				return "wifi list error: " + err.Error() + " — " + strings.TrimSpace(string(out))
+				return Fail("wifi list error: " + err.Error() + " — " + strings.TrimSpace(string(out)))
 			}
-			return string(out)
+			return OK(string(out))
 		}
-		return "wifi list: netsh not found on PATH (Windows)."
+		return Fail("wifi list: netsh not found on PATH (Windows).")
 	case "darwin":
 		if _, err := exec.LookPath("/System/Library/PrivateFrameworks/Apple80211.framework/Versions/Current/Resources/airport"); err == nil {
 			out, err := exec.Command("/System/Library/PrivateFrameworks/Apple80211.framework/Versions/Current/Resources/airport", "-s").CombinedOutput()
 			if err != nil {
-				return "wifi list error: " + err.Error()
+				return Fail("wifi list error: " + err.Error())
 			}
-			return string(out)
+			return OK(string(out))
 		}
-		return "wifi list: airport tool not available."
+		return Fail("wifi list: airport tool not available.")
 	default:
 		if p, _ := exec.LookPath("nmcli"); p != "" {
 			out, err := exec.Command(p, "device", "wifi", "list").CombinedOutput()
 			if err != nil {
-				return "wifi list error: " + err.Error() + " — " + strings.TrimSpace(string(out))
+				return Fail("wifi list error: " + err.Error() + " — " + strings.TrimSpace(string(out)))
 			}
-			return string(out)
+			return OK(string(out))
 		}
 		if p, _ := exec.LookPath("iwlist"); p != "" {
 			out, err := exec.Command(p, "scan").CombinedOutput()
 			if err != nil {
-				return "wifi list error: " + err.Error() + " — " + strings.TrimSpace(string(out))
+				return Fail("wifi list error: " + err.Error() + " — " + strings.TrimSpace(string(out)))
 			}
-			return string(out)
+			return OK(string(out))
 		}
-		return "wifi list: no wifi tool found (install NetworkManager/nmcli or iwlist)."
+		return Fail("wifi list: no wifi tool found (install NetworkManager/nmcli or iwlist).")
 	}
 }
 
-func wifiToggle(on bool) string {
+func wifiToggle(on bool) Result {
 	switch runtime.GOOS {
 	case "windows":
 		state := map[bool]string{true: "Enabled", false: "Disabled"}[on]
@@ -94,11 +118,11 @@ func wifiToggle(on bool) string {
 		power := exec.Command("powershell", "-NoProfile", "-Command", ps)
 		if out, err := power.CombinedOutput(); err == nil {
 			if strings.Contains(string(out), "No adapter named Wi-Fi found") {
-				return "wifi toggle: no adapter named 'Wi-Fi' found. Check adapter name or use Windows tools."
+				return Fail("wifi toggle: no adapter named 'Wi-Fi' found. Check adapter name or use Windows tools.")
 			}
-			return fmt.Sprintf("Wi-Fi %s (PowerShell).", map[bool]string{true: "enabled", false: "disabled"}[on])
+			return OK(fmt.Sprintf("Wi-Fi %s (PowerShell).", map[bool]string{true: "enabled", false: "disabled"}[on]))
 		} else {
-			return "wifi toggle error (PowerShell): " + err.Error() + " — " + strings.TrimSpace(string(out))
+			return Fail("wifi toggle error (PowerShell): " + err.Error() + " — " + strings.TrimSpace(string(out)))
 		}
 	case "darwin":
 		if p, _ := exec.LookPath("networksetup"); p != "" {
@@ -108,12 +132,12 @@ func wifiToggle(on bool) string {
 			}
 			cmd := exec.Command(p, "-setairportpower", "en0", state)
 			if out, err := cmd.CombinedOutput(); err == nil {
-				return fmt.Sprintf("Wi-Fi %s (networksetup).", state)
+				return OK(fmt.Sprintf("Wi-Fi %s (networksetup).", state))
 			} else {
-				return "wifi toggle error: " + err.Error() + " — " + strings.TrimSpace(string(out))
+				return Fail("wifi toggle error: " + err.Error() + " — " + strings.TrimSpace(string(out)))
 			}
 		}
-		return "wifi toggle: networksetup tool not found."
+		return Fail("wifi toggle: networksetup tool not found.")
 	default:
 		if p, _ := exec.LookPath("nmcli"); p != "" {
 			state := "on"
@@ -122,11 +146,11 @@ func wifiToggle(on bool) string {
 			}
 			cmd := exec.Command(p, "radio", "wifi", state)
 			if out, err := cmd.CombinedOutput(); err == nil {
-				return fmt.Sprintf("Wi-Fi %s (nmcli).", state)
+				return OK(fmt.Sprintf("Wi-Fi %s (nmcli).", state))
 			} else {
-				return "wifi toggle error: " + err.Error() + " — " + strings.TrimSpace(string(out))
+				return Fail("wifi toggle error: " + err.Error() + " — " + strings.TrimSpace(string(out)))
 			}
 		}
-		return "wifi toggle: nmcli (NetworkManager) not found. Use your distro's tools or install NetworkManager."
+		return Fail("wifi toggle: nmcli (NetworkManager) not found. Use your distro's tools or install NetworkManager.")
 	}
 }
@@ -15,6 +15,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -32,15 +33,62 @@ type Network struct {
 
 var lastNetworks []Network
 
-func CmdNet(args []string) string {
+func init() {
+	Register(Command{
+		Name:        "net",
+		Aliases:     []string{"network"},
+		Subcommands: []string{"wifi", "wireless"},
+		Usage:       "net wifi <list|on|off|connect [number [password]]|forget|saved>",
+		Summary:     "Manage wifi (netsh)",
+		Examples:    []string{"net wifi list", "net wifi connect 3", "net wifi saved"},
+		Run:         CmdNet,
+		Complete:    completeNet,
+		Secret:      netSecret,
+	})
+}
+
+// completeNet offers the wifi operations, and for connect and forget the
+// networks found by the last `net wifi list`.
+func completeNet(ctx context.Context, args []string) []Candidate {
+	if len(args) == 0 || !isWifi(args[0]) {
+		return nil
+	}
+	switch {
+	case len(args) == 1:
+		return Words("list", "on", "off", "connect", "forget", "saved")
+	case len(args) == 2 && (strings.EqualFold(args[1], "connect") || strings.EqualFold(args[1], "forget")):
+		out := make([]Candidate, len(lastNetworks))
+		for i, n := range lastNetworks {
+			out[i] = Candidate{Text: strconv.Itoa(i + 1), Note: n.SSID}
+		}
+		return out
+	}
+	return nil
+}
+
+// netSecret marks the password of `net wifi connect <number> <password>`,
+// so it is not saved to the history.
+func netSecret(args []string) []int {
+	if len(args) == 4 && isWifi(args[0]) && strings.EqualFold(args[1], "connect") {
+		return []int{3}
+	}
+	return nil
+}
+
+func isWifi(s string) bool {
+	s = strings.ToLower(s)
+	return s == "wifi" || s == "wireless"
+}
+
+func CmdNet(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "net: expected subcommand, e.g. `net wifi list|on|off|connect|forget|saved`"
+		return Fail("net: expected subcommand, e.g. `net wifi list|on|off|connect|forget|saved`")
 	}
 	sub := strings.ToLower(args[0])
 	switch sub {
 	case "wifi", "wireless":
 		if len(args) < 2 {
-			return "net wifi: expected `list`, `on`, `off`, `connect`, `forget`, or `saved`"
+			return Fail("net wifi: expected `list`, `on`, `off`, `connect`, `forget`, or `saved`")
 		}
 		op := strings.ToLower(args[1])
 		switch op {
@@ -52,37 +100,40 @@ func CmdNet(args []string) string {
 			return wifiToggle(false)
 		case "connect":
 			if len(args) < 3 {
-				return "net wifi connect: expected an index (e.g. `net wifi connect 3` or `net wifi connect 3 <password>`)"
+				return wifiChoose(ctx)
+			}
+			if len(args) > 4 {
+				return Fail("net wifi connect: expected an index and an optional password (e.g. `net wifi connect 3`)")
 			}
 			idx, err := strconv.Atoi(args[2])
 			if err != nil || idx <= 0 {
-				return "net wifi connect: invalid index"
+				return Fail("net wifi connect: invalid index")
 			}
-			var pwd string
-			if len(args) >= 4 {
-				pwd = args[3]
+			password := ""
+			if len(args) == 4 {
+				password = args[3]
 			}
-			return wifiConnect(idx-1, pwd)
+			return wifiConnect(ctx, idx-1, password)
 		case "forget":
 			if len(args) < 3 {
-				return "net wifi forget: expected an index (e.g. `net wifi forget 2`)"
+				return Fail("net wifi forget: expected an index (e.g. `net wifi forget 2`)")
 			}
 			idx, err := strconv.Atoi(args[2])
 			if err != nil || idx <= 0 {
-				return "net wifi forget: invalid index"
+				return Fail("net wifi forget: invalid index")
 			}
 			return wifiForget(idx - 1)
 		case "saved":
 			return wifiSaved()
 		default:
-			return "net wifi: unknown op. Use list|on|off|connect|forget|saved"
+			return Fail("net wifi: unknown op. Use list|on|off|connect|forget|saved")
 		}
 	default:
-		return "net: unknown subcommand. Try `net wifi list|on|off|connect|forget|saved`"
+		return Fail("net: unknown subcommand. Try `net wifi list|on|off|connect|forget|saved`")
 	}
 }
 
-func wifiList() string {
+func wifiList() Result {
 	out, _ := exec.Command("netsh", "wlan", "show", "networks", "mode=bssid").CombinedOutput()
 	clean := sanitizeOutput(strings.TrimSpace(string(out)))
 	if clean == "" {
@@ -130,9 +181,9 @@ func wifiList() string {
 
 	if len(nets) == 0 {
 		if clean != "" {
-			return "No networks parsed. Raw output:\n" + clean
+			return OK("No networks parsed. Raw output:\n" + clean)
 		}
-		return "No Wi-Fi networks found."
+		return OK("No Wi-Fi networks found.")
 	}
 
 	sb := &strings.Builder{}
@@ -143,15 +194,15 @@ func wifiList() string {
 		}
 		sb.WriteString("\n")
 	}
-	sb.WriteString("\nTip: connect with `net wifi connect <number>` or `net wifi connect <number> <password>`\n")
-	return sb.String()
+	sb.WriteString("\nTip: connect with `net wifi connect <number>`; you are asked for the password if it is needed\n")
+	return OK(sb.String())
 }
 
-func wifiSaved() string {
+func wifiSaved() Result {
 	out, err := exec.Command("netsh", "wlan", "show", "profiles").CombinedOutput()
 	clean := sanitizeOutput(strings.TrimSpace(string(out)))
 	if err != nil && clean == "" {
-		return "Failed to query saved profiles: " + err.Error()
+		return Fail("Failed to query saved profiles: " + err.Error())
 	}
 
 	lines := strings.Split(clean, "\n")
@@ -188,9 +239,9 @@ func wifiSaved() string {
 
 	if len(profiles) == 0 {
 		if clean != "" {
-			return "No saved profiles parsed. Raw output:\n" + clean
+			return OK("No saved profiles parsed. Raw output:\n" + clean)
 		}
-		return "No saved Wi-Fi profiles found."
+		return OK("No saved Wi-Fi profiles found.")
 	}
 
 	sb := &strings.Builder{}
@@ -202,10 +253,10 @@ func wifiSaved() string {
 		fmt.Fprintf(sb, "%d) %s%s\n", i+1, p, flag)
 	}
 	sb.WriteString("\nTip: forget a profile with `net wifi forget <number-from-listed-scan>` (or run netsh commands directly).\n")
-	return sb.String()
+	return OK(sb.String())
 }
 
-func wifiToggle(enable bool) string {
+func wifiToggle(enable bool) Result {
 	action := "Enable-NetAdapter"
 	if !enable {
 		action = "Disable-NetAdapter"
@@ -232,11 +283,11 @@ exit 0
 
 	l := strings.ToLower(clean)
 	if strings.Contains(l, "access is denied") || strings.Contains(l, "requires elevation") || strings.Contains(l, "requires administrator") {
-		return "wifi toggle failed: requires Administrator privileges. Run 0xRootShell elevated (right-click → Run as administrator) and try again."
+		return Fail("wifi toggle failed: requires Administrator privileges. Run 0xRootShell elevated (right-click → Run as administrator) and try again.")
 	}
 
 	if strings.Contains(l, "no adapter matching") || strings.Contains(l, "no adapter named") || strings.Contains(l, "no adapter") {
-		return "Wi-Fi toggle attempted: no adapter named like '*Wi-Fi*' found. Run `Get-NetAdapter` in PowerShell to see exact adapter names."
+		return Fail("Wi-Fi toggle attempted: no adapter named like '*Wi-Fi*' found. Run `Get-NetAdapter` in PowerShell to see exact adapter names.")
 	}
 
 	state := "disabled"
@@ -245,14 +296,33 @@ exit 0
 	}
 
 	if clean == "" {
-		return fmt.Sprintf("Wi-Fi %s attempted.", state)
+		return OK(fmt.Sprintf("Wi-Fi %s attempted.", state))
 	}
-	return fmt.Sprintf("Wi-Fi %s attempted.\n%s", state, clean)
+	return OK(fmt.Sprintf("Wi-Fi %s attempted.\n%s", state, clean))
 }
 
-func wifiConnect(index int, password string) string {
+// wifiChoose asks which of the listed networks to connect to.
+func wifiChoose(ctx context.Context) Result {
+	if len(lastNetworks) == 0 {
+		return Fail("net wifi connect: expected an index (e.g. `net wifi connect 3`); run `net wifi list` first")
+	}
+	names := make([]string, len(lastNetworks))
+	for i, n := range lastNetworks {
+		names[i] = n.SSID
+	}
+	i, err := Choose(ctx, "Connect to which network?", names)
+	if err != nil {
+		return Fail("net wifi connect: " + err.Error() + "; give the index as an argument")
+	}
+	return wifiConnect(ctx, i, "")
+}
+
+// wifiConnect connects to a network found by the last `net wifi list`.
+// A network without a saved profile needs a password; if none is given
+// it is asked for.
+func wifiConnect(ctx context.Context, index int, password string) Result {
 	if index < 0 || index >= len(lastNetworks) {
-		return "net wifi connect: index out of range. Run `net wifi list` first."
+		return Fail("net wifi connect: index out of range. Run `net wifi list` first.")
 	}
 	netw := lastNetworks[index]
 	ssid := netw.SSID
@@ -263,13 +333,20 @@ func wifiConnect(index int, password string) string {
 		out, err := exec.Command("netsh", "wlan", "connect", "name="+ssid).CombinedOutput()
 		clean := sanitizeOutput(strings.TrimSpace(string(out)))
 		if err != nil {
-			return fmt.Sprintf("Failed to connect to saved profile %s: %v\n%s", ssid, err, clean)
+			return Failf("Failed to connect to saved profile %s: %v\n%s", ssid, err, clean)
 		}
-		return fmt.Sprintf("Connecting to saved profile %s...\n%s", ssid, clean)
+		return OK(fmt.Sprintf("Connecting to saved profile %s...\n%s", ssid, clean))
 	}
 
 	if password == "" {
-		return fmt.Sprintf("PROMPT_PASSWORD:%d:%s", index, ssid)
+		var err error
+		password, err = AskHidden(ctx, fmt.Sprintf("Password for '%s':", ssid))
+		if err != nil {
+			return Fail("net wifi connect: " + err.Error() + "; give the password as an argument (`net wifi connect <number> <password>`)")
+		}
+	}
+	if password == "" {
+		return Fail("net wifi connect: no password given")
 	}
 
 	tempDir := filepath.Join("data", "wifi_profiles")
@@ -303,7 +380,7 @@ func wifiConnect(index int, password string) string {
 </WLANProfile>`, ssid, ssid, xmlEscape(password))
 
 	if err := os.WriteFile(fn, []byte(xml), 0600); err != nil {
-		return "Failed to write temporary profile: " + err.Error()
+		return Fail("Failed to write temporary profile: " + err.Error())
 	}
 	defer func() {
 		_ = os.Remove(fn)
@@ -311,29 +388,29 @@ func wifiConnect(index int, password string) string {
 
 	if out, err := exec.Command("netsh", "wlan", "add", "profile", "filename=\""+fn+"\"", "user=current").CombinedOutput(); err != nil {
 		clean := sanitizeOutput(strings.TrimSpace(string(out)))
-		return fmt.Sprintf("Failed to add profile: %v\n%s", err, clean)
+		return Failf("Failed to add profile: %v\n%s", err, clean)
 	}
 
 	if out, err := exec.Command("netsh", "wlan", "connect", "name="+ssid).CombinedOutput(); err != nil {
 		clean := sanitizeOutput(strings.TrimSpace(string(out)))
-		return fmt.Sprintf("Failed to connect to %s: %v\n%s", ssid, err, clean)
+		return Failf("Failed to connect to %s: %v\n%s", ssid, err, clean)
 	} else {
 		clean := sanitizeOutput(strings.TrimSpace(string(out)))
-		return fmt.Sprintf("Connecting to %s...\n%s", ssid, clean)
+		return OK(fmt.Sprintf("Connecting to %s...\n%s", ssid, clean))
 	}
 }
 
-func wifiForget(index int) string {
+func wifiForget(index int) Result {
 	if index < 0 || index >= len(lastNetworks) {
-		return "net wifi forget: index out of range. Run `net wifi list` first."
+		return Fail("net wifi forget: index out of range. Run `net wifi list` first.")
 	}
 	ssid := lastNetworks[index].SSID
 	out, err := exec.Command("netsh", "wlan", "delete", "profile", "name="+ssid).CombinedOutput()
 	clean := sanitizeOutput(strings.TrimSpace(string(out)))
 	if err != nil {
-		return fmt.Sprintf("Failed to forget profile %s: %v\n%s", ssid, err, clean)
+		return Failf("Failed to forget profile %s: %v\n%s", ssid, err, clean)
 	}
-	return fmt.Sprintf("Forgot profile %s.\n%s", ssid, clean)
+	return OK(fmt.Sprintf("Forgot profile %s.\n%s", ssid, clean))
 }
 
 func xmlEscape(s string) string {
@@ -0,0 +1,40 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"net"
+)
+
+// NetStatus names the network interface in use, the first one that is up
+// with an address other than loopback, or says there is none.
+func NetStatus() string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "unknown"
+	}
+	for _, ifc := range ifaces {
+		if ifc.Flags&net.FlagUp == 0 || ifc.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := ifc.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if ip, ok := a.(*net.IPNet); ok && ip.IP.IsGlobalUnicast() {
+				return ifc.Name
+			}
+		}
+	}
+	return "offline"
+}
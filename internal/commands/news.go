@@ -11,6 +11,7 @@
 package commands
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -20,7 +21,16 @@ import (
 	"time"
 )
 
-func CmdNews(args []string) string {
+func init() {
+	Register(Command{
+		Name:    "news",
+		Usage:   "news [topic]",
+		Summary: "Fetch latest news",
+		Run:     CmdNews,
+	})
+}
+
+func CmdNews(ctx context.Context, args []string) Result {
 	query := ""
 	if len(args) > 0 {
 		query = strings.Join(args, " ")
@@ -40,17 +50,17 @@ func CmdNews(args []string) string {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "news: failed to fetch headlines: " + err.Error()
+		return Fail("news: failed to fetch headlines: " + err.Error())
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Sprintf("news: remote returned status %d", resp.StatusCode)
+		return Failf("news: remote returned status %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "news: read error: " + err.Error()
+		return Fail("news: read error: " + err.Error())
 	}
 
 	type Item struct {
@@ -65,11 +75,11 @@ func CmdNews(args []string) string {
 
 	var r Rss
 	if err := xml.Unmarshal(body, &r); err != nil {
-		return "news: xml parse error: " + err.Error()
+		return Fail("news: xml parse error: " + err.Error())
 	}
 
 	if len(r.Channel.Items) == 0 {
-		return "news: no headlines found"
+		return Fail("news: no headlines found")
 	}
 
 	limit := 5
@@ -86,5 +96,5 @@ func CmdNews(args []string) string {
 		}
 		out.WriteString(fmt.Sprintf("%d) %s\n", i+1, title))
 	}
-	return strings.TrimSpace(out.String())
+	return OK(strings.TrimSpace(out.String()))
 }
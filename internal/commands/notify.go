@@ -12,6 +12,7 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -27,6 +28,31 @@ type OutgoingMsg struct {
 	Timestamp time.Time `json:"ts"`
 }
 
+func init() {
+	Register(Command{
+		Name:        "message",
+		Aliases:     []string{"msg"},
+		Subcommands: []string{"send"},
+		Usage:       "message send <contact> \"text\"",
+		Summary:     "Queue an outgoing message",
+		Run:         CmdMessage,
+	})
+	Register(Command{
+		Name:        "notify",
+		Subcommands: []string{"list", "send"},
+		Usage:       "notify <list|send <text>>",
+		Summary:     "Send a notification",
+		Run:         CmdNotify,
+	})
+	Register(Command{
+		Name:        "mail",
+		Subcommands: []string{"check", "open"},
+		Usage:       "mail <check|open>",
+		Summary:     "Mail helper",
+		Run:         CmdMail,
+	})
+}
+
 func messagesFilePath() string {
 	_ = os.MkdirAll("data/outgoing_messages", 0755)
 	return filepath.Join("data", "outgoing_messages", "messages.json")
@@ -43,9 +69,9 @@ func appendMessage(m OutgoingMsg) error {
 	return os.WriteFile(fn, nb, 0644)
 }
 
-func CmdMessage(args []string) string {
+func CmdMessage(ctx context.Context, args []string) Result {
 	if len(args) < 2 {
-		return "message: expected 'message send <contact> \"text\"'"
+		return Fail("message: expected 'message send <contact> \"text\"'")
 	}
 	if strings.ToLower(args[0]) == "send" {
 		to := args[1]
@@ -58,24 +84,24 @@ func CmdMessage(args []string) string {
 			Timestamp: time.Now().UTC(),
 		}
 		if err := appendMessage(msg); err != nil {
-			return "message: save error: " + err.Error()
+			return Fail("message: save error: " + err.Error())
 		}
-		return fmt.Sprintf("Message queued to %s: %s", to, text)
+		return OK(fmt.Sprintf("Message queued to %s: %s", to, text))
 	}
-	return "message: unknown subcommand"
+	return Fail("message: unknown subcommand")
 }
 
-func CmdNotify(args []string) string {
+func CmdNotify(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "notify: expected subcommand list/send"
+		return Fail("notify: expected subcommand list/send")
 	}
 	switch strings.ToLower(args[0]) {
 	case "list":
 		fn := messagesFilePath()
 		if b, err := os.ReadFile(fn); err == nil {
-			return string(b)
+			return OK(string(b))
 		}
-		return "notify: none"
+		return OK("notify: none")
 	case "send":
 		text := strings.Join(args[1:], " ")
 		text = strings.Trim(text, "\"")
@@ -86,27 +112,27 @@ func CmdNotify(args []string) string {
 			Timestamp: time.Now().UTC(),
 		}
 		if err := appendMessage(msg); err != nil {
-			return "notify send error: " + err.Error()
+			return Fail("notify send error: " + err.Error())
 		}
-		return "Notification saved."
+		return OK("Notification saved.")
 	default:
-		return "notify: unknown subcommand"
+		return Fail("notify: unknown subcommand")
 	}
 }
 
-func CmdMail(args []string) string {
+func CmdMail(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "mail: expected 'check' or 'open' or 'compose'"
+		return Fail("mail: expected 'check' or 'open' or 'compose'")
 	}
 	switch strings.ToLower(args[0]) {
 	case "check":
-		return "mail: mail integration not configured. Consider connecting an email plugin in plugins/."
+		return OK("mail: mail integration not configured. Consider connecting an email plugin in plugins/.")
 	case "open":
 		if err := runOpen("mailto:"); err != nil {
-			return "mail open error: " + err.Error()
+			return Fail("mail open error: " + err.Error())
 		}
-		return "Opened mail client"
+		return OK("Opened mail client")
 	default:
-		return "mail: unknown subcommand"
+		return Fail("mail: unknown subcommand")
 	}
 }
@@ -0,0 +1,24 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+//go:build windows
+// +build windows
+
+package commands
+
+import "os"
+
+// ownedByUser reports whether the file described by info belongs to the
+// user running the shell. The temp directory on Windows is per user, so
+// everything in it counts.
+func ownedByUser(info os.FileInfo) bool {
+	return true
+}
@@ -0,0 +1,237 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Policy holds the safety rules the engine applies to every command.
+// Confirm lists command patterns that must be confirmed before they run:
+// the first word names the command (or one of its aliases) and every
+// further word must appear among the arguments, in any order, so
+// "del -r" matches `del build -r`; "> file" matches redirections to
+// file, so "> ~/.bashrc" asks before `echo x >> ~/.bashrc`. Protected
+// paths may not be changed themselves; protected trees may not be
+// changed anywhere beneath them. Roots, when set, is a sandbox: commands
+// may not use any path outside these directories, however it is
+// reached.
+type Policy struct {
+	Confirm        []string `json:"confirm"`
+	Protected      []string `json:"protected"`
+	ProtectedTrees []string `json:"protected_trees"`
+	Roots          []string `json:"roots,omitempty"`
+}
+
+// ErrOutsideRoots is the error for a path the sandbox does not allow.
+var ErrOutsideRoots = errors.New("outside the allowed roots")
+
+func DefaultPolicy() *Policy {
+	p := &Policy{
+		Confirm: []string{
+			"del -r", "del --recursive", "del --permanent",
+			"rm -r", "rm --recursive", "rm --permanent",
+			"rmdir -r", "rmdir --force", "rmdir --permanent",
+			"remove -r", "remove --force", "remove --permanent",
+			"kill",
+			"clear browser history",
+			"remind clear",
+			"goal clear",
+			"sys off", "sys shutdown",
+			"file clean temp --apply",
+			"trash empty",
+		},
+		Protected: []string{"~", "/"},
+	}
+	switch runtime.GOOS {
+	case "windows":
+		p.Protected = []string{"~", `C:\`}
+		p.ProtectedTrees = []string{`C:\Windows`, `C:\Program Files`, `C:\Program Files (x86)`, `C:\ProgramData`}
+	case "darwin":
+		p.ProtectedTrees = []string{"/System", "/Library", "/Applications", "/bin", "/sbin", "/usr", "/etc", "/var", "/private"}
+	default:
+		p.ProtectedTrees = []string{"/bin", "/boot", "/dev", "/etc", "/lib", "/lib64", "/proc", "/sbin", "/sys", "/usr", "/var"}
+	}
+	return p
+}
+
+// LoadPolicy reads a policy from a JSON file. Lists the file leaves out
+// keep their defaults, and a missing file means the defaults.
+func LoadPolicy(file string) (*Policy, error) {
+	p := DefaultPolicy()
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(b, p); err != nil {
+		return DefaultPolicy(), fmt.Errorf("%s: %v", file, err)
+	}
+	return p, nil
+}
+
+// ConfirmRule returns the first confirm rule that matches cmd run with
+// args.
+func (p *Policy) ConfirmRule(cmd *Command, args []string) (string, bool) {
+	for _, rule := range p.Confirm {
+		words := strings.Fields(rule)
+		if len(words) == 0 || !hasFold(cmd.Names(), words[0]) {
+			continue
+		}
+		ok := true
+		for _, w := range words[1:] {
+			if !hasFold(args, w) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return rule, true
+		}
+	}
+	return "", false
+}
+
+// ConfirmRedirect returns the first confirm rule that matches a
+// redirection to path: "> file" matches both > and >>, ">> file" only >>.
+func (p *Policy) ConfirmRedirect(path string, appendMode bool) (string, bool) {
+	for _, rule := range p.Confirm {
+		words := strings.Fields(rule)
+		if len(words) != 2 || (words[0] != ">" && words[0] != ">>") {
+			continue
+		}
+		if words[0] == ">>" && !appendMode {
+			continue
+		}
+		if samePath(filepath.Clean(path), policyPath(words[1])) {
+			return rule, true
+		}
+	}
+	return "", false
+}
+
+func hasFold(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPath refuses changes to path if it is protected.
+func (p *Policy) CheckPath(path string) error {
+	path = filepath.Clean(path)
+	for _, prot := range p.Protected {
+		if samePath(path, policyPath(prot)) {
+			return fmt.Errorf("%s is protected", path)
+		}
+	}
+	for _, tree := range p.ProtectedTrees {
+		if within(path, policyPath(tree)) {
+			return fmt.Errorf("%s is protected (under %s)", path, tree)
+		}
+	}
+	return nil
+}
+
+// Sandboxed reports whether p confines commands to its roots.
+func (p *Policy) Sandboxed() bool {
+	return p != nil && len(p.Roots) > 0
+}
+
+// CheckRoots refuses path when the sandbox is on and path, once symlinks
+// are resolved, is not inside one of the roots.
+func (p *Policy) CheckRoots(path string) error {
+	if !p.Sandboxed() {
+		return nil
+	}
+	real := realPath(path)
+	for _, r := range p.Roots {
+		if within(real, realPath(policyPath(r))) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w", path, ErrOutsideRoots)
+}
+
+// StartDir is where a session that would start in wd starts: wd itself,
+// or the first root that is a directory when the sandbox does not allow
+// wd.
+func (p *Policy) StartDir(wd string) string {
+	if p.CheckRoots(wd) == nil {
+		return wd
+	}
+	for _, r := range p.Roots {
+		if info, err := os.Stat(policyPath(r)); err == nil && info.IsDir() {
+			return policyPath(r)
+		}
+	}
+	return wd
+}
+
+// realPath resolves the symlinks in path. For a path that does not exist
+// yet, the longest existing parent is resolved and the rest kept.
+func realPath(path string) string {
+	path = filepath.Clean(path)
+	rest := ""
+	for {
+		if r, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(r, rest)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(path, rest)
+		}
+		rest = filepath.Join(filepath.Base(path), rest)
+		path = parent
+	}
+}
+
+func policyPath(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[1:])
+		}
+	}
+	return filepath.Clean(p)
+}
+
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// within reports whether path is dir or lies beneath it.
+func within(path, dir string) bool {
+	if samePath(path, dir) {
+		return true
+	}
+	prefix := dir
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	if len(path) < len(prefix) {
+		return false
+	}
+	return samePath(path[:len(prefix)], prefix)
+}
@@ -0,0 +1,115 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PromptKind is the sort of answer a Prompt wants.
+type PromptKind int
+
+const (
+	PromptText    PromptKind = iota // a line of text
+	PromptHidden                    // text that is not shown, like a password
+	PromptConfirm                   // yes or no
+	PromptChoice                    // one of Choices
+)
+
+// Prompt is a question a command asks the user while it runs. The
+// command waits for the answer, which is never saved to the history.
+type Prompt struct {
+	Kind    PromptKind
+	Message string
+	Choices []string
+}
+
+var (
+	// ErrNoPrompt is returned when there is nobody to ask, as when
+	// running a script or rootsh -c.
+	ErrNoPrompt = errors.New("no terminal to ask")
+	// ErrPromptCancelled is returned when the user declines to answer.
+	ErrPromptCancelled = errors.New("cancelled")
+)
+
+// Parse checks an answer typed for p and returns it in canonical form:
+// "y" or "n" for a confirmation, and for a choice the number, from 1, of
+// the item picked by number or by name, since names may repeat. Text is
+// returned as typed.
+func (p Prompt) Parse(answer string) (string, error) {
+	switch p.Kind {
+	case PromptConfirm:
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return "y", nil
+		case "", "n", "no":
+			return "n", nil
+		}
+		return "", errors.New("answer y or n")
+	case PromptChoice:
+		answer = strings.TrimSpace(answer)
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(p.Choices) {
+			return strconv.Itoa(n), nil
+		}
+		for i, c := range p.Choices {
+			if strings.EqualFold(c, answer) {
+				return strconv.Itoa(i + 1), nil
+			}
+		}
+		return "", fmt.Errorf("choose 1-%d", len(p.Choices))
+	}
+	return answer, nil
+}
+
+// Ask puts p to the user of the session the command runs in and waits
+// for the answer, as returned by Parse.
+func Ask(ctx context.Context, p Prompt) (string, error) {
+	env := EnvOf(ctx)
+	if env.Ask == nil {
+		return "", ErrNoPrompt
+	}
+	return env.Ask(ctx, p)
+}
+
+// Confirm asks a yes or no question; no is the default.
+func Confirm(ctx context.Context, msg string) (bool, error) {
+	a, err := Ask(ctx, Prompt{Kind: PromptConfirm, Message: msg})
+	return a == "y", err
+}
+
+// AskText asks for a line of text, which is shown as it is typed.
+func AskText(ctx context.Context, msg string) (string, error) {
+	return Ask(ctx, Prompt{Kind: PromptText, Message: msg})
+}
+
+// AskHidden asks for text, like a password, that is not shown as it is
+// typed.
+func AskHidden(ctx context.Context, msg string) (string, error) {
+	return Ask(ctx, Prompt{Kind: PromptHidden, Message: msg})
+}
+
+// Choose asks for one of choices and returns its index.
+func Choose(ctx context.Context, msg string, choices []string) (int, error) {
+	a, err := Ask(ctx, Prompt{Kind: PromptChoice, Message: msg, Choices: choices})
+	if err != nil {
+		return -1, err
+	}
+	n, err := strconv.Atoi(a)
+	if err != nil || n < 1 || n > len(choices) {
+		return -1, fmt.Errorf("invalid choice '%s'", a)
+	}
+	return n - 1, nil
+}
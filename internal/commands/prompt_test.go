@@ -0,0 +1,71 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPromptParse(t *testing.T) {
+	confirm := Prompt{Kind: PromptConfirm}
+	choice := Prompt{Kind: PromptChoice, Choices: []string{"home", "Office", "home"}}
+	tests := []struct {
+		p      Prompt
+		answer string
+		want   string
+		err    string
+	}{
+		{confirm, "y", "y", ""},
+		{confirm, " YES ", "y", ""},
+		{confirm, "no", "n", ""},
+		{confirm, "", "n", ""},
+		{confirm, "maybe", "", "answer y or n"},
+		{choice, "2", "2", ""},
+		{choice, "office", "2", ""},
+		{choice, "home", "1", ""},
+		{choice, "3", "3", ""},
+		{choice, "0", "", "choose 1-3"},
+		{choice, "4", "", "choose 1-3"},
+		{choice, "attic", "", "choose 1-3"},
+		{Prompt{Kind: PromptText}, "  as typed ", "  as typed ", ""},
+		{Prompt{Kind: PromptHidden}, "", "", ""},
+	}
+	for _, tt := range tests {
+		got, err := tt.p.Parse(tt.answer)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Parse(%q) for kind %d: error %v, want %q", tt.answer, tt.p.Kind, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) for kind %d = %q, %v; want %q", tt.answer, tt.p.Kind, got, err, tt.want)
+		}
+	}
+}
+
+// TestAskNoPrompt checks that with nobody to ask, as in a script, the
+// helpers return ErrNoPrompt.
+func TestAskNoPrompt(t *testing.T) {
+	ctx := WithEnv(context.Background(), &Env{})
+	if _, err := AskHidden(ctx, "Password:"); !errors.Is(err, ErrNoPrompt) {
+		t.Errorf("AskHidden: %v", err)
+	}
+	if ok, err := Confirm(ctx, "Sure?"); ok || !errors.Is(err, ErrNoPrompt) {
+		t.Errorf("Confirm: %v, %v", ok, err)
+	}
+	if i, err := Choose(ctx, "Which?", []string{"a"}); i != -1 || !errors.Is(err, ErrNoPrompt) {
+		t.Errorf("Choose: %d, %v", i, err)
+	}
+}
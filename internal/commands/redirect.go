@@ -0,0 +1,124 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Redirect writes data to path for a `>` redirection, or appends it for
+// `>>`, and returns what to show for it. The target is checked and the
+// change journaled like any file a command changes, and a dry run only
+// says what would be written. Output sent to the null device is dropped.
+func Redirect(ctx context.Context, path, data string, appendMode bool) (string, error) {
+	if isDevNull(path) {
+		return "", nil
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendMode {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	if data != "" && !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+	write := func() error {
+		f, err := os.OpenFile(path, flags, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = f.WriteString(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+	op := beginOp(ctx)
+	var err error
+	if appendMode {
+		err = op.appendTo(path, write)
+	} else {
+		err = op.overwrite(path, write)
+	}
+	if err != nil {
+		return "", err
+	}
+	return op.done(nil, false).Output, nil
+}
+
+// WriteFile creates or replaces the file at path with data, for
+// commands outside this package such as `alias export`. Like Redirect it
+// checks path against the policy and journals the change, and a dry run
+// only says what would be written; a file it replaces goes to the
+// trash. A "> file" confirm rule for path applies as it does to a
+// redirection. msg is the output once the file is written.
+func WriteFile(ctx context.Context, path string, data []byte, msg string) (string, error) {
+	path, err := expandPath(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if err := confirmWrite(ctx, path); err != nil {
+		return "", err
+	}
+	op := beginOp(ctx)
+	err = op.write(path, func() error {
+		return os.WriteFile(path, data, 0644)
+	})
+	if err != nil {
+		return "", err
+	}
+	return op.done(op.log(nil, "%s", msg), false).Output, nil
+}
+
+// confirmWrite asks before writing path when a "> file" confirm rule
+// covers it and the line has not been confirmed. With nobody to ask the
+// write is refused.
+func confirmWrite(ctx context.Context, path string) error {
+	env := EnvOf(ctx)
+	if env.Policy == nil || env.Confirmed || env.DryRun {
+		return nil
+	}
+	rule, risky := env.Policy.ConfirmRedirect(path, false)
+	if !risky {
+		return nil
+	}
+	ok, err := Confirm(ctx, fmt.Sprintf("%s: writing %s needs confirmation (policy rule '%s'). Write it?", env.Line, path, rule))
+	if errors.Is(err, ErrNoPrompt) {
+		return fmt.Errorf("%s needs confirmation (policy rule '%s'); add --confirm to write it", path, rule)
+	}
+	if err != nil || !ok {
+		return ErrPromptCancelled
+	}
+	return nil
+}
+
+// ReadFile reads the file at path, relative to the command's working
+// directory, if the sandbox allows it.
+func ReadFile(ctx context.Context, path string) ([]byte, error) {
+	path, err := expandPath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func isDevNull(path string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Base(path), "NUL")
+	}
+	return filepath.Clean(path) == os.DevNull
+}
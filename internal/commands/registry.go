@@ -0,0 +1,236 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrSessionClosed is the cause of the cancellation a command sees when
+// its session closes rather than the user stopping it. Commands whose
+// state outlives a session, like a focus session, then leave it alone.
+var ErrSessionClosed = errors.New("session closed")
+
+// Command describes a verb the engine can dispatch. Commands register
+// themselves from an init() in the file that implements them, so adding a
+// command means touching a single place.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Subcommands []string
+	Usage       string
+	Summary     string
+	Examples    []string
+
+	// DryRun commands make their file changes through a fileOp and accept
+	// --dry-run; the engine removes the flag and sets Env.DryRun instead.
+	DryRun bool
+
+	// Async commands are started with Start in a goroutine when the caller
+	// has a message channel; Pending is printed as the immediate reply.
+	// Start must return once ctx is cancelled and must not write to ch
+	// after returning; a returned error is shown as the final line and
+	// marks the command as failed. Without a channel the engine runs Start
+	// in the foreground and collects what it writes.
+	Async   bool
+	Pending string
+	Start   func(ctx context.Context, args []string, ch chan string) error
+
+	// Run and Pipe, like Start, get a context carrying the Env to run in.
+	// An async command may set Run too; it is then used instead of Start
+	// whenever the command runs in the foreground, as in a pipeline, a
+	// script or $(...), and should return just the results, one per line.
+	Run func(ctx context.Context, args []string) Result
+
+	// Pipe, if set, is used instead of Run when output from a previous
+	// pipeline stage is fed into the command.
+	Pipe func(ctx context.Context, args []string, stdin string) Result
+
+	// Flags lists the options the command takes, for Tab completion.
+	// Complete, if set, offers values for the argument that follows args,
+	// such as the IDs of existing items; subcommands, flags and file
+	// paths are completed without it.
+	Flags    []string
+	Complete func(ctx context.Context, args []string) []Candidate
+
+	// Secret, if set, returns the indexes in args of values such as
+	// passwords, which are replaced before the line is saved to the
+	// history or the audit trail.
+	Secret func(args []string) []int
+}
+
+// Candidate is a value offered by Tab completion. Note, if set, is shown
+// beside it, for example the text of a goal next to its ID.
+type Candidate struct {
+	Text string
+	Note string
+}
+
+// Words makes candidates without notes.
+func Words(words ...string) []Candidate {
+	out := make([]Candidate, len(words))
+	for i, w := range words {
+		out[i] = Candidate{Text: w}
+	}
+	return out
+}
+
+// Names returns the primary name followed by all aliases.
+func (c *Command) Names() []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+type Registry struct {
+	mu     sync.RWMutex
+	cmds   []*Command
+	byName map[string]*Command
+}
+
+func NewRegistry() *Registry {
+	return &Registry{byName: map[string]*Command{}}
+}
+
+// Default holds every command registered from this package.
+var Default = NewRegistry()
+
+// Register adds c to the default registry. It panics on a name clash since
+// that can only be a programming error.
+func Register(c Command) {
+	if err := Default.Register(c); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Registry) Register(c Command) error {
+	if c.Name == "" {
+		return fmt.Errorf("register: command without a name")
+	}
+	if c.Run == nil && c.Start == nil {
+		return fmt.Errorf("register: %s: no handler", c.Name)
+	}
+	if c.Async && c.Start == nil {
+		return fmt.Errorf("register: %s: async command without Start", c.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cmd := &c
+	for _, n := range cmd.Names() {
+		key := strings.ToLower(n)
+		if prev, ok := r.byName[key]; ok {
+			return fmt.Errorf("register: %s: name %q already used by %s", c.Name, n, prev.Name)
+		}
+	}
+	for _, n := range cmd.Names() {
+		r.byName[strings.ToLower(n)] = cmd
+	}
+	r.cmds = append(r.cmds, cmd)
+	return nil
+}
+
+func (r *Registry) Lookup(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.byName[strings.ToLower(name)]
+	return c, ok
+}
+
+// Commands returns all registered commands sorted by name.
+func (r *Registry) Commands() []*Command {
+	r.mu.RLock()
+	out := append([]*Command(nil), r.cmds...)
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Clone returns a registry with the same commands that can be extended
+// without affecting r.
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := NewRegistry()
+	n.cmds = append(n.cmds, r.cmds...)
+	for k, v := range r.byName {
+		n.byName[k] = v
+	}
+	return n
+}
+
+// HelpText renders the command overview shown by `help`.
+func (r *Registry) HelpText() string {
+	sb := &strings.Builder{}
+	sb.WriteString("\t\t\t\t0xRootShell\nCommands:\n")
+	for _, c := range r.Commands() {
+		usage := c.Usage
+		if usage == "" {
+			usage = c.Name
+		}
+		line := c.Summary
+		if len(c.Aliases) > 0 {
+			line += " (aliases: " + strings.Join(c.Aliases, ", ") + ")"
+		}
+		if c.Async {
+			line += " [background]"
+		}
+		if len(usage) > 34 {
+			fmt.Fprintf(sb, "  %s\n  %-34s %s\n", usage, "", line)
+			continue
+		}
+		fmt.Fprintf(sb, "  %-34s %s\n", usage, line)
+	}
+	sb.WriteString("\nType 'help <command>' for details.")
+	return sb.String()
+}
+
+// CommandHelp renders the detailed help for a single command.
+func (r *Registry) CommandHelp(name string) string {
+	c, ok := r.Lookup(name)
+	if !ok {
+		return fmt.Sprintf("help: no such command '%s'", name)
+	}
+	sb := &strings.Builder{}
+	usage := c.Usage
+	if usage == "" {
+		usage = c.Name
+	}
+	fmt.Fprintf(sb, "%s\n  %s\n", usage, c.Summary)
+	if len(c.Aliases) > 0 {
+		fmt.Fprintf(sb, "Aliases: %s\n", strings.Join(c.Aliases, ", "))
+	}
+	if len(c.Subcommands) > 0 {
+		fmt.Fprintf(sb, "Subcommands: %s\n", strings.Join(c.Subcommands, ", "))
+	}
+	if len(c.Flags) > 0 {
+		fmt.Fprintf(sb, "Flags: %s\n", strings.Join(c.Flags, ", "))
+	}
+	if c.Async {
+		sb.WriteString("Runs in the background; output appears when ready.\n")
+	}
+	if c.Pipe != nil {
+		sb.WriteString("Accepts piped input.\n")
+	}
+	if c.DryRun {
+		sb.WriteString("Accepts --dry-run to list the changes without making them.\n")
+	}
+	if len(c.Examples) > 0 {
+		sb.WriteString("Examples:\n")
+		for _, ex := range c.Examples {
+			fmt.Fprintf(sb, "  %s\n", ex)
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
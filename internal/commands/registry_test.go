@@ -0,0 +1,117 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func noop(ctx context.Context, args []string) Result { return OK("") }
+
+func TestRegisterClashes(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(Command{Name: "copy", Aliases: []string{"cp"}, Run: noop}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		c   Command
+		err string
+	}{
+		{Command{Name: "copy", Run: noop}, `register: copy: name "copy" already used by copy`},
+		{Command{Name: "COPY", Run: noop}, `name "COPY" already used by copy`},
+		{Command{Name: "cp", Run: noop}, `register: cp: name "cp" already used by copy`},
+		{Command{Name: "dup", Aliases: []string{"Cp"}, Run: noop}, `register: dup: name "Cp" already used by copy`},
+		{Command{Name: "dup", Aliases: []string{"d", "copy"}, Run: noop}, `name "copy" already used by copy`},
+		{Command{Run: noop}, "command without a name"},
+		{Command{Name: "idle"}, "register: idle: no handler"},
+		{Command{Name: "bg", Async: true, Run: noop}, "register: bg: async command without Start"},
+	} {
+		err := r.Register(tc.c)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("register %s %v: error %v, want %q", tc.c.Name, tc.c.Aliases, err, tc.err)
+		}
+	}
+	// A refused command leaves none of its names behind.
+	if _, ok := r.Lookup("d"); ok {
+		t.Error("alias d of a refused command was registered")
+	}
+	if n := len(r.Commands()); n != 1 {
+		t.Errorf("%d commands registered, want 1", n)
+	}
+	if c, ok := r.Lookup("CP"); !ok || c.Name != "copy" {
+		t.Errorf("lookup CP: %v %v", c, ok)
+	}
+
+	// A clone is extended without affecting the original.
+	cl := r.Clone()
+	if err := cl.Register(Command{Name: "dup", Run: noop}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cl.Register(Command{Name: "cp", Run: noop}); err == nil {
+		t.Error("clone accepted a name the original uses")
+	}
+	if _, ok := r.Lookup("dup"); ok {
+		t.Error("registering in a clone changed the original")
+	}
+}
+
+func TestHelp(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Command{
+		Name:        "trash",
+		Aliases:     []string{"bin"},
+		Subcommands: []string{"list", "restore", "empty"},
+		Flags:       []string{"--older"},
+		Usage:       "trash [list | restore <name> | empty]",
+		Summary:     "Manage the trash",
+		Examples:    []string{"trash list", "trash empty --older 30d"},
+		DryRun:      true,
+		Run:         noop,
+	})
+	r.Register(Command{Name: "tail", Summary: "Follow a file", Async: true, Start: func(ctx context.Context, args []string, ch chan string) error { return nil }})
+
+	want := `trash [list | restore <name> | empty]
+  Manage the trash
+Aliases: bin
+Subcommands: list, restore, empty
+Flags: --older
+Accepts --dry-run to list the changes without making them.
+Examples:
+  trash list
+  trash empty --older 30d`
+	for _, name := range []string{"trash", "BIN"} {
+		if got := r.CommandHelp(name); got != want {
+			t.Errorf("help %s:\n%s\nwant:\n%s", name, got, want)
+		}
+	}
+	if got := r.CommandHelp("tail"); got != "tail\n  Follow a file\nRuns in the background; output appears when ready." {
+		t.Errorf("help tail: %q", got)
+	}
+	if got := r.CommandHelp("nope"); got != "help: no such command 'nope'" {
+		t.Errorf("help nope: %q", got)
+	}
+
+	text := r.HelpText()
+	for _, line := range []string{
+		"  tail                               Follow a file [background]\n",
+		"  trash [list | restore <name> | empty]\n" + strings.Repeat(" ", 37) + "Manage the trash (aliases: bin)\n",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("help text lacks %q:\n%s", line, text)
+		}
+	}
+	if strings.Index(text, "tail") > strings.Index(text, "trash") {
+		t.Error("help text is not sorted by name")
+	}
+}
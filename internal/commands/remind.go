@@ -12,6 +12,7 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -30,6 +31,37 @@ type Reminder struct {
 
 const remindersFile = "data/reminders.json"
 
+func init() {
+	Register(Command{
+		Name:        "remind",
+		Subcommands: []string{"add", "list", "rm", "del", "remove", "clear"},
+		Usage:       "remind [add|list|rm|clear] <text> [date]",
+		Summary:     "Save a quick reminder (stored locally)",
+		Examples:    []string{"remind add \"call mom\" 2025-10-21_20:00", "remind list", "remind rm <id>"},
+		Run:         CmdRemind,
+		Complete:    completeRemind,
+	})
+}
+
+// completeRemind offers the IDs of reminders to rm.
+func completeRemind(ctx context.Context, args []string) []Candidate {
+	if len(args) != 1 {
+		return nil
+	}
+	if sub := strings.ToLower(args[0]); sub != "rm" && sub != "del" && sub != "remove" {
+		return nil
+	}
+	rem, err := loadReminders()
+	if err != nil {
+		return nil
+	}
+	out := make([]Candidate, len(rem))
+	for i, r := range rem {
+		out[i] = Candidate{Text: r.ID, Note: r.Text}
+	}
+	return out
+}
+
 func loadReminders() ([]Reminder, error) {
 	path := remindersFile
 	dir := filepath.Dir(path)
@@ -50,6 +82,30 @@ func loadReminders() ([]Reminder, error) {
 	return r, nil
 }
 
+// remindOverdue is how long after it is due NextReminder still returns
+// a reminder, so one that was missed is shown for a while and then gives
+// way to the next. It stays in `remind list` until removed.
+const remindOverdue = time.Hour
+
+// NextReminder returns the reminder with the earliest due time, leaving
+// out those due more than remindOverdue before now, if any has one.
+func NextReminder(now time.Time) (Reminder, bool) {
+	rs, err := loadReminders()
+	if err != nil {
+		return Reminder{}, false
+	}
+	var next Reminder
+	for _, r := range rs {
+		if r.Due.IsZero() || now.Sub(r.Due) > remindOverdue {
+			continue
+		}
+		if next.Due.IsZero() || r.Due.Before(next.Due) {
+			next = r
+		}
+	}
+	return next, !next.Due.IsZero()
+}
+
 func saveReminders(r []Reminder) error {
 	path := remindersFile
 	dir := filepath.Dir(path)
@@ -83,7 +139,7 @@ func parseOptionalTime(tokens []string) (time.Time, bool) {
 	return time.Time{}, false
 }
 
-func CmdRemind(args []string) string {
+func CmdRemind(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
 		return remindList()
 	}
@@ -91,7 +147,7 @@ func CmdRemind(args []string) string {
 	switch sub {
 	case "add":
 		if len(args) < 2 {
-			return "remind add: expected text. Example: `remind add \"call mom\" 2025-10-21 20:00`"
+			return Fail("remind add: expected text. Example: `remind add \"call mom\" 2025-10-21 20:00`")
 		}
 		toks := args[1:]
 		due, consumed := parseOptionalTime(toks)
@@ -104,7 +160,7 @@ func CmdRemind(args []string) string {
 		return remindList()
 	case "rm", "del", "remove":
 		if len(args) < 2 {
-			return "remind rm: expected reminder ID. Use `remind list` to see IDs."
+			return Fail("remind rm: expected reminder ID. Use `remind list` to see IDs.")
 		}
 		return remindRemove(args[1])
 	case "clear":
@@ -120,13 +176,13 @@ func CmdRemind(args []string) string {
 	}
 }
 
-func remindAdd(text string, due time.Time) string {
+func remindAdd(text string, due time.Time) Result {
 	if strings.TrimSpace(text) == "" {
-		return "remind add: cannot add empty reminder"
+		return Fail("remind add: cannot add empty reminder")
 	}
 	rem, err := loadReminders()
 	if err != nil {
-		return "remind add: load error: " + err.Error()
+		return Fail("remind add: load error: " + err.Error())
 	}
 	id := strconv.FormatInt(time.Now().UTC().UnixNano(), 10)
 	r := Reminder{
@@ -139,21 +195,21 @@ func remindAdd(text string, due time.Time) string {
 	}
 	rem = append(rem, r)
 	if err := saveReminders(rem); err != nil {
-		return "remind add: save error: " + err.Error()
+		return Fail("remind add: save error: " + err.Error())
 	}
 	if !due.IsZero() {
-		return fmt.Sprintf("Reminder saved: %s (due %s)", text, r.Due.Format("2006-01-02 15:04"))
+		return OK(fmt.Sprintf("Reminder saved: %s (due %s)", text, r.Due.Format("2006-01-02 15:04")))
 	}
-	return fmt.Sprintf("Reminder saved: %s", text)
+	return OK(fmt.Sprintf("Reminder saved: %s", text))
 }
 
-func remindList() string {
+func remindList() Result {
 	rem, err := loadReminders()
 	if err != nil {
-		return "remind list: load error: " + err.Error()
+		return Fail("remind list: load error: " + err.Error())
 	}
 	if len(rem) == 0 {
-		return "No reminders."
+		return OK("No reminders.")
 	}
 	for i := 0; i < len(rem)-1; i++ {
 		for j := i + 1; j < len(rem); j++ {
@@ -174,13 +230,13 @@ func remindList() string {
 			sb.WriteString(fmt.Sprintf("%s    [%s] %s\n", r.ID, r.Due.Format("2006-01-02 15:04"), r.Text))
 		}
 	}
-	return strings.TrimSpace(sb.String())
+	return OK(strings.TrimSpace(sb.String()))
 }
 
-func remindRemove(id string) string {
+func remindRemove(id string) Result {
 	rem, err := loadReminders()
 	if err != nil {
-		return "remind rm: load error: " + err.Error()
+		return Fail("remind rm: load error: " + err.Error())
 	}
 	newRem := []Reminder{}
 	found := false
@@ -192,17 +248,17 @@ func remindRemove(id string) string {
 		newRem = append(newRem, r)
 	}
 	if !found {
-		return "remind rm: id not found"
+		return Fail("remind rm: id not found")
 	}
 	if err := saveReminders(newRem); err != nil {
-		return "remind rm: save error: " + err.Error()
+		return Fail("remind rm: save error: " + err.Error())
 	}
-	return "Reminder removed: " + id
+	return OK("Reminder removed: " + id)
 }
 
-func remindClear() string {
+func remindClear() Result {
 	if err := saveReminders([]Reminder{}); err != nil {
-		return "remind clear: error: " + err.Error()
+		return Fail("remind clear: error: " + err.Error())
 	}
-	return "All reminders cleared."
+	return OK("All reminders cleared.")
 }
@@ -0,0 +1,57 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextReminder(t *testing.T) {
+	t.Chdir(t.TempDir())
+	now := time.Date(2025, 10, 21, 12, 0, 0, 0, time.UTC)
+	if _, ok := NextReminder(now); ok {
+		t.Fatal("a reminder without any saved")
+	}
+	rs := []Reminder{
+		{ID: "1", Text: "no date"},
+		{ID: "2", Text: "tomorrow", Due: now.Add(24 * time.Hour)},
+		{ID: "3", Text: "missed", Due: now.Add(-2 * time.Hour)},
+		{ID: "4", Text: "just missed", Due: now.Add(-10 * time.Minute)},
+		{ID: "5", Text: "soon", Due: now.Add(time.Hour)},
+	}
+	if err := saveReminders(rs); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		at   time.Time
+		want string
+	}{
+		{now.Add(-3 * time.Hour), "missed"},
+		{now, "just missed"},
+		{now.Add(50 * time.Minute), "just missed"},
+		{now.Add(51 * time.Minute), "soon"},
+		{now.Add(2 * time.Hour), "soon"},
+		{now.Add(2*time.Hour + time.Second), "tomorrow"},
+	} {
+		r, ok := NextReminder(tc.at)
+		if !ok || r.Text != tc.want {
+			t.Errorf("at %s: got %q (%v), want %q", tc.at.Format("15:04:05"), r.Text, ok, tc.want)
+		}
+	}
+	if r, ok := NextReminder(now.Add(26 * time.Hour)); ok {
+		t.Errorf("all overdue: got %q", r.Text)
+	}
+	if rem, _ := loadReminders(); len(rem) != len(rs) {
+		t.Errorf("expired reminders were removed: %d left", len(rem))
+	}
+}
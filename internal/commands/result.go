@@ -0,0 +1,57 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Result is what every command returns: the text to show, and whether it
+// succeeded. Code follows shell conventions (0 ok, 1 failure, 2 usage or
+// syntax error, 127 unknown command).
+type Result struct {
+	Output string
+	Err    error
+	Code   int
+
+	// Suggest, when set on a failed result, is a corrected command line
+	// the user may want to run instead.
+	Suggest string
+}
+
+func OK(out string) Result {
+	return Result{Output: out}
+}
+
+// Fail returns a failed result; msg is shown to the user as the output.
+func Fail(msg string) Result {
+	return Result{Output: msg, Err: errors.New(msg), Code: 1}
+}
+
+func Failf(format string, a ...any) Result {
+	return Fail(fmt.Sprintf(format, a...))
+}
+
+// Failed reports whether the command did not succeed.
+func (r Result) Failed() bool {
+	return r.Code != 0
+}
+
+// okIf returns out as a success, or as a failure when failed is set. It is
+// used by commands that act on several targets and report per target.
+func okIf(out string, failed bool) Result {
+	if failed {
+		return Fail(out)
+	}
+	return OK(out)
+}
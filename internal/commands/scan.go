@@ -23,67 +23,82 @@ import (
 	"time"
 )
 
-func StartScan(args []string, ch chan string) {
+func init() {
+	Register(Command{
+		Name:        "scan",
+		Subcommands: []string{"system", "quick", "full"},
+		Usage:       "scan [system] [quick|full]",
+		Summary:     "Start Windows Defender scan",
+		Examples:    []string{"scan", "scan system full"},
+		Async:       true,
+		Pending:     "Scan started... results will appear below.",
+		Start:       StartScan,
+	})
+}
+
+func StartScan(ctx context.Context, args []string, ch chan string) error {
 	if runtime.GOOS != "windows" {
-		ch <- "scan: Windows Defender supported only on Windows."
-		return
+		return errors.New("scan: Windows Defender supported only on Windows.")
 	}
 
 	scanType, mpScanType, ok := parseScanArgs(args)
 	if !ok {
-		ch <- "scan: unrecognized target. Use `scan system` or `scan system full`."
-		return
+		return errors.New("scan: unrecognized target. Use `scan system` or `scan system full`.")
 	}
 
 	ch <- fmt.Sprintf("Starting Windows Defender %s...", scanType)
 
 	ctxTimeout := 15 * time.Minute
-	if err := runDefenderPowershellScanStream(scanType, ch, ctxTimeout); err == nil {
+	if err := runDefenderPowershellScanStream(ctx, scanType, ch, ctxTimeout); err == nil {
 		ch <- fmt.Sprintf("Windows Defender %s finished.", scanType)
-		return
+		return nil
+	} else if ctx.Err() != nil {
+		return ctx.Err()
 	} else {
 		ch <- fmt.Sprintf("PowerShell scan failed: %v — falling back to MpCmdRun.exe", err)
 	}
 
-	if err := runMpCmdRunScanStream(mpScanType, ch, ctxTimeout); err == nil {
+	if err := runMpCmdRunScanStream(ctx, mpScanType, ch, ctxTimeout); err == nil {
 		ch <- fmt.Sprintf("Windows Defender (MpCmdRun) %s finished.", scanType)
-		return
+		return nil
+	} else if ctx.Err() != nil {
+		return ctx.Err()
 	} else {
 		ch <- fmt.Sprintf("MpCmdRun scan failed: %v", err)
 	}
 
-	ch <- "scan: failed to complete. See messages above for details."
+	return errors.New("scan: failed to complete. See messages above for details.")
 }
 
-func CmdScan(args []string) string {
+func CmdScan(ctx context.Context, args []string) Result {
 	if runtime.GOOS != "windows" {
-		return "scan: Windows Defender supported only on Windows."
+		return Fail("scan: Windows Defender supported only on Windows.")
 	}
 
 	scanType, mpScanType, ok := parseScanArgs(args)
 	if !ok {
-		return "scan: unrecognized target. Use `scan system` or `scan system full`."
+		return Fail("scan: unrecognized target. Use `scan system` or `scan system full`.")
 	}
 
 	out, err := runDefenderPowershellScanBlocking(scanType, 20*time.Minute)
 	if err == nil {
 		trim := strings.TrimSpace(out)
 		if trim == "" {
-			return fmt.Sprintf("Windows Defender %s completed. No output.", scanType)
+			return OK(fmt.Sprintf("Windows Defender %s completed. No output.", scanType))
 		}
-		return fmt.Sprintf("Windows Defender %s completed:\n%s", scanType, trim)
+		return OK(fmt.Sprintf("Windows Defender %s completed:\n%s", scanType, trim))
 	}
 
 	out2, err2 := runMpCmdRunScanBlocking(mpScanType, 20*time.Minute)
 	if err2 == nil {
 		trim := strings.TrimSpace(out2)
 		if trim == "" {
-			return fmt.Sprintf("Windows Defender (MpCmdRun) %s completed. No output.", scanType)
+			return OK(fmt.Sprintf("Windows Defender (MpCmdRun) %s completed. No output.", scanType))
 		}
-		return fmt.Sprintf("Windows Defender (MpCmdRun) %s completed:\n%s", scanType, trim)
+		return OK(fmt.Sprintf("Windows Defender (MpCmdRun) %s completed:\n%s", scanType, trim))
 	}
 
-	return fmt.Sprintf("scan failed.\nPowerShell err: %v\nMpCmdRun err: %v", err, err2)
+	return Failf("scan failed.\nPowerShell err: %v\nMpCmdRun err: %v", err, err2)
 }
 
 func parseScanArgs(args []string) (psScanType string, mpScanType string, ok bool) {
@@ -118,7 +133,7 @@ func parseScanArgs(args []string) (psScanType string, mpScanType string, ok bool
 	}
 }
 
-func runDefenderPowershellScanStream(scanType string, ch chan string, timeout time.Duration) error {
+func runDefenderPowershellScanStream(parent context.Context, scanType string, ch chan string, timeout time.Duration) error {
 	psCmd := fmt.Sprintf(`
 Try {
     Import-Module Defender -ErrorAction SilentlyContinue;
@@ -134,7 +149,7 @@ Try {
     exit 2
 }`, scanType)
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", psCmd)
@@ -161,24 +176,25 @@ Try {
 
 	select {
 	case err := <-waitCh:
-		select {
-		case rerr := <-errCh:
-			if rerr != nil {
-				//non-fatal:continue to return process error if any
-			}
-		default:
-		}
+		<-errCh
+		<-errCh
 		if err != nil {
 			return err
 		}
 		return nil
 	case <-ctx.Done():
 		_ = cmd.Process.Kill()
+		<-waitCh
+		<-errCh
+		<-errCh
+		if parent.Err() != nil {
+			return parent.Err()
+		}
 		return errors.New("powershell scan timed out")
 	}
 }
 
-func runMpCmdRunScanStream(scanType string, ch chan string, timeout time.Duration) error {
+func runMpCmdRunScanStream(parent context.Context, scanType string, ch chan string, timeout time.Duration) error {
 	args := []string{"-Scan", "-ScanType", scanType}
 	tryPaths := []string{
 		"MpCmdRun.exe",
@@ -188,7 +204,10 @@ func runMpCmdRunScanStream(scanType string, ch chan string, timeout time.Duratio
 
 	var lastErr error
 	for _, exe := range tryPaths {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		if parent.Err() != nil {
+			return parent.Err()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
 		cmd := exec.CommandContext(ctx, exe, args...)
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
@@ -218,6 +237,8 @@ func runMpCmdRunScanStream(scanType string, ch chan string, timeout time.Duratio
 
 		select {
 		case err := <-waitCh:
+			<-errCh
+			<-errCh
 			cancel()
 			if err != nil {
 				lastErr = fmt.Errorf("%s: %v", exe, err)
@@ -226,6 +247,9 @@ func runMpCmdRunScanStream(scanType string, ch chan string, timeout time.Duratio
 			return nil
 		case <-ctx.Done():
 			_ = cmd.Process.Kill()
+			<-waitCh
+			<-errCh
+			<-errCh
 			cancel()
 			lastErr = fmt.Errorf("%s: timed out", exe)
 			continue
@@ -12,6 +12,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -20,32 +21,41 @@ import (
 	"time"
 )
 
-func CmdScreenshot(args []string) string {
+func init() {
+	Register(Command{
+		Name:    "screenshot",
+		Usage:   "screenshot",
+		Summary: "Save a screenshot to data/screenshots/",
+		Run:     CmdScreenshot,
+	})
+}
+
+func CmdScreenshot(ctx context.Context, args []string) Result {
 	dir := filepath.Join("data", "screenshots")
 	_ = os.MkdirAll(dir, 0755)
 	fname := filepath.Join(dir, fmt.Sprintf("shot-%d.png", time.Now().Unix()))
 	switch runtime.GOOS {
 	case "linux":
 		if err := exec.Command("gnome-screenshot", "-f", fname).Run(); err == nil {
-			return "Saved screenshot: " + fname
+			return OK("Saved screenshot: " + fname)
 		}
 		if err := exec.Command("scrot", fname).Run(); err == nil {
-			return "Saved screenshot: " + fname
+			return OK("Saved screenshot: " + fname)
 		}
-		return "screenshot: install gnome-screenshot or scrot"
+		return Fail("screenshot: install gnome-screenshot or scrot")
 	case "windows":
 		ps := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms;Add-Type -AssemblyName System.Drawing;$bmp = New-Object System.Drawing.Bitmap([Windows.Forms.SystemInformation]::VirtualScreen.Width,[Windows.Forms.SystemInformation]::VirtualScreen.Height);$g = [System.Drawing.Graphics]::FromImage($bmp);$g.CopyFromScreen([Windows.Forms.SystemInformation]::VirtualScreen.X,[Windows.Forms.SystemInformation]::VirtualScreen.Y,0,0,$bmp.Size);$bmp.Save("%s");`, fname)
 		cmd := exec.Command("powershell", "-NoProfile", "-Command", ps)
 		if err := cmd.Run(); err != nil {
-			return "screenshot: failed: " + err.Error()
+			return Fail("screenshot: failed: " + err.Error())
 		}
-		return "Saved screenshot: " + fname
+		return OK("Saved screenshot: " + fname)
 	case "darwin":
 		if err := exec.Command("screencapture", "-x", fname).Run(); err == nil {
-			return "Saved screenshot: " + fname
+			return OK("Saved screenshot: " + fname)
 		}
-		return "screenshot: failed on macOS"
+		return Fail("screenshot: failed on macOS")
 	default:
-		return "screenshot: unsupported OS"
+		return Fail("screenshot: unsupported OS")
 	}
 }
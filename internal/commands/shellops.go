@@ -21,6 +21,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +29,128 @@ import (
 
 const extCmdTimeout = 6 * time.Second
 
+func init() {
+	Register(Command{
+		Name:     "mkdir",
+		Usage:    "mkdir <dir> [<dir> ...]",
+		Summary:  "Create directories (parents included)",
+		Examples: []string{"mkdir build", "mkdir src/cmd src/internal"},
+		Run:      CmdMkdir,
+		DryRun:   true,
+	})
+	Register(Command{
+		Name:        "create",
+		Subcommands: []string{"folder", "directory", "dir"},
+		Usage:       "create folder <name>",
+		Summary:     "Create a new folder",
+		Examples:    []string{"create folder projects"},
+		Run:         cmdCreate,
+		DryRun:      true,
+	})
+	Register(Command{
+		Name:     "rmdir",
+		Usage:    "rmdir <dir> [--force|-r] [--permanent]",
+		Summary:  "Remove a directory",
+		Examples: []string{"rmdir old", "rmdir build -r"},
+		Run:      CmdRmdir,
+		DryRun:   true,
+		Flags:    []string{"--force", "-r", "--permanent"},
+	})
+	Register(Command{
+		Name:        "remove",
+		Aliases:     []string{"delete"},
+		Subcommands: []string{"folder", "directory", "dir", "file"},
+		Usage:       "remove [folder|file] <name>",
+		Summary:     "Delete a folder or file",
+		Examples:    []string{"remove folder old", "remove notes.txt"},
+		Run:         cmdRemove,
+		DryRun:      true,
+	})
+	Register(Command{
+		Name:     "del",
+		Aliases:  []string{"deletefile"},
+		Usage:    "del [-r|--recursive] [--permanent] <target> ...",
+		Summary:  "Move files (and directories with -r) to the trash",
+		Examples: []string{"del notes.txt", "del *.tmp", "del -r build", "del --permanent secret.txt"},
+		Run:      CmdDel,
+		DryRun:   true,
+		Flags:    []string{"-r", "--recursive", "--permanent"},
+	})
+	Register(Command{
+		Name:    "rm",
+		Usage:   "rm [-r] [--permanent] <target> ...",
+		Summary: "Same as del",
+		Run:     CmdRm,
+		DryRun:  true,
+		Flags:   []string{"-r", "--permanent"},
+	})
+	Register(Command{
+		Name:     "cp",
+		Aliases:  []string{"copy"},
+		Usage:    "cp <src> <dst>",
+		Summary:  "Copy file or folder",
+		Examples: []string{"cp notes.txt backup.txt", "cp a.txt b.txt archive/"},
+		Run:      CmdCp,
+		DryRun:   true,
+	})
+	Register(Command{
+		Name:     "mv",
+		Aliases:  []string{"move"},
+		Usage:    "mv <src> <dst>",
+		Summary:  "Move or rename file/folder",
+		Examples: []string{"mv draft.txt final.txt"},
+		Run:      CmdMv,
+		DryRun:   true,
+	})
+	Register(Command{
+		Name:     "cat",
+		Aliases:  []string{"view", "read", "openfile"},
+		Usage:    "cat <file> [file2 ...]",
+		Summary:  "Display contents of a file",
+		Examples: []string{"cat notes.txt", "tasks | cat > procs.txt"},
+		Run:      CmdCat,
+		Pipe:     CmdCatStdin,
+	})
+	Register(Command{
+		Name:     "grep",
+		Aliases:  []string{"findin", "search-in", "searchinside"},
+		Usage:    "grep [-i] [-n] <pattern> <file> ...",
+		Summary:  "Search inside files or piped input",
+		Examples: []string{"grep -i todo notes.txt", "find invoice | grep 2024"},
+		Run:      CmdGrep,
+		Pipe:     CmdGrepStdin,
+		Flags:    []string{"-i", "-n"},
+	})
+	Register(Command{
+		Name:    "tasks",
+		Aliases: []string{"processes", "tasklist"},
+		Usage:   "tasks",
+		Summary: "Show running processes",
+		Run:     CmdTasklist,
+	})
+	Register(Command{
+		Name:     "kill",
+		Aliases:  []string{"end", "terminate", "stop", "taskkill"},
+		Usage:    "kill <pid|name>",
+		Summary:  "Terminate a process",
+		Examples: []string{"kill 4242", "kill notepad.exe"},
+		Run:      CmdTaskkill,
+		Complete: func(ctx context.Context, args []string) []Candidate {
+			if len(args) > 0 {
+				return nil
+			}
+			return Words(processNames()...)
+		},
+	})
+	Register(Command{
+		Name:    "drives",
+		Aliases: []string{"volumes", "disk", "disks", "get-volume", "wmic"},
+		Usage:   "drives",
+		Summary: "Show connected drives / volumes",
+		Run:     CmdGetVolume,
+	})
+}
+
 func runCommand(cmdName string, args []string, timeout time.Duration) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -44,266 +167,277 @@ func runCommand(cmdName string, args []string, timeout time.Duration) (string, e
 	return out.String(), nil
 }
 
-func expand(p string) string {
-	return expandPath(p)
+func expand(ctx context.Context, p string) (string, error) {
+	return expandPath(ctx, p)
 }
 
-func CmdMkdir(args []string) string {
+func CmdMkdir(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "mkdir: usage: mkdir <dir> [<dir> ...]"
+		return Fail("mkdir: usage: mkdir <dir> [<dir> ...]")
 	}
 	out := []string{}
+	failed := false
+	op := beginOp(ctx)
 	for _, a := range args {
-		p := expand(a)
-		if !filepath.IsAbs(p) {
-			if wd, err := os.Getwd(); err == nil {
-				p = filepath.Join(wd, p)
-			}
+		p, err := expand(ctx, a)
+		if err == nil {
+			err = op.mkdirAll(p)
 		}
-		if err := os.MkdirAll(p, 0755); err != nil {
+		if err != nil {
 			out = append(out, fmt.Sprintf("mkdir: %s: %v", a, err))
+			failed = true
 		} else {
-			out = append(out, fmt.Sprintf("Created %s", p))
+			out = op.log(out, "Created %s", p)
+		}
+	}
+	return op.done(out, failed)
+}
+
+func cmdCreate(ctx context.Context, args []string) Result {
+	if len(args) > 0 {
+		t := strings.ToLower(args[0])
+		if t == "folder" || t == "directory" || t == "dir" {
+			return CmdMkdir(ctx, args[1:])
 		}
 	}
-	return strings.Join(out, "\n")
+	return CmdMkdir(ctx, args)
 }
 
-func CmdRmdir(args []string) string {
+func CmdRmdir(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "rmdir: usage: rmdir <dir> [--force|-r]"
+		return Fail("rmdir: usage: rmdir <dir> [--force|-r] [--permanent]")
 	}
 	force := false
+	op := beginOp(ctx)
 	paths := []string{}
 	for _, a := range args {
 		if a == "-r" || a == "--force" {
 			force = true
 			continue
 		}
+		if a == "--permanent" {
+			op.permanent = true
+			continue
+		}
 		paths = append(paths, a)
 	}
 	out := []string{}
+	failed := false
 	for _, a := range paths {
-		p := expand(a)
-		if !filepath.IsAbs(p) {
-			if wd, err := os.Getwd(); err == nil {
-				p = filepath.Join(wd, p)
-			}
+		p, err := expand(ctx, a)
+		if err != nil {
+			out = append(out, fmt.Sprintf("rmdir: %v", err))
+			failed = true
+			continue
 		}
 		info, err := os.Stat(p)
 		if err != nil {
 			out = append(out, fmt.Sprintf("rmdir: %s: %v", a, err))
+			failed = true
 			continue
 		}
 		if !info.IsDir() {
 			out = append(out, fmt.Sprintf("rmdir: %s: not a directory", a))
+			failed = true
 			continue
 		}
 		if force {
-			if err := os.RemoveAll(p); err != nil {
+			if err := op.remove(p); err != nil {
 				out = append(out, fmt.Sprintf("rmdir: %s: %v", a, err))
+				failed = true
 			} else {
-				out = append(out, fmt.Sprintf("Removed (recursively) %s", p))
+				out = op.log(out, "%s (recursively) %s", op.removed(), p)
 			}
 			continue
 		}
 		f, err := os.Open(p)
 		if err != nil {
 			out = append(out, fmt.Sprintf("rmdir: %s: %v", a, err))
+			failed = true
 			continue
 		}
 		names, _ := f.Readdirnames(1)
 		f.Close()
 		if len(names) > 0 {
 			out = append(out, fmt.Sprintf("rmdir: %s: directory not empty (use -r to remove)", a))
+			failed = true
 			continue
 		}
-		if err := os.Remove(p); err != nil {
+		if err := op.remove(p); err != nil {
 			out = append(out, fmt.Sprintf("rmdir: %s: %v", a, err))
+			failed = true
 		} else {
-			out = append(out, fmt.Sprintf("Removed %s", p))
+			out = op.log(out, "%s %s", op.removed(), p)
 		}
 	}
-	return strings.Join(out, "\n")
+	return op.done(out, failed)
 }
 
-func deleteTargets(targets []string, recursive bool) (string, error) {
+func cmdRemove(ctx context.Context, args []string) Result {
+	if len(args) == 0 {
+		return Fail("remove: usage examples: 'remove folder <name>' or 'remove <file>'")
+	}
+	t := strings.ToLower(args[0])
+	if t == "folder" || t == "directory" || t == "dir" {
+		return CmdRmdir(ctx, args[1:])
+	}
+	if t == "file" {
+		return CmdDel(ctx, args[1:])
+	}
+	return CmdDel(ctx, args)
+}
+
+func deleteTargets(ctx context.Context, targets []string, recursive, permanent bool) (string, bool, error) {
 	if len(targets) == 0 {
-		return "", errors.New("del/rm: expected target(s)")
+		return "", true, errors.New("del/rm: expected target(s)")
 	}
 	out := []string{}
+	failed := false
+	op := beginOp(ctx)
+	op.permanent = permanent
 	for _, t := range targets {
-		if strings.ContainsAny(t, "*?[]") {
-			matches, _ := filepath.Glob(t)
-			if len(matches) == 0 {
-				out = append(out, fmt.Sprintf("No match: %s", t))
-				continue
-			}
-			for _, m := range matches {
-				if fi, err := os.Stat(m); err == nil {
-					if fi.IsDir() {
-						if recursive {
-							if err := os.RemoveAll(m); err != nil {
-								out = append(out, fmt.Sprintf("Failed to remove dir %s: %v", m, err))
-							} else {
-								out = append(out, fmt.Sprintf("Removed dir %s", m))
-							}
-						} else {
-							out = append(out, fmt.Sprintf("Skipping dir %s (use -r to remove)", m))
-						}
-					} else {
-						if err := os.Remove(m); err != nil {
-							out = append(out, fmt.Sprintf("Failed to remove %s: %v", m, err))
-						} else {
-							out = append(out, fmt.Sprintf("Deleted %s", m))
-						}
-					}
-				} else {
-					out = append(out, fmt.Sprintf("Missing: %s", m))
-				}
-			}
+		p, err := expand(ctx, t)
+		if err != nil {
+			out = append(out, fmt.Sprintf("Refused: %v", err))
+			failed = true
 			continue
 		}
-
-		p := expand(t)
-		if !filepath.IsAbs(p) {
-			if wd, err := os.Getwd(); err == nil {
-				p = filepath.Join(wd, p)
-			}
-		}
 		fi, err := os.Stat(p)
 		if err != nil {
 			out = append(out, fmt.Sprintf("Missing: %s", t))
+			failed = true
 			continue
 		}
 		if fi.IsDir() {
 			if recursive {
-				if err := os.RemoveAll(p); err != nil {
+				if err := op.remove(p); err != nil {
 					out = append(out, fmt.Sprintf("Failed to remove dir %s: %v", p, err))
+					failed = true
 				} else {
-					out = append(out, fmt.Sprintf("Removed dir %s", p))
+					out = op.log(out, "%s dir %s", op.removed(), p)
 				}
 			} else {
 				out = append(out, fmt.Sprintf("Skipping dir %s (use -r to remove)", p))
+				failed = true
 			}
 		} else {
-			if err := os.Remove(p); err != nil {
+			if err := op.remove(p); err != nil {
 				out = append(out, fmt.Sprintf("Failed to delete %s: %v", p, err))
+				failed = true
 			} else {
-				out = append(out, fmt.Sprintf("Deleted %s", p))
+				out = op.log(out, "%s %s", op.removed(), p)
 			}
 		}
 	}
-	return strings.Join(out, "\n"), nil
+	res := op.done(out, failed)
+	return res.Output, failed, nil
 }
 
-func CmdDel(args []string) string {
+func CmdDel(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "del: usage: del [-r|--recursive] <target> [<target> ...]"
+		return Fail("del: usage: del [-r|--recursive] [--permanent] <target> [<target> ...]")
 	}
-	recursive := false
+	recursive, permanent := false, false
 	targets := []string{}
 	for _, a := range args {
 		if a == "-r" || a == "--recursive" {
 			recursive = true
 			continue
 		}
+		if a == "--permanent" {
+			permanent = true
+			continue
+		}
 		targets = append(targets, a)
 	}
-	s, err := deleteTargets(targets, recursive)
+	s, failed, err := deleteTargets(ctx, targets, recursive, permanent)
 	if err != nil {
-		return "del: " + err.Error() + "\n" + s
+		return Fail("del: " + err.Error() + "\n" + s)
 	}
-	return s
+	return okIf(s, failed)
 }
-func CmdRm(args []string) string {
+func CmdRm(ctx context.Context, args []string) Result {
 	// rm is alias to del
-	return CmdDel(args)
+	return CmdDel(ctx, args)
 }
 
-func CmdCp(args []string) string {
+func CmdCp(ctx context.Context, args []string) Result {
 	if len(args) < 2 {
-		return "cp: usage: cp <src> <dst>  OR cp <src1> <src2> ... <dstDir>"
+		return Fail("cp: usage: cp <src> <dst>  OR cp <src1> <src2> ... <dstDir>")
 	}
-	dst := expand(args[len(args)-1])
-	if !filepath.IsAbs(dst) {
-		if wd, err := os.Getwd(); err == nil {
-			dst = filepath.Join(wd, dst)
-		}
+	dst, err := expand(ctx, args[len(args)-1])
+	if err != nil {
+		return Fail("cp: " + err.Error())
 	}
 	srcs := args[:len(args)-1]
+	failed := false
 	if len(srcs) > 1 {
 		if info, err := os.Stat(dst); err != nil || !info.IsDir() {
-			return "cp: when copying multiple sources, destination must be an existing directory"
+			return Fail("cp: when copying multiple sources, destination must be an existing directory")
 		}
 	}
 	out := []string{}
+	op := beginOp(ctx)
 	for _, s := range srcs {
-		sp := expand(s)
-		if !filepath.IsAbs(sp) {
-			if wd, err := os.Getwd(); err == nil {
-				sp = filepath.Join(wd, sp)
-			}
+		sp, err := expand(ctx, s)
+		if err != nil {
+			out = append(out, "cp: "+err.Error())
+			failed = true
+			continue
 		}
 		info, err := os.Stat(sp)
 		if err != nil {
 			out = append(out, fmt.Sprintf("cp: %s: %v", s, err))
+			failed = true
 			continue
 		}
 		target := dst
 		if len(srcs) > 1 || (info.IsDir() && (info.IsDir())) {
 			target = filepath.Join(dst, filepath.Base(sp))
 		}
-		if err := copyFileOrDir(sp, target); err != nil {
+		if err := op.copy(sp, target); err != nil {
 			out = append(out, fmt.Sprintf("cp: failed %s -> %s: %v", sp, target, err))
+			failed = true
 		} else {
-			out = append(out, fmt.Sprintf("Copied %s -> %s", sp, target))
+			out = op.log(out, "Copied %s -> %s", sp, target)
 		}
 	}
-	return strings.Join(out, "\n")
+	return op.done(out, failed)
 }
 
-func CmdMv(args []string) string {
+func CmdMv(ctx context.Context, args []string) Result {
 	if len(args) < 2 {
-		return "mv: usage: mv <src> <dst>"
+		return Fail("mv: usage: mv <src> <dst>")
 	}
-	src := expand(args[0])
-	if !filepath.IsAbs(src) {
-		if wd, err := os.Getwd(); err == nil {
-			src = filepath.Join(wd, src)
-		}
-	}
-	dst := expand(args[1])
-	if !filepath.IsAbs(dst) {
-		if wd, err := os.Getwd(); err == nil {
-			dst = filepath.Join(wd, dst)
-		}
+	src, err := expand(ctx, args[0])
+	if err != nil {
+		return Fail("mv: " + err.Error())
 	}
-	if err := os.Rename(src, dst); err == nil {
-		return fmt.Sprintf("Moved %s -> %s", src, dst)
+	dst, err := expand(ctx, args[1])
+	if err != nil {
+		return Fail("mv: " + err.Error())
 	}
-	if err := copyFileOrDir(src, dst); err == nil {
-		_ = os.RemoveAll(src)
-		return fmt.Sprintf("Moved %s -> %s", src, dst)
+	op := beginOp(ctx)
+	dst, err = op.move(src, dst)
+	if err != nil {
+		return op.done([]string{fmt.Sprintf("mv: failed to move %s -> %s: %v", src, dst, err)}, true)
 	}
-	return fmt.Sprintf("mv: failed to move %s -> %s", src, dst)
+	return op.done(op.log(nil, "Moved %s -> %s", src, dst), false)
 }
 
-func CmdCat(args []string) string {
+func CmdCat(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "cat: usage: cat <file> [file2 ...]"
+		return Fail("cat: usage: cat <file> [file2 ...]")
 	}
 	out := &strings.Builder{}
 	for i, a := range args {
-		p := expand(a)
-		if !filepath.IsAbs(p) {
-			if wd, err := os.Getwd(); err == nil {
-				p = filepath.Join(wd, p)
-			}
+		p, err := expand(ctx, a)
+		if err != nil {
+			return Fail("cat: " + err.Error())
 		}
 		f, err := os.Open(p)
 		if err != nil {
-			return fmt.Sprintf("cat: %s: %v", a, err)
+			return Failf("cat: %s: %v", a, err)
 		}
 		if len(args) > 1 {
 			out.WriteString(fmt.Sprintf("=== %s ===\n", a))
@@ -311,22 +445,39 @@ func CmdCat(args []string) string {
 		_, err = io.Copy(out, f)
 		f.Close()
 		if err != nil {
-			return fmt.Sprintf("cat: read error %s: %v", a, err)
+			return Failf("cat: read error %s: %v", a, err)
 		}
 		if i < len(args)-1 {
 			out.WriteString("\n")
 		}
 	}
-	return out.String()
+	return OK(out.String())
 }
 
-func CmdGrep(args []string) string {
-	if len(args) < 2 {
-		return "grep: usage: grep [-i] [-n] <pattern> <file> [file...]"
+// CmdCatStdin echoes piped input; "-" among the arguments stands for it
+// when files are given too.
+func CmdCatStdin(ctx context.Context, args []string, stdin string) Result {
+	if len(args) == 0 {
+		return OK(stdin)
 	}
-	ignoreCase := false
-	showNumber := false
-	toks := []string{}
+	out := &strings.Builder{}
+	failed := false
+	for i, a := range args {
+		if a == "-" {
+			out.WriteString(stdin)
+		} else {
+			r := CmdCat(ctx, []string{a})
+			out.WriteString(r.Output)
+			failed = failed || r.Failed()
+		}
+		if i < len(args)-1 {
+			out.WriteString("\n")
+		}
+	}
+	return okIf(out.String(), failed)
+}
+
+func parseGrepArgs(args []string) (ignoreCase, showNumber bool, toks []string) {
 	for _, t := range args {
 		if t == "-i" {
 			ignoreCase = true
@@ -338,145 +489,231 @@ func CmdGrep(args []string) string {
 		}
 		toks = append(toks, t)
 	}
+	return ignoreCase, showNumber, toks
+}
+
+// grepScan writes matching lines from sc to out. label prefixes each line
+// and is left out for piped input.
+func grepScan(sc *bufio.Scanner, out *strings.Builder, label, pattern string, ignoreCase, showNumber bool) {
+	pat := pattern
+	if ignoreCase {
+		pat = strings.ToLower(pat)
+	}
+	prefix := ""
+	if label != "" {
+		prefix = label + ":"
+	}
+	ln := 0
+	for sc.Scan() {
+		ln++
+		line := sc.Text()
+		hay := line
+		if ignoreCase {
+			hay = strings.ToLower(hay)
+		}
+		if !strings.Contains(hay, pat) {
+			continue
+		}
+		switch {
+		case showNumber:
+			out.WriteString(fmt.Sprintf("%s%d: %s\n", prefix, ln, line))
+		case label != "":
+			out.WriteString(fmt.Sprintf("%s %s\n", prefix, line))
+		default:
+			out.WriteString(line + "\n")
+		}
+	}
+}
+
+func CmdGrep(ctx context.Context, args []string) Result {
+	if len(args) < 2 {
+		return Fail("grep: usage: grep [-i] [-n] <pattern> <file> [file...]")
+	}
+	ignoreCase, showNumber, toks := parseGrepArgs(args)
 	if len(toks) < 2 {
-		return "grep: usage: grep [-i] [-n] <pattern> <file> [file...]"
+		return Fail("grep: usage: grep [-i] [-n] <pattern> <file> [file...]")
 	}
 	pattern := toks[0]
 	files := toks[1:]
 	out := &strings.Builder{}
+	failed := false
 	for _, f := range files {
-		p := expand(f)
-		if !filepath.IsAbs(p) {
-			if wd, err := os.Getwd(); err == nil {
-				p = filepath.Join(wd, p)
-			}
+		p, err := expand(ctx, f)
+		if err != nil {
+			out.WriteString("grep: " + err.Error() + "\n")
+			failed = true
+			continue
 		}
 		file, err := os.Open(p)
 		if err != nil {
 			out.WriteString(fmt.Sprintf("grep: %s: %v\n", f, err))
+			failed = true
 			continue
 		}
-		sc := bufio.NewScanner(file)
-		ln := 0
-		for sc.Scan() {
-			ln++
-			line := sc.Text()
-			hay := line
-			pat := pattern
-			if ignoreCase {
-				hay = strings.ToLower(hay)
-				pat = strings.ToLower(pat)
-			}
-			if strings.Contains(hay, pat) {
-				if showNumber {
-					out.WriteString(fmt.Sprintf("%s:%d: %s\n", f, ln, line))
-				} else {
-					out.WriteString(fmt.Sprintf("%s: %s\n", f, line))
-				}
-			}
-		}
+		grepScan(bufio.NewScanner(file), out, f, pattern, ignoreCase, showNumber)
 		file.Close()
 	}
 	res := strings.TrimSpace(out.String())
 	if res == "" {
-		return "grep: no matches"
+		return Fail("grep: no matches")
 	}
-	return res
+	return okIf(res, failed)
 }
 
-func CmdTasklist(args []string) string {
+// CmdGrepStdin filters piped input. Files named after the pattern take
+// precedence over the input, as with CmdGrep.
+func CmdGrepStdin(ctx context.Context, args []string, stdin string) Result {
+	ignoreCase, showNumber, toks := parseGrepArgs(args)
+	if len(toks) == 0 {
+		return Fail("grep: usage: <command> | grep [-i] [-n] <pattern>")
+	}
+	if len(toks) > 1 {
+		return CmdGrep(ctx, args)
+	}
+	out := &strings.Builder{}
+	grepScan(bufio.NewScanner(strings.NewReader(stdin)), out, "", toks[0], ignoreCase, showNumber)
+	res := strings.TrimRight(out.String(), "\n")
+	if res == "" {
+		return Fail("grep: no matches")
+	}
+	return OK(res)
+}
+
+func CmdTasklist(ctx context.Context, args []string) Result {
 	if isWindows() {
 		out, err := runCommand("tasklist", []string{"/FO", "TABLE"}, extCmdTimeout)
 		if err != nil {
 			out2, err2 := runCommand("tasklist", []string{}, extCmdTimeout)
 			if err2 != nil {
-				return "tasklist: error: " + err.Error() + " | " + err2.Error()
+				return Fail("tasklist: error: " + err.Error() + " | " + err2.Error())
 			}
-			return out2
+			return OK(out2)
 		}
-		return strings.TrimSpace(out)
+		return OK(strings.TrimSpace(out))
 	}
 	out, err := runCommand("ps", []string{"-eo", "pid,comm,%cpu,%mem", "--sort=-%cpu"}, extCmdTimeout)
 	if err != nil {
-		return "tasklist: error: " + err.Error()
+		return Fail("tasklist: error: " + err.Error())
 	}
-	return strings.TrimSpace(out)
+	return OK(strings.TrimSpace(out))
 }
 
-func CmdTaskkill(args []string) string {
+// processNames lists the names of running processes, sorted and without
+// duplicates. It is meant for completion, so it gives up quickly.
+func processNames() []string {
+	var out string
+	var err error
+	if isWindows() {
+		out, err = runCommand("tasklist", []string{"/FO", "CSV", "/NH"}, 2*time.Second)
+	} else {
+		out, err = runCommand("ps", []string{"-eo", "comm="}, 2*time.Second)
+	}
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var names []string
+	for _, l := range strings.Split(out, "\n") {
+		name := strings.TrimSpace(l)
+		if isWindows() {
+			name, _, _ = strings.Cut(name, ",")
+			name = strings.Trim(name, `"`)
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func CmdTaskkill(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "taskkill: usage: taskkill <pid> | taskkill /IM <name> | taskkill <name>"
+		return Fail("taskkill: usage: taskkill <pid> | taskkill /IM <name> | taskkill <name>")
 	}
 	if isWindows() {
 		if args[0] == "/IM" && len(args) > 1 {
 			name := args[1]
 			out, err := runCommand("taskkill", []string{"/IM", name, "/F"}, extCmdTimeout)
 			if err != nil {
-				return "taskkill: " + err.Error() + " | " + out
+				return Fail("taskkill: " + err.Error() + " | " + out)
 			}
-			return strings.TrimSpace(out)
+			return OK(strings.TrimSpace(out))
 		}
 		if pid, err := strconv.Atoi(args[0]); err == nil {
 			out, err := runCommand("taskkill", []string{"/PID", fmt.Sprintf("%d", pid), "/F"}, extCmdTimeout)
 			if err != nil {
-				return "taskkill: " + err.Error() + " | " + out
+				return Fail("taskkill: " + err.Error() + " | " + out)
 			}
-			return strings.TrimSpace(out)
+			return OK(strings.TrimSpace(out))
 		}
 		out, err := runCommand("taskkill", []string{"/IM", args[0], "/F"}, extCmdTimeout)
 		if err != nil {
-			return "taskkill: " + err.Error() + " | " + out
+			return Fail("taskkill: " + err.Error() + " | " + out)
 		}
-		return strings.TrimSpace(out)
+		return OK(strings.TrimSpace(out))
 	}
 	if pid, err := strconv.Atoi(args[0]); err == nil {
 		out, err := runCommand("kill", []string{"-9", fmt.Sprintf("%d", pid)}, extCmdTimeout)
 		if err != nil {
-			return "taskkill: " + err.Error() + " | " + out
+			return Fail("taskkill: " + err.Error() + " | " + out)
 		}
-		return fmt.Sprintf("killed %d", pid)
+		return OK(fmt.Sprintf("killed %d", pid))
 	}
 	out, err := runCommand("pkill", []string{"-f", args[0]}, extCmdTimeout)
 	if err != nil {
 		// pkill returns non-zero if no process matched; return message
-		return "taskkill: " + err.Error() + " | " + out
+		return Fail("taskkill: " + err.Error() + " | " + out)
 	}
-	return strings.TrimSpace(out)
+	return OK(strings.TrimSpace(out))
 }
 
-func CmdGetVolume(args []string) string {
+func CmdGetVolume(ctx context.Context, args []string) Result {
 	if isWindows() {
 		out, err := runCommand("wmic", []string{"logicaldisk", "get", "Caption,FreeSpace,Size,VolumeName"}, extCmdTimeout)
 		if err == nil && strings.TrimSpace(out) != "" {
-			return strings.TrimSpace(out)
+			return OK(strings.TrimSpace(out))
 		}
 		pout, err2 := runCommand("powershell", []string{"-NoProfile", "-Command", "Get-Volume | Select DriveLetter, SizeRemaining, Size | Format-Table -AutoSize"}, extCmdTimeout)
 		if err2 == nil && strings.TrimSpace(pout) != "" {
-			return strings.TrimSpace(pout)
+			return OK(strings.TrimSpace(pout))
 		}
 		if err != nil {
-			return "get-volume: wmic error: " + err.Error()
+			return Fail("get-volume: wmic error: " + err.Error())
 		}
-		return "get-volume: no output"
+		return Fail("get-volume: no output")
 	}
 	o, e := runCommand("df", []string{"-h"}, extCmdTimeout)
 	if e != nil {
-		return "get-volume: df error: " + e.Error()
+		return Fail("get-volume: df error: " + e.Error())
 	}
-	return strings.TrimSpace(o)
+	return OK(strings.TrimSpace(o))
 }
 
 func isWindows() bool {
 	return strings.EqualFold(os.Getenv("OS"), "Windows_NT") || filepath.Separator == '\\'
 }
 
-func expandPath(p string) string {
+// expandPath expands a leading ~ and makes p absolute against the
+// command's working directory. When the policy confines commands to a
+// sandbox, a path outside it is an error and goes to the audit trail.
+func expandPath(ctx context.Context, p string) (string, error) {
 	if p == "" {
-		return p
+		return p, nil
 	}
+	p = absPath(ctx, p)
+	env := EnvOf(ctx)
+	if err := env.Policy.CheckRoots(p); err != nil {
+		env.audit("sandbox", err.Error())
+		return p, err
+	}
+	return p, nil
+}
 
-	p = os.ExpandEnv(p)
-
+// absPath is expandPath without the sandbox check, for paths rootshell
+// chooses itself, such as those in the trash.
+func absPath(ctx context.Context, p string) string {
 	if strings.HasPrefix(p, "~") {
 		if p == "~" {
 			if h, err := os.UserHomeDir(); err == nil && h != "" {
@@ -493,9 +730,7 @@ func expandPath(p string) string {
 	}
 
 	if !filepath.IsAbs(p) {
-		if wd, err := os.Getwd(); err == nil {
-			p = filepath.Join(wd, p)
-		}
+		p = filepath.Join(EnvOf(ctx).Dir, p)
 	}
 
 	p = filepath.Clean(p)
@@ -503,17 +738,30 @@ func expandPath(p string) string {
 	if runtime.GOOS == "windows" {
 		p = filepath.FromSlash(p)
 	}
-
 	return p
 }
 
-func copyFileOrDir(src, dst string) error {
+// expandPaths is expandPath for several paths; it stops at the first
+// one that is refused.
+func expandPaths(ctx context.Context, ps ...string) ([]string, error) {
+	out := make([]string, len(ps))
+	for i, p := range ps {
+		var err error
+		if out[i], err = expandPath(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func copyFileOrDir(ctx context.Context, src, dst string) error {
 	if src == "" || dst == "" {
 		return errors.New("copy: src and dst must be non-empty")
 	}
 
-	src = expandPath(src)
-	dst = expandPath(dst)
+	// The paths are checked against the policy by the caller; the trash
+	// and other internal places are outside any sandbox.
+	src, dst = absPath(ctx, src), absPath(ctx, dst)
 
 	srcInfo, err := os.Lstat(src)
 	if err != nil {
@@ -348,9 +348,9 @@ func (c *Client) SetMiniServer(ctx context.Context, miniURL string) error {
 	return nil
 }
 
-func (c *Client) GetClosestServers(n int) error {
+func (c *Client) GetClosestServers(ctx context.Context, n int) error {
 	if len(c.servers) == 0 {
-		if err := c.GetServers(context.Background()); err != nil {
+		if err := c.GetServers(ctx); err != nil {
 			return err
 		}
 	}
@@ -737,16 +737,38 @@ func formatRate(bps float64, bytes bool) (float64, string) {
 // CmdSpeedtest runs the full NetPulse speedtest implementation and returns all output as a string.
 // args is a slice of arguments like ["run","--simple"] or flags like ["--simple","--timeout","15"].
 // If args is nil or empty, defaults are used (which mimic running the binary without flags).
-func CmdSpeedtest(args []string) string {
+func init() {
+	Register(Command{
+		Name:     "speedtest",
+		Usage:    "speedtest [--simple] [--json] [--no-upload]",
+		Summary:  "Run internet speedtest",
+		Examples: []string{"speedtest", "speedtest --simple"},
+		Async:    true,
+		Pending:  "Running speedtest... results will appear below.",
+		Start:    StartSpeedtest,
+		Flags:    []string{"--simple", "--json", "--no-upload"},
+	})
+}
+
+func StartSpeedtest(ctx context.Context, args []string, ch chan string) error {
+	ch <- "Starting speedtest..."
+	if err := CmdSpeedtestStream(ctx, args, ch); err != nil {
+		return err
+	}
+	ch <- "Speedtest finished."
+	return nil
+}
+
+func CmdSpeedtest(ctx context.Context, args []string) Result {
 	var buf bytes.Buffer
-	if err := runSpeedtest(&buf, args); err != nil {
+	if err := runSpeedtest(ctx, &buf, args); err != nil {
 		out := buf.String()
 		if out != "" {
-			return out + "\nERROR: " + err.Error()
+			return Fail(out + "\nERROR: " + err.Error())
 		}
-		return "speedtest error: " + err.Error()
+		return Fail("speedtest error: " + err.Error())
 	}
-	return buf.String()
+	return OK(buf.String())
 }
 
 var _ansiRe = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
@@ -783,9 +805,9 @@ func (w *chanWriter) Write(p []byte) (int, error) {
 	return n, nil
 }
 
-func CmdSpeedtestStream(args []string, ch chan string) {
+func CmdSpeedtestStream(ctx context.Context, args []string, ch chan string) error {
 	w := &chanWriter{ch: ch}
-	err := runSpeedtest(w, args)
+	err := runSpeedtest(ctx, w, args)
 	w.mu.Lock()
 	rem := w.buf.String()
 	w.mu.Unlock()
@@ -796,13 +818,11 @@ func CmdSpeedtestStream(args []string, ch chan string) {
 		}
 	}
 	if err != nil {
-		select {
-		case ch <- "ERROR: " + err.Error():
-		default:
-		}
+		return errors.New("speedtest error: " + err.Error())
 	}
+	return nil
 }
-func runSpeedtest(out io.Writer, args []string) error {
+func runSpeedtest(parent context.Context, out io.Writer, args []string) error {
 	rand.Seed(time.Now().UnixNano())
 
 	fs := flag.NewFlagSet("netpulse-speedtest", flag.ContinueOnError)
@@ -894,7 +914,7 @@ func runSpeedtest(out io.Writer, args []string) error {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	ctx, cancel := context.WithTimeout(parent, cfg.Timeout)
 	defer cancel()
 
 	if *showBanner {
@@ -911,7 +931,7 @@ func runSpeedtest(out io.Writer, args []string) error {
 		if err := client.GetServers(ctx); err != nil {
 			return fmt.Errorf("cannot fetch server list: %w", err)
 		}
-		if err := client.GetClosestServers(50); err != nil {
+		if err := client.GetClosestServers(ctx, 50); err != nil {
 			return fmt.Errorf("cannot compute closest servers: %w", err)
 		}
 		for _, s := range client.servers {
@@ -930,7 +950,7 @@ func runSpeedtest(out io.Writer, args []string) error {
 		}
 	}
 
-	if err := client.GetClosestServers(5); err != nil {
+	if err := client.GetClosestServers(ctx, 5); err != nil {
 		return fmt.Errorf("cannot select closest servers: %w", err)
 	}
 	if _, err := client.GetBestServer(ctx); err != nil {
@@ -947,7 +967,7 @@ func runSpeedtest(out io.Writer, args []string) error {
 
 	if !*noDownload {
 		fmt.Fprintln(out)
-		dctx, dcancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		dctx, dcancel := context.WithTimeout(parent, cfg.Timeout)
 		defer dcancel()
 		down, err := client.Download(dctx, *single, *bytesFlag, out)
 		if err != nil {
@@ -965,7 +985,7 @@ func runSpeedtest(out io.Writer, args []string) error {
 
 	if !*noUpload {
 		fmt.Fprintln(out)
-		uctx, ucancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		uctx, ucancel := context.WithTimeout(parent, cfg.Timeout)
 		defer ucancel()
 		up, err := client.Upload(uctx, *single, *bytesFlag, out)
 		if err != nil {
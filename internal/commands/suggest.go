@@ -0,0 +1,72 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import "strings"
+
+// Closest returns the candidate nearest to word by edit distance, counting
+// a swap of two adjacent letters as one edit. Short words allow one edit
+// and longer ones two; ok is false when nothing is that close.
+func Closest(word string, candidates []string) (best string, ok bool) {
+	word = strings.ToLower(word)
+	limit := 1
+	if len([]rune(word)) > 4 {
+		limit = 2
+	}
+	bestDist := limit + 1
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		if lc == word {
+			return "", false
+		}
+		if d := editDistance(word, lc); d < bestDist || (d == bestDist && ok && lc < best) {
+			best, bestDist, ok = lc, d, d <= limit
+		}
+	}
+	return best, ok
+}
+
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(t)]
+}
+
+// Names returns every name and alias a command can be invoked by.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, 0, len(r.byName))
+	for n := range r.byName {
+		out = append(out, n)
+	}
+	return out
+}
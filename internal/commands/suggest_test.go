@@ -0,0 +1,67 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import "testing"
+
+func TestClosest(t *testing.T) {
+	for _, tc := range []struct {
+		word       string
+		candidates []string
+		want       string
+		ok         bool
+	}{
+		{"ecoh", []string{"echo", "exit", "cd"}, "echo", true},
+		{"lsit", []string{"list", "rm"}, "list", true},
+		{"histroy", []string{"history", "help"}, "history", true},
+		{"hstry", []string{"history", "help"}, "history", true},
+		{"GERP", []string{"grep", "Grab"}, "grep", true},
+		// Ties go to the first in alphabetical order, whatever the
+		// order of the candidates.
+		{"bat", []string{"hat", "cat", "bar"}, "bar", true},
+		{"bat", []string{"cat", "hat"}, "cat", true},
+		{"remve", []string{"remote", "remove"}, "remove", true},
+		// Short words allow one edit, longer ones two.
+		{"ab", []string{"abcd"}, "", false},
+		{"cdx", []string{"c"}, "", false},
+		{"hsty", []string{"history"}, "", false},
+		{"hxtoy", []string{"history"}, "", false},
+		{"xyzzy", []string{"echo", "exit"}, "", false},
+		{"echo", nil, "", false},
+		// A word that is a candidate needs no correction.
+		{"Echo", []string{"ecoh", "echo"}, "", false},
+	} {
+		got, ok := Closest(tc.word, tc.candidates)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("Closest(%q, %q) = %q, %v; want %q, %v", tc.word, tc.candidates, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"abc", "abc", 0},
+		{"abc", "acb", 1},
+		{"abcd", "badc", 2},
+		{"kitten", "sitting", 3},
+		{"café", "cafe", 1},
+	} {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -13,6 +13,7 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -24,131 +25,151 @@ import (
 	"time"
 )
 
-func CmdSys(args []string) string {
+func init() {
+	Register(Command{
+		Name:        "sys",
+		Subcommands: []string{"status", "perf", "lock", "sleep", "off", "shutdown", "bootlog", "update"},
+		Usage:       "sys <status|perf|lock|sleep|off|bootlog|update>",
+		Summary:     "System status, performance and power",
+		Examples:    []string{"sys status", "sys perf", "sys off --confirm"},
+		Run:         CmdSys,
+	})
+	Register(Command{
+		Name:        "show",
+		Subcommands: []string{"notifications"},
+		Usage:       "show notifications",
+		Summary:     "Show saved notifications",
+		Run: func(ctx context.Context, args []string) Result {
+			if len(args) > 0 && strings.ToLower(args[0]) == "notifications" {
+				return CmdShowNotifications()
+			}
+			return Fail("show: unknown target. Try 'show notifications'")
+		},
+	})
+}
+
+func CmdSys(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "sys: expected 'sys status|lock|sleep|off|bootlog|update'"
+		return Fail("sys: expected subcommand. Try 'sys status' or 'sys perf'")
 	}
 	sub := strings.ToLower(args[0])
 	switch sub {
 	case "status":
 		return CmdSysStatus()
+	case "perf":
+		return CmdSysPerf()
 	case "lock":
 		return SysLock()
 	case "sleep":
 		return SysSleep()
 	case "off", "shutdown":
-		for _, a := range args {
-			if a == "--confirm" || a == "-y" {
-				return SysShutdown()
-			}
-		}
-		return "sys off: destructive action. append --confirm to actually shutdown."
+		return SysShutdown()
 	case "bootlog":
 		return SysBootLog()
 	case "update":
-		return "sys update: use OS update tool (Windows Settings / apt / dnf / etc.)"
+		return OK("sys update: use OS update tool (Windows Settings / apt / dnf / etc.)")
 	default:
-		return "sys: unknown subcommand"
+		return Fail("sys: unknown subcommand")
 	}
 }
 
-func SysLock() string {
+func SysLock() Result {
 	switch runtime.GOOS {
 	case "windows":
 		cmd := exec.Command("rundll32.exe", "user32.dll,LockWorkStation")
 		if err := cmd.Run(); err != nil {
-			return "sys lock error: " + err.Error()
+			return Fail("sys lock error: " + err.Error())
 		}
-		return "Locked workstation."
+		return OK("Locked workstation.")
 	case "darwin":
 		cmd := exec.Command("/System/Library/CoreServices/Menu Extras/User.menu/Contents/Resources/CGSession", "-suspend")
 		if err := cmd.Run(); err != nil {
-			return "sys lock error: " + err.Error()
+			return Fail("sys lock error: " + err.Error())
 		}
-		return "Locked screen."
+		return OK("Locked screen.")
 	default:
 		if err := exec.Command("loginctl", "lock-session").Run(); err == nil {
-			return "Locked session."
+			return OK("Locked session.")
 		}
 		if err := exec.Command("gnome-screensaver-command", "-l").Run(); err == nil {
-			return "Locked screen."
+			return OK("Locked screen.")
 		}
-		return "sys lock: no known lock command available on this Linux system."
+		return Fail("sys lock: no known lock command available on this Linux system.")
 	}
 }
 
-func SysSleep() string {
+func SysSleep() Result {
 	switch runtime.GOOS {
 	case "windows":
 		ps := `Add-Type -AssemblyName System.Windows.Forms; [System.Windows.Forms.Application]::SetSuspendState('Suspend', $false, $false)`
 		cmd := exec.Command("powershell", "-NoProfile", "-Command", ps)
 		if err := cmd.Run(); err != nil {
-			return "sys sleep error: " + err.Error()
+			return Fail("sys sleep error: " + err.Error())
 		}
-		return "System sleep requested."
+		return OK("System sleep requested.")
 	case "darwin":
 		cmd := exec.Command("pmset", "sleepnow")
 		if err := cmd.Run(); err != nil {
-			return "sys sleep error: " + err.Error()
+			return Fail("sys sleep error: " + err.Error())
 		}
-		return "Sleep requested."
+		return OK("Sleep requested.")
 	default:
 		if err := exec.Command("systemctl", "suspend").Run(); err == nil {
-			return "System suspend requested."
+			return OK("System suspend requested.")
 		}
-		return "sys sleep: need systemctl or permission to suspend."
+		return Fail("sys sleep: need systemctl or permission to suspend.")
 	}
 }
 
-func SysShutdown() string {
+func SysShutdown() Result {
 	switch runtime.GOOS {
 	case "windows":
 		cmd := exec.Command("shutdown", "/s", "/t", "0")
 		if err := cmd.Start(); err != nil {
-			return "sys off error: " + err.Error()
+			return Fail("sys off error: " + err.Error())
 		}
-		return "Shutting down..."
+		return OK("Shutting down...")
 	case "darwin":
 		cmd := exec.Command("shutdown", "-h", "now")
 		if err := cmd.Start(); err != nil {
-			return "sys off error: " + err.Error()
+			return Fail("sys off error: " + err.Error())
 		}
-		return "Shutting down..."
+		return OK("Shutting down...")
 	default:
 		cmd := exec.Command("systemctl", "poweroff")
 		if err := cmd.Start(); err != nil {
-			return "sys off error: " + err.Error()
+			return Fail("sys off error: " + err.Error())
 		}
-		return "Shutting down..."
+		return OK("Shutting down...")
 	}
 }
 
-func SysBootLog() string {
+func SysBootLog() Result {
 	switch runtime.GOOS {
 	case "linux":
 		out, err := exec.Command("uptime", "-p").CombinedOutput()
 		if err == nil {
-			return "Uptime: " + strings.TrimSpace(string(out))
+			return OK("Uptime: " + strings.TrimSpace(string(out)))
 		}
-		return "bootlog: " + err.Error()
+		return Fail("bootlog: " + err.Error())
 	case "windows":
 		out, err := exec.Command("powershell", "-NoProfile", "-Command", "([Management.ManagementDateTimeConverter]::ToDateTime((Get-WmiObject -Class Win32_OperatingSystem).LastBootUpTime)).ToString()").CombinedOutput()
 		if err == nil {
-			return "Last boot: " + strings.TrimSpace(string(out))
+			return OK("Last boot: " + strings.TrimSpace(string(out)))
 		}
-		return "bootlog: " + err.Error()
+		return Fail("bootlog: " + err.Error())
 	case "darwin":
 		out, err := exec.Command("sysctl", "-n", "kern.boottime").CombinedOutput()
 		if err == nil {
-			return strings.TrimSpace(string(out))
+			return OK(strings.TrimSpace(string(out)))
 		}
-		return "bootlog: " + err.Error()
+		return Fail("bootlog: " + err.Error())
 	default:
-		return "bootlog: unsupported OS"
+		return Fail("bootlog: unsupported OS")
 	}
 }
 
-func CmdSysPerf() string {
+func CmdSysPerf() Result {
 	ps := `
 $cpu = (Get-Counter '\Processor(_Total)\% Processor Time').CounterSamples.CookedValue
 $proc = Get-Process | Sort-Object -Descending CPU | Select-Object -First 5 | ForEach-Object { "{0, -25} {1,6:N1} CPU {2,8:N1} MB" -f $_.ProcessName, $_.CPU, ($_.WorkingSet/1MB) }
@@ -173,9 +194,9 @@ if ($netstats) { "Network: " + $netstats }
 `
 	out, err := runPowerShell(ps)
 	if err != nil {
-		return fmt.Sprintf("sys perf: failed to query performance counters: %s\n(Ensure PowerShell is available and running on Windows)", err.Error())
+		return Failf("sys perf: failed to query performance counters: %s\n(Ensure PowerShell is available and running on Windows)", err.Error())
 	}
-	return out
+	return OK(out)
 }
 func runPowerShell(script string) (string, error) {
 	cmd := exec.Command("powershell", "-NoProfile", "-Command", script)
@@ -226,14 +247,14 @@ func safeCmdStart(cmd *exec.Cmd) error {
 	return cmd.Start()
 }
 
-func CmdShowNotifications() string {
+func CmdShowNotifications() Result {
 	cmd := exec.Command("cmd", "/C", "start", "ms-actioncenter:")
 	if err := cmd.Start(); err == nil {
-		return "Opened Action Center. Note: Windows does not allow enumerating other apps' notifications programmatically for security reasons. This opens the notification center where you can view them."
+		return OK("Opened Action Center. Note: Windows does not allow enumerating other apps' notifications programmatically for security reasons. This opens the notification center where you can view them.")
 	}
 	_, err := runPowerShell("Start-Process -FilePath 'ms-actioncenter:'")
 	if err == nil {
-		return "Opened Action Center."
+		return OK("Opened Action Center.")
 	}
-	return "Failed to open Action Center. Try opening notifications manually (Win+A)."
+	return Fail("Failed to open Action Center. Try opening notifications manually (Win+A).")
 }
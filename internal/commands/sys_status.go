@@ -17,11 +17,11 @@ import (
 	"time"
 )
 
-func CmdSysStatus() string {
+func CmdSysStatus() Result {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
-	return fmt.Sprintf("System status:\n"+
+	return OK(fmt.Sprintf("System status:\n"+
 		"  OS: %s/%s\n"+
 		"  CPUs: %d\n"+
 		"  Alloc: %d KB\n"+
@@ -34,5 +34,5 @@ func CmdSysStatus() string {
 		m.Alloc/1024,
 		m.Sys/1024,
 		runtime.NumGoroutine(),
-		time.Now().Format(time.RFC1123))
+		time.Now().Format(time.RFC1123)))
 }
@@ -12,27 +12,38 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 )
 
-func ScheduleTimer(args []string, ch chan string) {
-	if ch == nil {
-		return
-	}
+func init() {
+	Register(Command{
+		Name:     "timer",
+		Aliases:  []string{"alarm"},
+		Usage:    "timer <duration|hhmm>",
+		Summary:  "Schedule alarm/timer",
+		Examples: []string{"timer 25m", "alarm 0630"},
+		Async:    true,
+		Pending:  "Timer scheduled.",
+		Start:    ScheduleTimer,
+	})
+}
+
+func ScheduleTimer(ctx context.Context, args []string, ch chan string) error {
 	if len(args) == 0 {
-		ch <- "timer: expected duration like '25m' or time like '0630'"
-		return
+		return errors.New("timer: expected duration like '25m' or time like '0630'")
 	}
 	raw := args[0]
 	if d, err := time.ParseDuration(raw); err == nil {
 		ch <- fmt.Sprintf("Timer set for %s from now.", d.String())
-		time.AfterFunc(d, func() {
+		if sleepCtx(ctx, d) {
 			ch <- fmt.Sprintf("Timer: %s elapsed.", d.String())
-		})
-		return
+		}
+		return nil
 	}
 	s := strings.ReplaceAll(raw, ":", "")
 	if len(s) == 4 {
@@ -44,11 +55,23 @@ func ScheduleTimer(args []string, ch chan string) {
 			target = target.Add(24 * time.Hour)
 		}
 		ch <- fmt.Sprintf("Alarm set for %s", target.Format("2006-01-02 15:04"))
-		duration := target.Sub(now)
-		time.AfterFunc(duration, func() {
+		if sleepCtx(ctx, target.Sub(now)) {
 			ch <- fmt.Sprintf("Alarm: %s reached.", target.Format("2006-01-02 15:04"))
-		})
-		return
+		}
+		return nil
+	}
+	return errors.New("timer: unrecognized format. Try '25m' or '0630'.")
+}
+
+// sleepCtx waits for d and reports whether it elapsed before ctx was
+// cancelled.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
-	ch <- "timer: unrecognized format. Try '25m' or '0630'."
 }
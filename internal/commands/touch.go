@@ -11,6 +11,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,9 +20,65 @@ import (
 	"time"
 )
 
-func CmdTouch(args []string) string {
+func init() {
+	Register(Command{
+		Name:     "touch",
+		Usage:    "touch [-p] [-c] [-t <ts>] [-r <ref>] <file> ...",
+		Summary:  "Create files or update their timestamps",
+		Examples: []string{"touch notes.txt", "touch -p logs/today.log"},
+		Run:      CmdTouch,
+		DryRun:   true,
+		Flags:    []string{"-p", "--parents", "-c", "--no-create", "-t", "-r", "-a", "-m"},
+	})
+	Register(Command{
+		Name:        "new",
+		Subcommands: []string{"file", "document", "folder", "directory", "dir"},
+		Usage:       "new file <name>",
+		Summary:     "Create new file or folder",
+		Examples:    []string{"new file todo.md", "new folder drafts"},
+		Run:         cmdNew,
+		DryRun:      true,
+	})
+	Register(Command{
+		Name:        "save",
+		Subcommands: []string{"file"},
+		Usage:       "save file <name>",
+		Summary:     "Save (create) a file",
+		Run:         cmdSave,
+		DryRun:      true,
+	})
+}
+
+func cmdNew(ctx context.Context, args []string) Result {
 	if len(args) == 0 {
-		return "touch: usage: touch [options] <file>...\nOptions: -p|--parents, -c|--no-create, -t <timestamp>, -r <ref>, -a, -m"
+		return CmdMkdir(ctx, args)
+	}
+	first := strings.ToLower(args[0])
+	if first == "file" || first == "document" {
+		return CmdTouch(ctx, args[1:])
+	}
+	if first == "folder" || first == "directory" || first == "dir" {
+		return CmdMkdir(ctx, args[1:])
+	}
+	if ext := filepath.Ext(args[0]); ext != "" {
+		return CmdTouch(ctx, args)
+	}
+	return CmdMkdir(ctx, args)
+}
+
+func cmdSave(ctx context.Context, args []string) Result {
+	if len(args) > 0 && strings.ToLower(args[0]) == "file" {
+		return CmdTouch(ctx, args[1:])
+	}
+	if len(args) > 0 && (strings.HasPrefix(args[0], ".") || filepath.Ext(args[0]) != "") {
+		return CmdTouch(ctx, args)
+	}
+	return Fail("save: try 'save file <name>'")
+}
+
+func CmdTouch(ctx context.Context, args []string) Result {
+	if len(args) == 0 {
+		return Fail("touch: usage: touch [options] <file>...\nOptions: -p|--parents, -c|--no-create, -t <timestamp>, -r <ref>, -a, -m")
 	}
 
 	createParents := false
@@ -65,7 +122,7 @@ func CmdTouch(args []string) string {
 			ts := args[i+1]
 			parsed, perr := parseTimestamp(ts)
 			if perr != nil {
-				return "touch: invalid timestamp: " + perr.Error()
+				return Fail("touch: invalid timestamp: " + perr.Error())
 			}
 			setTime = parsed
 			hasSetTime = true
@@ -77,7 +134,7 @@ func CmdTouch(args []string) string {
 			if len(parts) == 2 {
 				parsed, perr := parseTimestamp(parts[1])
 				if perr != nil {
-					return "touch: invalid timestamp: " + perr.Error()
+					return Fail("touch: invalid timestamp: " + perr.Error())
 				}
 				setTime = parsed
 				hasSetTime = true
@@ -90,39 +147,40 @@ func CmdTouch(args []string) string {
 	}
 
 	if len(remaining) == 0 {
-		return "touch: no files specified"
+		return Fail("touch: no files specified")
 	}
 
 	if refFile != "" {
-		rp := expandPath(refFile)
-		if !filepath.IsAbs(rp) {
-			if wd, err := os.Getwd(); err == nil {
-				rp = filepath.Join(wd, rp)
-			}
+		rp, err := expandPath(ctx, refFile)
+		if err != nil {
+			return Fail("touch: " + err.Error())
 		}
 		fi, err := os.Stat(rp)
 		if err != nil {
-			return "touch: reference file error: " + err.Error()
+			return Fail("touch: reference file error: " + err.Error())
 		}
 		setTime = fi.ModTime()
 		hasSetTime = true
 	}
 
 	outLines := []string{}
+	failed := false
 	now := time.Now()
+	op := beginOp(ctx)
 
 	for _, f := range remaining {
-		p := expandPath(f)
-		if !filepath.IsAbs(p) {
-			if wd, err := os.Getwd(); err == nil {
-				p = filepath.Join(wd, p)
-			}
+		p, err := expandPath(ctx, f)
+		if err != nil {
+			outLines = append(outLines, "touch: "+err.Error())
+			failed = true
+			continue
 		}
 
 		dir := filepath.Dir(p)
 		if createParents {
-			if err := os.MkdirAll(dir, 0755); err != nil {
+			if err := op.mkdirAll(dir); err != nil {
 				outLines = append(outLines, fmt.Sprintf("%s: failed to create parent dirs: %v", f, err))
+				failed = true
 				continue
 			}
 		} else {
@@ -131,10 +189,12 @@ func CmdTouch(args []string) string {
 					if os.IsNotExist(err) {
 						if noCreate {
 							outLines = append(outLines, fmt.Sprintf("%s: parent directory does not exist", f))
+							failed = true
 							continue
 						}
-						if err := os.MkdirAll(dir, 0755); err != nil {
+						if err := op.mkdirAll(dir); err != nil {
 							outLines = append(outLines, fmt.Sprintf("%s: failed to create parent dirs: %v", f, err))
+							failed = true
 							continue
 						}
 					}
@@ -149,20 +209,31 @@ func CmdTouch(args []string) string {
 					outLines = append(outLines, fmt.Sprintf("%s: does not exist (not created due to --no-create)", f))
 					continue
 				}
-				fd, ferr := os.OpenFile(p, os.O_CREATE|os.O_WRONLY, 0644)
+				ferr := op.write(p, func() error {
+					fd, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY, 0644)
+					if err != nil {
+						return err
+					}
+					return fd.Close()
+				})
 				if ferr != nil {
 					outLines = append(outLines, fmt.Sprintf("%s: create failed: %v", f, ferr))
+					failed = true
+					continue
+				}
+				if op.dry {
 					continue
 				}
-				_ = fd.Close()
 				if hasSetTime {
 					if err := setFileTimes(p, setTime, onlyA, onlyM); err != nil {
 						outLines = append(outLines, fmt.Sprintf("%s: created but time set failed: %v", f, err))
+						failed = true
 						continue
 					}
 				} else {
 					if err := setFileTimes(p, now, onlyA, onlyM); err != nil {
 						outLines = append(outLines, fmt.Sprintf("%s: created but time set failed: %v", f, err))
+						failed = true
 						continue
 					}
 				}
@@ -170,6 +241,11 @@ func CmdTouch(args []string) string {
 				continue
 			}
 			outLines = append(outLines, fmt.Sprintf("%s: stat error: %v", f, err))
+			failed = true
+			continue
+		}
+		if op.dry {
+			op.planned("touch", p)
 			continue
 		}
 
@@ -179,6 +255,7 @@ func CmdTouch(args []string) string {
 		}
 		if err := setFileTimes(p, targetTime, onlyA, onlyM); err != nil {
 			outLines = append(outLines, fmt.Sprintf("%s: update time failed: %v", f, err))
+			failed = true
 			continue
 		}
 		if fi.IsDir() {
@@ -188,7 +265,7 @@ func CmdTouch(args []string) string {
 		}
 	}
 
-	return strings.Join(outLines, "\n")
+	return op.done(outLines, failed)
 }
 
 func parseTimestamp(s string) (time.Time, error) {
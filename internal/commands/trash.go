@@ -0,0 +1,346 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register(Command{
+		Name:        "trash",
+		Subcommands: []string{"list", "restore", "empty"},
+		Usage:       "trash list | restore <name>... | empty [--older 30d]",
+		Summary:     "Browse, restore or purge deleted files",
+		Examples:    []string{"trash list", "trash restore notes.txt", "trash empty --older 30d"},
+		Run:         CmdTrash,
+		Flags:       []string{"--older"},
+		DryRun:      true,
+		Complete: func(ctx context.Context, args []string) []Candidate {
+			if len(args) == 0 || strings.ToLower(args[0]) != "restore" {
+				return nil
+			}
+			items, _ := trashList()
+			out := make([]Candidate, len(items))
+			for i, it := range items {
+				out[i] = Candidate{Text: it.Name, Note: it.Path}
+			}
+			return out
+		},
+	})
+}
+
+// The trash follows the freedesktop.org Trash specification: files/ holds
+// the deleted items and info/ a NAME.trashinfo file for each with its
+// original path and deletion date. On Linux it is the user's own trash,
+// so items show up in the desktop's trash can too; elsewhere rootshell
+// keeps one with the same layout under data/trash. Items from other
+// filesystems are copied into the home trash rather than using a per-disk
+// .Trash directory.
+const trashInfoDate = "2006-01-02T15:04:05"
+
+type trashItem struct {
+	Name    string // entry name under files/
+	Path    string // where it was deleted from
+	Deleted time.Time
+}
+
+func trashDir() string {
+	if runtime.GOOS == "linux" {
+		if d := os.Getenv("XDG_DATA_HOME"); d != "" {
+			return filepath.Join(d, "Trash")
+		}
+		if h, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(h, ".local", "share", "Trash")
+		}
+	}
+	d, err := filepath.Abs(filepath.Join("data", "trash"))
+	if err != nil {
+		return filepath.Join("data", "trash")
+	}
+	return d
+}
+
+// trashPut moves path into the trash and returns where it now lives.
+func trashPut(ctx context.Context, path string) (string, error) {
+	dir := trashDir()
+	files, info := filepath.Join(dir, "files"), filepath.Join(dir, "info")
+	if err := os.MkdirAll(files, 0700); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(info, 0700); err != nil {
+		return "", err
+	}
+	// The info file is created first and exclusively, which reserves the
+	// name as the specification asks.
+	base := filepath.Base(path)
+	var name string
+	var f *os.File
+	for n := 1; ; n++ {
+		name = base
+		if n > 1 {
+			ext := filepath.Ext(base)
+			name = fmt.Sprintf("%s.%d%s", strings.TrimSuffix(base, ext), n, ext)
+		}
+		var err error
+		f, err = os.OpenFile(filepath.Join(info, name+".trashinfo"), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+	}
+	u := url.URL{Path: filepath.ToSlash(path)}
+	_, err := fmt.Fprintf(f, "[Trash Info]\nPath=%s\nDeletionDate=%s\n", u.EscapedPath(), time.Now().Format(trashInfoDate))
+	f.Close()
+	dst := filepath.Join(files, name)
+	if err == nil {
+		err = moveFile(ctx, path, dst)
+	}
+	if err != nil {
+		_ = os.Remove(filepath.Join(info, name+".trashinfo"))
+		return "", err
+	}
+	return dst, nil
+}
+
+// trashForget drops the info file of an item that has been taken out of
+// the trash by other means, such as undo.
+func trashForget(trashed string) {
+	if filepath.Base(filepath.Dir(trashed)) != "files" {
+		return
+	}
+	_ = os.Remove(filepath.Join(filepath.Dir(filepath.Dir(trashed)), "info", filepath.Base(trashed)+".trashinfo"))
+}
+
+func trashList() ([]trashItem, error) {
+	dir := filepath.Join(trashDir(), "info")
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []trashItem
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".trashinfo") {
+			continue
+		}
+		it, err := readTrashInfo(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		out = append(out, it)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Deleted.After(out[j].Deleted) })
+	return out, nil
+}
+
+func readTrashInfo(file string) (trashItem, error) {
+	it := trashItem{Name: strings.TrimSuffix(filepath.Base(file), ".trashinfo")}
+	f, err := os.Open(file)
+	if err != nil {
+		return it, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "Path":
+			if p, err := url.PathUnescape(v); err == nil {
+				it.Path = filepath.FromSlash(p)
+			}
+		case "DeletionDate":
+			it.Deleted, _ = time.ParseInLocation(trashInfoDate, v, time.Local)
+		}
+	}
+	if it.Path == "" {
+		return it, fmt.Errorf("%s: no Path", file)
+	}
+	return it, sc.Err()
+}
+
+// trashRemove deletes an item and its info file for good.
+func trashRemove(it trashItem) error {
+	dir := trashDir()
+	if err := os.RemoveAll(filepath.Join(dir, "files", it.Name)); err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(dir, "info", it.Name+".trashinfo"))
+}
+
+func CmdTrash(ctx context.Context, args []string) Result {
+	if len(args) == 0 {
+		return trashShow()
+	}
+	switch strings.ToLower(args[0]) {
+	case "list", "ls":
+		return trashShow()
+	case "restore":
+		if len(args) < 2 {
+			return Fail("trash restore: usage: trash restore <name>...")
+		}
+		return trashRestore(ctx, args[1:])
+	case "empty", "purge":
+		var older time.Duration
+		for i := 1; i < len(args); i++ {
+			if args[i] == "--older" && i+1 < len(args) {
+				d, err := ParseAge(args[i+1])
+				if err != nil {
+					return Fail("trash empty: " + err.Error())
+				}
+				older = d
+				i++
+				continue
+			}
+			return Failf("trash empty: unexpected argument '%s'", args[i])
+		}
+		return trashEmpty(ctx, older)
+	}
+	return Fail("trash: unknown subcommand. Try 'trash list', 'trash restore <name>' or 'trash empty --older 30d'")
+}
+
+func trashShow() Result {
+	items, err := trashList()
+	if err != nil {
+		return Fail("trash list: " + err.Error())
+	}
+	if len(items) == 0 {
+		return OK("Trash is empty.")
+	}
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "%-24s %-16s %s\n", "NAME", "DELETED", "FROM")
+	for _, it := range items {
+		fmt.Fprintf(sb, "%-24s %-16s %s\n", it.Name, it.Deleted.Format("2006-01-02 15:04"), it.Path)
+	}
+	return OK(strings.TrimRight(sb.String(), "\n"))
+}
+
+// trashRestore puts items back where they came from. A name is the entry
+// name shown by `trash list`, or else an original file name, in which
+// case the most recently deleted match is restored.
+func trashRestore(ctx context.Context, names []string) Result {
+	items, err := trashList()
+	if err != nil {
+		return Fail("trash restore: " + err.Error())
+	}
+	op := beginOp(ctx)
+	out := []string{}
+	failed := false
+	for _, n := range names {
+		it, ok := findTrashItem(items, n)
+		if !ok {
+			out = append(out, fmt.Sprintf("trash restore: no item '%s' in trash", n))
+			failed = true
+			continue
+		}
+		if err := op.restore(filepath.Join(trashDir(), "files", it.Name), it.Path); err != nil {
+			out = append(out, "trash restore: "+err.Error())
+			failed = true
+			continue
+		}
+		out = op.log(out, "Restored %s", it.Path)
+	}
+	return op.done(out, failed)
+}
+
+func findTrashItem(items []trashItem, name string) (trashItem, bool) {
+	for _, it := range items {
+		if it.Name == name {
+			return it, true
+		}
+	}
+	for _, it := range items {
+		if filepath.Base(it.Path) == name || it.Path == name {
+			return it, true
+		}
+	}
+	return trashItem{}, false
+}
+
+// trashEmpty deletes the items in the trash for good, or on a dry run
+// lists them.
+func trashEmpty(ctx context.Context, older time.Duration) Result {
+	items, err := trashList()
+	if err != nil {
+		return Fail("trash empty: " + err.Error())
+	}
+	op := beginOp(ctx)
+	cutoff := time.Now().Add(-older)
+	n := 0
+	var errs []string
+	for _, it := range items {
+		if older > 0 && it.Deleted.After(cutoff) {
+			continue
+		}
+		if op.dry {
+			op.planned("delete", it.Name+" (from "+it.Path+")")
+			continue
+		}
+		if err := trashRemove(it); err != nil {
+			errs = append(errs, fmt.Sprintf("trash empty: %s: %v", it.Name, err))
+			continue
+		}
+		n++
+	}
+	if op.dry {
+		return op.done(nil, false)
+	}
+	msg := fmt.Sprintf("Removed %d item(s) from trash", n)
+	if older > 0 {
+		msg += fmt.Sprintf(" deleted more than %s ago", formatAge(older))
+	}
+	return okIf(strings.Join(append([]string{msg}, errs...), "\n"), len(errs) > 0)
+}
+
+// ParseAge reads an age such as 30d, 2w or 12h; plain Go durations work
+// too.
+func ParseAge(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if len(s) > 1 {
+		if u, ok := units[s[len(s)-1]]; ok {
+			n, err := strconv.Atoi(s[:len(s)-1])
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age '%s'", s)
+			}
+			return time.Duration(n) * u, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age '%s' (use e.g. 30d, 2w, 12h)", s)
+	}
+	return d, nil
+}
+
+func formatAge(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
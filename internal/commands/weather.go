@@ -11,13 +11,24 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
 )
 
-func CmdWeather(args []string) string {
+func init() {
+	Register(Command{
+		Name:     "weather",
+		Usage:    "weather [location]",
+		Summary:  "Get weather",
+		Examples: []string{"weather", "weather berlin"},
+		Run:      CmdWeather,
+	})
+}
+
+func CmdWeather(ctx context.Context, args []string) Result {
 	loc := "your location"
 	if len(args) > 0 {
 		loc = strings.Join(args, " ")
@@ -29,7 +40,7 @@ func CmdWeather(args []string) string {
 
 	cacheKey := "weather:" + strings.ToLower(strings.TrimSpace(loc))
 	if data, ok := readCache(cacheKey); ok {
-		return string(data) + " (cached)"
+		return OK(string(data) + " (cached)")
 	}
 
 	geourl := "https://geocoding-api.open-meteo.com/v1/search?name=" + url.QueryEscape(loc) + "&count=1&language=en"
@@ -40,16 +51,16 @@ func CmdWeather(args []string) string {
 			s := strings.TrimSpace(string(b2))
 			if s != "" {
 				_ = writeCache(cacheKey, []byte(s), 300) // cache 5 minutes
-				return s
+				return OK(s)
 			}
 		}
 		if data, ok := readCache(cacheKey); ok {
-			return string(data) + " (cached)"
+			return OK(string(data) + " (cached)")
 		}
 		if err != nil {
-			return "weather: geocode failed: " + err.Error()
+			return Fail("weather: geocode failed: " + err.Error())
 		}
-		return fmt.Sprintf("weather: geocode returned status %d", sc)
+		return Failf("weather: geocode returned status %d", sc)
 	}
 
 	var geoRes struct {
@@ -67,13 +78,13 @@ func CmdWeather(args []string) string {
 			s := strings.TrimSpace(string(b2))
 			if s != "" {
 				_ = writeCache(cacheKey, []byte(s), 300)
-				return s
+				return OK(s)
 			}
 		}
 		if data, ok := readCache(cacheKey); ok {
-			return string(data) + " (cached)"
+			return OK(string(data) + " (cached)")
 		}
-		return "weather: geocode parse error: " + err.Error()
+		return Fail("weather: geocode parse error: " + err.Error())
 	}
 	if len(geoRes.Results) == 0 {
 		wttrURL := "https://wttr.in/" + url.PathEscape(loc) + "?format=3"
@@ -81,13 +92,13 @@ func CmdWeather(args []string) string {
 			s := strings.TrimSpace(string(b2))
 			if s != "" {
 				_ = writeCache(cacheKey, []byte(s), 300)
-				return s
+				return OK(s)
 			}
 		}
 		if data, ok := readCache(cacheKey); ok {
-			return string(data) + " (cached)"
+			return OK(string(data) + " (cached)")
 		}
-		return "weather: location not found"
+		return Fail("weather: location not found")
 	}
 	g := geoRes.Results[0]
 
@@ -100,16 +111,16 @@ func CmdWeather(args []string) string {
 			s := strings.TrimSpace(string(b3))
 			if s != "" {
 				_ = writeCache(cacheKey, []byte(s), 300)
-				return s
+				return OK(s)
 			}
 		}
 		if data, ok := readCache(cacheKey); ok {
-			return string(data) + " (cached)"
+			return OK(string(data) + " (cached)")
 		}
 		if err2 != nil {
-			return "weather: forecast fetch failed: " + err2.Error()
+			return Fail("weather: forecast fetch failed: " + err2.Error())
 		}
-		return fmt.Sprintf("weather: forecast returned status %d", sc2)
+		return Failf("weather: forecast returned status %d", sc2)
 	}
 
 	var fRes struct {
@@ -127,21 +138,21 @@ func CmdWeather(args []string) string {
 			s := strings.TrimSpace(string(b3))
 			if s != "" {
 				_ = writeCache(cacheKey, []byte(s), 300)
-				return s
+				return OK(s)
 			}
 		}
 		if data, ok := readCache(cacheKey); ok {
-			return string(data) + " (cached)"
+			return OK(string(data) + " (cached)")
 		}
-		return "weather: forecast parse error: " + err.Error()
+		return Fail("weather: forecast parse error: " + err.Error())
 	}
 
 	c := fRes.CurrentWeather
 	if c.Time == "" {
 		if data, ok := readCache(cacheKey); ok {
-			return string(data) + " (cached)"
+			return OK(string(data) + " (cached)")
 		}
-		return "weather: no current weather available"
+		return Fail("weather: no current weather available")
 	}
 
 	wDesc := map[int]string{
@@ -183,5 +194,5 @@ func CmdWeather(args []string) string {
 	out := fmt.Sprintf("%s — %s — %.1f°C — wind %.1f km/h", name, desc, c.Temperature, c.Windspeed)
 
 	_ = writeCache(cacheKey, []byte(out), 300)
-	return out
+	return OK(out)
 }
@@ -0,0 +1,241 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+type aliasKey struct{}
+
+// alias returns the expansion of a user alias, unless that alias is
+// already being expanded under ctx. Built-in commands win over an alias
+// of the same name, which could only have been saved before such names
+// were refused.
+func (e *Engine) alias(ctx context.Context, name string) (string, bool) {
+	name = strings.ToLower(name)
+	if active, _ := ctx.Value(aliasKey{}).(map[string]bool); active[name] || e.isBuiltin(name) {
+		return "", false
+	}
+	return e.store.Alias(name)
+}
+
+// isBuiltin reports whether name is a registered command or one of its
+// aliases, which a user alias may not take.
+func (e *Engine) isBuiltin(name string) bool {
+	_, ok := e.registry.Lookup(name)
+	return ok
+}
+
+// aliasCommand runs an alias like a command. Arguments fill `$1`..`$9`
+// and `$@` in the expansion; when it uses none of them they are appended.
+func (e *Engine) aliasCommand(name, expansion string) *commands.Command {
+	name = strings.ToLower(name)
+	return &commands.Command{
+		Name: name,
+		Run: func(ctx context.Context, args []string) commands.Result {
+			active := map[string]bool{name: true}
+			if prev, ok := ctx.Value(aliasKey{}).(map[string]bool); ok {
+				for k := range prev {
+					active[k] = true
+				}
+			}
+			ctx = context.WithValue(ctx, aliasKey{}, active)
+			line := expansion
+			if !usesPositional(expansion) && len(args) > 0 {
+				line += " " + quoteArgs(args)
+			}
+			return e.execute(withArgs(ctx, args), line)
+		},
+	}
+}
+
+func usesPositional(s string) bool {
+	for i := 0; i+1 < len(s); i++ {
+		if s[i] == '$' && (s[i+1] == '@' || s[i+1] == '#' || (s[i+1] >= '0' && s[i+1] <= '9')) {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteArgs joins args back into a command line that lexes to the same
+// words, single-quoting any word the lexer would otherwise change.
+func quoteArgs(args []string) string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || needsQuotes(a) {
+			a = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		out[i] = a
+	}
+	return strings.Join(out, " ")
+}
+
+func isAliasName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isNameByte(s[i]) && s[i] != '-' && s[i] != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Engine) CmdAlias(ctx context.Context, args []string) commands.Result {
+	if len(args) == 0 {
+		return e.aliasList()
+	}
+	switch strings.ToLower(args[0]) {
+	case "list", "ls":
+		return e.aliasList()
+	case "add", "set":
+		if len(args) < 3 {
+			return commands.Fail("alias add: usage: alias add <name> \"<command>\"")
+		}
+		name := strings.ToLower(args[1])
+		if !isAliasName(name) {
+			return commands.Failf("alias add: invalid name '%s'", args[1])
+		}
+		if e.isBuiltin(name) {
+			return commands.Failf("alias add: '%s' is a built-in command; pick another name", args[1])
+		}
+		expansion := strings.TrimSpace(args[2])
+		if len(args) > 3 {
+			expansion = quoteArgs(args[2:])
+		}
+		if err := e.store.SetAlias(name, expansion); err != nil {
+			return commands.Fail("alias add: save error: " + err.Error())
+		}
+		return commands.OK(fmt.Sprintf("Alias %s -> %s", name, expansion))
+	case "rm", "remove", "del":
+		if len(args) < 2 {
+			return commands.Fail("alias rm: usage: alias rm <name>...")
+		}
+		failed := false
+		var out []string
+		for _, n := range args[1:] {
+			found, err := e.store.DeleteAlias(strings.ToLower(n))
+			switch {
+			case err != nil:
+				out = append(out, "alias rm: "+n+": "+err.Error())
+				failed = true
+			case !found:
+				out = append(out, "alias rm: no such alias '"+n+"'")
+				failed = true
+			default:
+				out = append(out, "Removed alias "+n)
+			}
+		}
+		if failed {
+			return commands.Fail(strings.Join(out, "\n"))
+		}
+		return commands.OK(strings.Join(out, "\n"))
+	case "export":
+		m, err := e.store.Aliases()
+		if err != nil {
+			return commands.Fail("alias export: " + err.Error())
+		}
+		b, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return commands.Fail("alias export: " + err.Error())
+		}
+		if len(args) < 2 {
+			return commands.OK(string(b))
+		}
+		path := e.resolve(args[1])
+		out, err := commands.WriteFile(ctx, path, append(b, '\n'), fmt.Sprintf("Exported %d aliases to %s", len(m), path))
+		if err != nil {
+			return commands.Fail("alias export: " + err.Error())
+		}
+		return commands.OK(out)
+	case "import":
+		if len(args) < 2 {
+			return commands.Fail("alias import: usage: alias import <file> [--replace]")
+		}
+		return e.aliasImport(ctx, args[1], len(args) > 2 && args[2] == "--replace")
+	}
+	return commands.Fail("alias: unknown subcommand. Try 'alias add', 'alias list', 'alias rm', 'alias export' or 'alias import'")
+}
+
+func (e *Engine) aliasList() commands.Result {
+	m, err := e.store.Aliases()
+	if err != nil {
+		return commands.Fail("alias list: " + err.Error())
+	}
+	if len(m) == 0 {
+		return commands.OK("No aliases. Add one with: alias add gs \"grep -i -n\"")
+	}
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	sb := &strings.Builder{}
+	for _, n := range names {
+		fmt.Fprintf(sb, "%-12s %s\n", n, m[n])
+	}
+	return commands.OK(strings.TrimRight(sb.String(), "\n"))
+}
+
+// aliasImport merges aliases from a file written by `alias export`.
+// Existing aliases are kept unless replace is set.
+func (e *Engine) aliasImport(ctx context.Context, file string, replace bool) commands.Result {
+	b, err := commands.ReadFile(ctx, file)
+	if err != nil {
+		return commands.Fail("alias import: " + err.Error())
+	}
+	var in map[string]string
+	if err := json.Unmarshal(b, &in); err != nil {
+		return commands.Fail("alias import: parse error: " + err.Error())
+	}
+	have, err := e.store.Aliases()
+	if err != nil {
+		return commands.Fail("alias import: " + err.Error())
+	}
+	added, skipped, builtin := 0, 0, 0
+	for name, expansion := range in {
+		name = strings.ToLower(name)
+		if !isAliasName(name) {
+			skipped++
+			continue
+		}
+		if e.isBuiltin(name) {
+			builtin++
+			continue
+		}
+		if _, ok := have[name]; ok && !replace {
+			skipped++
+			continue
+		}
+		if err := e.store.SetAlias(name, expansion); err != nil {
+			return commands.Fail("alias import: save error: " + err.Error())
+		}
+		added++
+	}
+	msg := fmt.Sprintf("Imported %d aliases", added)
+	if skipped > 0 {
+		msg += fmt.Sprintf(" (%d skipped; use --replace to overwrite existing ones)", skipped)
+	}
+	if builtin > 0 {
+		msg += fmt.Sprintf(" (%d skipped: names of built-in commands)", builtin)
+	}
+	return commands.OK(msg)
+}
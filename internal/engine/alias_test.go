@@ -0,0 +1,55 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xrootAnon/0xRootShell/internal/store"
+)
+
+// newTestEngine returns an engine with a store of its own, started in a
+// temporary directory so the data/ files commands keep stay out of the
+// tree.
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	st, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return NewEngine(st, nil)
+}
+
+func TestAliasBuiltinNames(t *testing.T) {
+	e := newTestEngine(t)
+	for _, name := range []string{"alias", "cd", "end"} {
+		res := e.RunScript("t", "alias add "+name+" pwd", nil, nil)
+		if !res.Failed() || !strings.Contains(res.Output, "built-in command") {
+			t.Errorf("alias add %s: got %q, want it refused", name, res.Output)
+		}
+	}
+	// One saved before such names were refused must not shadow the
+	// command, so it can still be removed.
+	if err := e.store.SetAlias("alias", "pwd"); err != nil {
+		t.Fatal(err)
+	}
+	if res := e.RunScript("t", "alias rm alias", nil, nil); res.Output != "Removed alias alias" {
+		t.Errorf("alias rm alias: got %q", res.Output)
+	}
+	if res := e.RunScript("t", "alias add gs 'echo hi'\ngs", nil, nil); !strings.HasSuffix(res.Output, "hi") {
+		t.Errorf("gs: got %q", res.Output)
+	}
+}
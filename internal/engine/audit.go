@@ -0,0 +1,253 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+	"github.com/0xrootAnon/0xRootShell/internal/store"
+)
+
+// audit adds a policy event to the audit trail. A failure to record it
+// must not stop the command, so it is ignored.
+func (e *Engine) audit(line, kind, detail string) {
+	_ = e.store.AddAudit(store.AuditEvent{Time: time.Now(), Kind: kind, Command: e.Redact(line), Status: "refused", Detail: detail, Cwd: e.Cwd()})
+}
+
+// logExec adds a command line that ran in env to the audit trail. A
+// background job is logged once it ends.
+func (e *Engine) logExec(env *commands.Env, start time.Time, res commands.Result, job *Job) {
+	ev := store.AuditEvent{Time: start, Kind: "exec", Command: e.Redact(env.Line), Cwd: env.Dir, DryRun: env.DryRun}
+	if job == nil {
+		ev.Code = res.Code
+		ev.Status = execStatus(res.Code)
+		ev.Duration = time.Since(start).Milliseconds()
+		ev.Output = len(res.Output)
+		ev.Files = env.Files.Paths()
+		_ = e.store.AddAudit(ev)
+		return
+	}
+	ev.Job = job.ID
+	go func() {
+		<-job.Done()
+		switch job.State() {
+		case JobFailed:
+			ev.Code = 1
+		case JobCancelled:
+			ev.Code = 130
+		}
+		ev.Status = execStatus(ev.Code)
+		ev.Duration = job.Elapsed().Milliseconds()
+		ev.Output = job.OutputSize()
+		ev.Files = env.Files.Paths()
+		_ = e.store.AddAudit(ev)
+	}()
+}
+
+func execStatus(code int) string {
+	switch code {
+	case 0:
+		return "ok"
+	case 130:
+		return "cancelled"
+	}
+	return "failed"
+}
+
+func (e *Engine) CmdAudit(ctx context.Context, args []string) commands.Result {
+	export := ""
+	if len(args) > 0 && strings.ToLower(args[0]) == "export" {
+		if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+			return commands.Fail("audit export: usage: audit export <file.jsonl> [filters]")
+		}
+		export, args = args[1], args[2:]
+	}
+	f, limit, err := parseAuditArgs(args, export == "")
+	if err != nil {
+		return commands.Fail("audit: " + err.Error())
+	}
+	events, err := e.store.AuditEvents(f, limit)
+	if err != nil {
+		return commands.Fail("audit: " + err.Error())
+	}
+	if export != "" {
+		return e.exportAudit(ctx, export, events)
+	}
+	if len(events) == 0 {
+		return commands.OK("No matching audit events.")
+	}
+	sb := &strings.Builder{}
+	for i := len(events) - 1; i >= 0; i-- {
+		writeAuditEvent(sb, events[i])
+	}
+	return commands.OK(strings.TrimRight(sb.String(), "\n"))
+}
+
+// parseAuditArgs reads the filters of `audit`. Listing shows the last 20
+// events unless -n says otherwise; export takes all of them.
+func parseAuditArgs(args []string, listing bool) (store.AuditFilter, int, error) {
+	var f store.AuditFilter
+	limit := 0
+	if listing {
+		limit = 20
+	}
+	now := time.Now()
+	for i := 0; i < len(args); i++ {
+		flag := args[i]
+		if i+1 >= len(args) {
+			return f, 0, fmt.Errorf("%s expects a value", flag)
+		}
+		val := args[i+1]
+		i++
+		switch flag {
+		case "--date", "--on":
+			t, span, err := auditTime(val, now)
+			if err != nil {
+				return f, 0, err
+			}
+			if span == 0 {
+				return f, 0, fmt.Errorf("%s expects a day, such as today or 2025-06-01", flag)
+			}
+			f.Since, f.Until = t, t.Add(span)
+		case "--since":
+			t, _, err := auditTime(val, now)
+			if err != nil {
+				return f, 0, err
+			}
+			f.Since = t
+		case "--until":
+			t, span, err := auditTime(val, now)
+			if err != nil {
+				return f, 0, err
+			}
+			f.Until = t.Add(span)
+		case "--verb":
+			f.Verb = val
+		case "--status":
+			switch s := strings.ToLower(val); s {
+			case "ok", "failed", "cancelled", "refused":
+				f.Status = s
+			case "fail", "error":
+				f.Status = "failed"
+			default:
+				return f, 0, fmt.Errorf("unknown status '%s' (ok, failed, cancelled or refused)", val)
+			}
+		case "--kind":
+			f.Kind = val
+		case "--file":
+			f.File = val
+		case "-n", "--limit":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return f, 0, fmt.Errorf("invalid count '%s'", val)
+			}
+			limit = n
+		default:
+			return f, 0, fmt.Errorf("unknown option '%s'", flag)
+		}
+	}
+	return f, limit, nil
+}
+
+// auditTime reads a point in time: today, yesterday, a date such as
+// 2025-06-01, a date and time such as 2025-06-01T14:30, or an age such as
+// 2h or 3d meaning that long ago. For a day, span is its length, so the
+// end of the day can be found; otherwise it is 0.
+func auditTime(s string, now time.Time) (time.Time, time.Duration, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(s) {
+	case "today":
+		return today, 24 * time.Hour, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), 24 * time.Hour, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, 24 * time.Hour, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02T15:04:05", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, 0, nil
+		}
+	}
+	if d, err := commands.ParseAge(s); err == nil {
+		return now.Add(-d), 0, nil
+	}
+	return time.Time{}, 0, fmt.Errorf("invalid time '%s' (use today, yesterday, 2025-06-01, 2025-06-01T14:30 or an age such as 3d)", s)
+}
+
+func writeAuditEvent(sb *strings.Builder, ev store.AuditEvent) {
+	label, took := ev.Kind, ""
+	if ev.Kind == "exec" {
+		label = ev.Status
+		took = formatElapsed(time.Duration(ev.Duration) * time.Millisecond)
+	}
+	fmt.Fprintf(sb, "#%-5d %s  %-9s %7s  %s\n", ev.ID, ev.Time.Local().Format("2006-01-02 15:04:05"), label, took, ev.Command)
+	var notes []string
+	if ev.Cwd != "" {
+		notes = append(notes, "in "+ev.Cwd)
+	}
+	if ev.Kind == "exec" {
+		notes = append(notes, formatBytes(ev.Output)+" of output")
+	}
+	if ev.Code != 0 {
+		notes = append(notes, fmt.Sprintf("exit %d", ev.Code))
+	}
+	if ev.Job != 0 {
+		notes = append(notes, fmt.Sprintf("job %d", ev.Job))
+	}
+	if ev.DryRun {
+		notes = append(notes, "dry run")
+	}
+	if len(notes) > 0 {
+		fmt.Fprintf(sb, "       %s\n", strings.Join(notes, ", "))
+	}
+	if ev.Detail != "" {
+		fmt.Fprintf(sb, "       %s\n", ev.Detail)
+	}
+	for _, p := range ev.Files {
+		fmt.Fprintf(sb, "       changed %s\n", p)
+	}
+}
+
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// exportAudit writes events to file as JSON lines, oldest first. It
+// writes like any file command, so the policy, dry runs and undo apply.
+func (e *Engine) exportAudit(ctx context.Context, file string, events []store.AuditEvent) commands.Result {
+	sb := &strings.Builder{}
+	enc := json.NewEncoder(sb)
+	for i := len(events) - 1; i >= 0; i-- {
+		if err := enc.Encode(events[i]); err != nil {
+			return commands.Fail("audit export: " + err.Error())
+		}
+	}
+	path := e.resolve(file)
+	out, err := commands.WriteFile(ctx, path, []byte(sb.String()), fmt.Sprintf("Exported %d audit events to %s", len(events), path))
+	if err != nil {
+		return commands.Fail("audit export: " + err.Error())
+	}
+	return commands.OK(out)
+}
@@ -0,0 +1,174 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xrootAnon/0xRootShell/internal/store"
+)
+
+func TestAuditTime(t *testing.T) {
+	now := time.Date(2025, 6, 10, 15, 30, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	tests := []struct {
+		in   string
+		want time.Time
+		span time.Duration
+	}{
+		{"today", time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC), day},
+		{"Yesterday", time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC), day},
+		{"2025-06-01", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), day},
+		{"2025-06-01T14:30", time.Date(2025, 6, 1, 14, 30, 0, 0, time.UTC), 0},
+		{"2h", now.Add(-2 * time.Hour), 0},
+		{"3d", now.Add(-3 * day), 0},
+	}
+	for _, tt := range tests {
+		got, span, err := auditTime(tt.in, now)
+		if err != nil || !got.Equal(tt.want) || span != tt.span {
+			t.Errorf("auditTime(%q) = %v, %v, %v; want %v, %v", tt.in, got, span, err, tt.want, tt.span)
+		}
+	}
+	if _, _, err := auditTime("soon", now); err == nil {
+		t.Errorf("auditTime(soon): expected an error")
+	}
+}
+
+func TestParseAuditArgs(t *testing.T) {
+	f, limit, err := parseAuditArgs([]string{"--verb", "del", "--status", "error", "--file", "notes", "-n", "5"}, true)
+	want := store.AuditFilter{Verb: "del", Status: "failed", File: "notes"}
+	if err != nil || !reflect.DeepEqual(f, want) || limit != 5 {
+		t.Errorf("got %+v, %d, %v; want %+v, 5", f, limit, err, want)
+	}
+	if _, limit, _ := parseAuditArgs(nil, true); limit != 20 {
+		t.Errorf("listing limit = %d, want 20", limit)
+	}
+	if _, limit, _ := parseAuditArgs(nil, false); limit != 0 {
+		t.Errorf("export limit = %d, want 0", limit)
+	}
+	for _, args := range [][]string{{"--verb"}, {"--status", "maybe"}, {"--colour", "red"}, {"-n", "-1"}, {"--date", "2h"}} {
+		if _, _, err := parseAuditArgs(args, true); err == nil {
+			t.Errorf("parseAuditArgs(%q): expected an error", args)
+		}
+	}
+}
+
+// TestAuditFilters checks that the filters select the logged lines.
+func TestAuditFilters(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	e := newTestEngine(t)
+	e.Execute("cd '" + dir + "'")
+	e.Execute("echo hi > notes.txt")
+	e.Execute("cat missing.txt")
+	e.Execute("del notes.txt")
+	e.Execute("nosuchcommand")
+	tests := []struct {
+		args string
+		want []string
+	}{
+		{"--verb del", []string{"del notes.txt"}},
+		{"--status failed", []string{"cat missing.txt", "nosuchcommand"}},
+		{"--file notes.txt", []string{"echo hi > notes.txt", "del notes.txt"}},
+		{"--verb echo --status ok", []string{"echo hi > notes.txt"}},
+		{"--since 1h -n 2", []string{"del notes.txt", "nosuchcommand"}},
+		{"--date yesterday", nil},
+	}
+	for _, tt := range tests {
+		args := strings.Fields(tt.args)
+		f, limit, err := parseAuditArgs(args, true)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.args, err)
+		}
+		events, err := e.store.AuditEvents(f, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for i := len(events) - 1; i >= 0; i-- {
+			got = append(got, events[i].Command)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("audit %s: got %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+// TestAuditExport checks that export writes the filtered events as JSON
+// lines, oldest first, like any other file write.
+func TestAuditExport(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	e := newTestEngine(t)
+	e.policy.Protected = append(e.policy.Protected, filepath.Join(dir, "keep.jsonl"))
+	e.policy.Confirm = append(e.policy.Confirm, "> "+filepath.Join(dir, "ask.jsonl"))
+	e.Execute("cd '" + dir + "'")
+	e.Execute("echo one")
+	e.Execute("echo two")
+	e.Execute("cat missing.txt")
+
+	res := e.Execute("audit export out.jsonl --verb echo")
+	if res.Failed() || !strings.HasPrefix(res.Output, "Exported 2 audit events") {
+		t.Fatalf("export: %q", res.Output)
+	}
+	f, err := os.Open(filepath.Join(dir, "out.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var ev store.AuditEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		got = append(got, ev.Command)
+	}
+	f.Close()
+	if want := []string{"echo one", "echo two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("exported %q, want %q", got, want)
+	}
+	if res := e.Execute("undo"); res.Failed() {
+		t.Errorf("undo export: %s", res.Output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out.jsonl")); err == nil {
+		t.Errorf("undo left the export")
+	}
+
+	refused := []struct{ line, want string }{
+		{"audit export keep.jsonl", "protected"},
+		{"audit export ask.jsonl", "needs confirmation"},
+	}
+	for _, tt := range refused {
+		if res := e.Execute(tt.line); !res.Failed() || !strings.Contains(res.Output, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.line, res.Output, tt.want)
+		}
+	}
+	if res := e.Execute("audit export out.jsonl --dry-run"); res.Failed() || !strings.HasPrefix(res.Output, "Dry run") {
+		t.Errorf("dry run export: %q", res.Output)
+	}
+	if res := e.Execute("audit export ask.jsonl --confirm"); res.Failed() {
+		t.Errorf("confirmed export: %q", res.Output)
+	}
+	for _, name := range []string{"keep.jsonl", "out.jsonl"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s was written", name)
+		}
+	}
+}
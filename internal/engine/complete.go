@@ -0,0 +1,319 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+// Completion is what Tab offers for the word before the cursor. Each
+// item replaces the line from Start up to the cursor.
+type Completion struct {
+	Start int
+	Items []commands.Candidate
+}
+
+// Complete works out what could go in place of the last word of line,
+// the text before the cursor: a command, subcommand, flag, value or file
+// path.
+func (e *Engine) Complete(ctx context.Context, line string) Completion {
+	words, word, start, redirect := completionWords(line)
+	var items []commands.Candidate
+	switch {
+	case redirect:
+		items = e.completePaths(word, false)
+	case len(words) == 0:
+		items = e.completeVerbs(word)
+	default:
+		items = e.completeArgs(ctx, words, word)
+	}
+	for i := range items {
+		items[i].Text = quoteWord(items[i].Text)
+	}
+	return Completion{Start: start, Items: items}
+}
+
+// completionWords splits the command that line ends in into the words
+// before the last one and the last one, unquoted as the lexer would,
+// which starts at offset start. redirect is set when that word follows
+// > or >>.
+func completionWords(line string) (words []string, word string, start int, redirect bool) {
+	cur := &strings.Builder{}
+	in := false
+	var quote byte
+	begin := func(i int) {
+		if !in {
+			in, start = true, i
+		}
+	}
+	flush := func() {
+		if in {
+			words = append(words, cur.String())
+			cur.Reset()
+			in, redirect = false, false
+		}
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\' && i+1 < len(line) && escapes(line[i+1], true):
+			i++
+			cur.WriteByte(line[i])
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				cur.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			begin(i)
+			quote = c
+		case c == '\\' && i+1 < len(line) && escapes(line[i+1], false):
+			begin(i)
+			i++
+			cur.WriteByte(line[i])
+		case c == ' ' || c == '\t':
+			flush()
+		case strings.IndexByte("|;&>()", c) >= 0:
+			flush()
+			words, redirect = nil, c == '>'
+		default:
+			begin(i)
+			cur.WriteByte(c)
+		}
+	}
+	if !in {
+		return words, "", len(line), redirect
+	}
+	return words, cur.String(), start, redirect
+}
+
+// quoteWord quotes a completed word if the lexer would otherwise split
+// or expand it, keeping a leading ~/ outside the quotes.
+func quoteWord(s string) string {
+	if !needsQuotes(strings.ReplaceAll(s, "~", "")) {
+		return s
+	}
+	if rest, ok := strings.CutPrefix(s, "~/"); ok {
+		return "~/" + quoteArgs([]string{rest})
+	}
+	return quoteArgs([]string{s})
+}
+
+func matching(items []commands.Candidate, prefix string) []commands.Candidate {
+	var out []commands.Candidate
+	seen := map[string]bool{}
+	for _, it := range items {
+		if seen[it.Text] || !strings.HasPrefix(strings.ToLower(it.Text), strings.ToLower(prefix)) {
+			continue
+		}
+		seen[it.Text] = true
+		out = append(out, it)
+	}
+	return out
+}
+
+func (e *Engine) completeVerbs(prefix string) []commands.Candidate {
+	var items []commands.Candidate
+	for _, n := range e.registry.Names() {
+		cmd, _ := e.registry.Lookup(n)
+		items = append(items, commands.Candidate{Text: n, Note: cmd.Summary})
+	}
+	if aliases, err := e.store.Aliases(); err == nil {
+		for n, exp := range aliases {
+			items = append(items, commands.Candidate{Text: n, Note: "alias for " + exp})
+		}
+	}
+	e.mu.Lock()
+	for n := range e.funcs {
+		items = append(items, commands.Candidate{Text: n, Note: "function"})
+	}
+	e.mu.Unlock()
+	items = matching(items, prefix)
+	sort.Slice(items, func(i, j int) bool { return items[i].Text < items[j].Text })
+	return items
+}
+
+// completeArgs offers flags for a word starting with -, otherwise the
+// command's subcommands and values, and file paths when there are none.
+func (e *Engine) completeArgs(ctx context.Context, words []string, prefix string) []commands.Candidate {
+	cmd, ok := e.registry.Lookup(words[0])
+	if !ok {
+		return e.completePaths(prefix, false)
+	}
+	args := words[1:]
+	if strings.HasPrefix(prefix, "-") {
+		flags := append([]string(nil), cmd.Flags...)
+		if cmd.DryRun {
+			flags = append(flags, "--dry-run")
+		}
+		if _, risky := e.policy.ConfirmRule(cmd, args); risky {
+			flags = append(flags, "--confirm")
+		}
+		return matching(commands.Words(flags...), prefix)
+	}
+	var items []commands.Candidate
+	if len(args) == 0 {
+		items = commands.Words(cmd.Subcommands...)
+	}
+	if cmd.Complete != nil {
+		items = append(items, cmd.Complete(commands.WithEnv(ctx, e.env()), args)...)
+	}
+	if items = matching(items, prefix); len(items) > 0 {
+		return items
+	}
+	return e.completePaths(prefix, cmd.Name == "cd")
+}
+
+// completePaths lists the entries of the directory prefix points into
+// whose names start with the rest of it. Hidden entries are offered only
+// for a prefix starting with a dot.
+func (e *Engine) completePaths(prefix string, dirsOnly bool) []commands.Candidate {
+	cut := strings.LastIndex(prefix, "/")
+	if runtime.GOOS == "windows" {
+		cut = strings.LastIndexAny(prefix, `/\`)
+	}
+	dir, base := prefix[:cut+1], prefix[cut+1:]
+	root := dir
+	if root == "" {
+		root = "."
+	}
+	root = e.resolve(root)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var items []commands.Candidate
+	for _, ent := range entries {
+		name := ent.Name()
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		if !strings.HasPrefix(name, base) && !(runtime.GOOS == "windows" && strings.HasPrefix(strings.ToLower(name), strings.ToLower(base))) {
+			continue
+		}
+		isDir := ent.IsDir()
+		if ent.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(root, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		if dirsOnly && !isDir {
+			continue
+		}
+		if isDir {
+			name += "/"
+		}
+		items = append(items, commands.Candidate{Text: dir + name})
+	}
+	return items
+}
+
+func (e *Engine) completeHelp(ctx context.Context, args []string) []commands.Candidate {
+	if len(args) > 0 {
+		return nil
+	}
+	var items []commands.Candidate
+	for _, c := range e.registry.Commands() {
+		items = append(items, commands.Candidate{Text: c.Name, Note: c.Summary})
+	}
+	return items
+}
+
+func (e *Engine) completeJobs(ctx context.Context, args []string) []commands.Candidate {
+	if len(args) > 0 {
+		return nil
+	}
+	var items []commands.Candidate
+	for _, j := range e.jobs.list() {
+		items = append(items, commands.Candidate{Text: strconv.Itoa(j.ID), Note: j.State().String() + ": " + j.Line})
+	}
+	return items
+}
+
+func (e *Engine) completeSet(ctx context.Context, args []string) []commands.Candidate {
+	switch {
+	case len(args) == 0:
+		items := commands.Words("dryrun")
+		for n, s := range settings {
+			if !strings.Contains(n, " ") {
+				items = append(items, commands.Candidate{Text: n, Note: s.usage})
+			}
+		}
+		return items
+	case len(args) == 1 && strings.EqualFold(args[0], "dryrun"):
+		return commands.Words("on", "off")
+	case len(args) == 1:
+		items := commands.Words(settings[strings.ToLower(args[0])].choices...)
+		for n, s := range settings {
+			if sub, ok := strings.CutPrefix(n, strings.ToLower(args[0])+" "); ok {
+				items = append(items, commands.Candidate{Text: sub, Note: s.usage})
+			}
+		}
+		return items
+	case len(args) == 2:
+		return commands.Words(settings[strings.ToLower(args[0]+" "+args[1])].choices...)
+	}
+	return nil
+}
+
+func (e *Engine) completeVars(ctx context.Context, args []string) []commands.Candidate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var items []commands.Candidate
+	for n, v := range e.vars {
+		items = append(items, commands.Candidate{Text: n, Note: v})
+	}
+	for n := range e.funcs {
+		items = append(items, commands.Candidate{Text: n, Note: "function"})
+	}
+	return items
+}
+
+func (e *Engine) completeAlias(ctx context.Context, args []string) []commands.Candidate {
+	if len(args) != 1 || strings.ToLower(args[0]) != "rm" {
+		return nil
+	}
+	aliases, _ := e.store.Aliases()
+	var items []commands.Candidate
+	for n, exp := range aliases {
+		items = append(items, commands.Candidate{Text: n, Note: exp})
+	}
+	return items
+}
+
+// completeAudit offers values for the audit filters that have a fixed
+// set of them.
+func (e *Engine) completeAudit(ctx context.Context, args []string) []commands.Candidate {
+	if len(args) == 0 {
+		return nil
+	}
+	switch args[len(args)-1] {
+	case "--status":
+		return commands.Words("ok", "failed", "cancelled", "refused")
+	case "--kind":
+		return commands.Words("exec", "sandbox", "protected")
+	case "--date", "--since", "--until":
+		return commands.Words("today", "yesterday")
+	case "--verb":
+		return e.completeHelp(ctx, nil)
+	}
+	return nil
+}
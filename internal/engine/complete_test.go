@@ -0,0 +1,101 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestCompletionWords(t *testing.T) {
+	tests := []struct {
+		line     string
+		words    []string
+		word     string
+		start    int
+		redirect bool
+	}{
+		{"", nil, "", 0, false},
+		{"he", nil, "he", 0, false},
+		{"cd ", []string{"cd"}, "", 3, false},
+		{`cd C:\Us`, []string{"cd"}, `C:\Us`, 3, false},
+		{`del C:\logs\*.lo`, []string{"del"}, `C:\logs\*.lo`, 4, false},
+		{`open \\server\sh`, []string{"open"}, `\\server\sh`, 5, false},
+		{`cat my\ fi`, []string{"cat"}, "my fi", 4, false},
+		{`cat 'C:\My Do`, []string{"cat"}, `C:\My Do`, 4, false},
+		{`cat "say \"hi`, []string{"cat"}, `say "hi`, 4, false},
+		{`cat "C:\Us`, []string{"cat"}, `C:\Us`, 4, false},
+		{"ls | grep -i go", []string{"grep", "-i"}, "go", 13, false},
+		{"ls > ou", nil, "ou", 5, true},
+		{"echo a; c", nil, "c", 8, false},
+	}
+	for _, tt := range tests {
+		words, word, start, redirect := completionWords(tt.line)
+		if !reflect.DeepEqual(words, tt.words) || word != tt.word || start != tt.start || redirect != tt.redirect {
+			t.Errorf("completionWords(%q) = %q, %q, %d, %v; want %q, %q, %d, %v",
+				tt.line, words, word, start, redirect, tt.words, tt.word, tt.start, tt.redirect)
+		}
+	}
+}
+
+func TestCompletePaths(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"my file.txt", "notes.txt", "sub/x.txt"}
+	// Elsewhere a backslash is part of a name, which must complete as
+	// typed and without quotes, as on Windows a path would.
+	sep := "/"
+	if runtime.GOOS == "windows" {
+		sep = `\`
+	} else {
+		names = append(names, `C:\Users`)
+	}
+	for _, n := range names {
+		p := filepath.Join(dir, filepath.FromSlash(n))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e := newTestEngine(t)
+	e.Execute("cd '" + dir + "'")
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"cat no", []string{"notes.txt"}},
+		{`cat my\ f`, []string{"'my file.txt'"}},
+		{"cat 'my", []string{"'my file.txt'"}},
+		{"cd s", []string{"sub/"}},
+		{"cat sub" + sep + "x", []string{"sub" + sep + "x.txt"}},
+	}
+	if runtime.GOOS != "windows" {
+		tests = append(tests, struct {
+			line string
+			want []string
+		}{`cat C:\U`, []string{`C:\Users`}})
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, it := range e.Complete(context.Background(), tt.line).Items {
+			got = append(got, it.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
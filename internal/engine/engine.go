@@ -12,20 +12,56 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/0xrootAnon/0xRootShell/internal/commands"
 	"github.com/0xrootAnon/0xRootShell/internal/store"
 )
 
+// Engine is one shell session. Its working directory and environment are
+// its own; commands see them through the Env attached to their context,
+// so the process working directory is never changed.
 type Engine struct {
-	store   *store.Store
-	cwd     string
-	MsgChan chan string
+	store    *store.Store
+	MsgChan  chan string
+	registry *commands.Registry
+	jobs     *jobTable
+	journal  *commands.Journal
+
+	// Interactive sessions ask before running risky commands, and let
+	// commands ask questions through Prompts; others refuse risky
+	// commands unless given --confirm. policyErr is why the policy
+	// file could not be used, if it could not.
+	Interactive bool
+	policy      *commands.Policy
+	policyErr   error
+	prompts     chan *PromptRequest
+
+	// closed is closed by Close, so whoever waits on MsgChan or Prompts
+	// can stop.
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// mu guards the state below, which the UI reads and changes while a
+	// command runs.
+	mu       sync.Mutex
+	cwd      string
+	environ  []string
+	cancel   context.CancelCauseFunc
+	fgCtx    context.Context
+	exiting  bool
+	exitCode int
+	lastCode int
+	dryRun   bool
+	vars     map[string]string
+	funcs    map[string][]stmt
 }
 
 func sanitizeForUI(s string) string {
@@ -39,330 +75,343 @@ func NewEngine(s *store.Store, ch chan string) *Engine {
 	if err != nil {
 		wd = "."
 	}
-	return &Engine{store: s, cwd: wd, MsgChan: ch}
+	e := &Engine{store: s, cwd: wd, environ: os.Environ(), MsgChan: ch, registry: commands.Default.Clone(), jobs: newJobTable(),
+		journal: commands.NewJournal(s), vars: map[string]string{}, funcs: map[string][]stmt{}, prompts: make(chan *PromptRequest),
+		closed: make(chan struct{})}
+	e.policy, e.policyErr = commands.LoadPolicy(policyFile)
+	e.cwd = e.policy.StartDir(wd)
+	e.registerBuiltins()
+	return e
 }
 
-func (e *Engine) Execute(raw string) string {
-	raw = strings.TrimSpace(raw)
-	if raw == "" {
-		return ""
+// Registry exposes the commands this engine can dispatch.
+func (e *Engine) Registry() *commands.Registry {
+	return e.registry
+}
+
+func (e *Engine) registerBuiltins() {
+	builtins := []commands.Command{
+		{
+			Name:     "cd",
+			Usage:    "cd <dir>",
+			Summary:  "Change current directory",
+			Examples: []string{"cd ~/projects", "cd .."},
+			Run:      e.CmdCd,
+		},
+		{
+			Name:    "pwd",
+			Usage:   "pwd",
+			Summary: "Print current directory",
+			Run:     func(ctx context.Context, args []string) commands.Result { return e.CmdPwd() },
+		},
+		{
+			Name:     "help",
+			Usage:    "help [command]",
+			Summary:  "Show this help",
+			Examples: []string{"help", "help find"},
+			Run:      e.CmdHelp,
+			Complete: e.completeHelp,
+		},
+		{
+			Name:     "history",
+			Usage:    "history [count]",
+			Summary:  "Show command history, shared by every session; !! reruns the last command, !n command n",
+			Examples: []string{"history", "history 100", "!!", "!42", "!-2"},
+			Run:      e.CmdHistory,
+		},
+		{
+			Name:    "jobs",
+			Usage:   "jobs",
+			Summary: "List background jobs",
+			Run:     e.CmdJobs,
+		},
+		{
+			Name:     "fg",
+			Usage:    "fg [id]",
+			Summary:  "Show the output of a background job",
+			Examples: []string{"fg", "fg 2"},
+			Run:      e.CmdFg,
+			Complete: e.completeJobs,
+		},
+		{
+			Name:     "cancel",
+			Usage:    "cancel <id>",
+			Summary:  "Stop a background job",
+			Examples: []string{"cancel 2"},
+			Run:      e.CmdCancel,
+			Complete: e.completeJobs,
+		},
+		{
+			Name:     "set",
+			Usage:    "set [name=value | dryrun on|off | scrollback <lines> | colors auto|16|256|truecolor|none | anim on|off|speed <n>|lines <n>|boot on|off | status <segments>|off]",
+			Summary:  "Set a variable, or list them; preview file changes for the session; or change a saved option",
+			Examples: []string{"set dir=~/logs", "ls $dir", "set dryrun on", "set scrollback 10000", "set colors 256", "set anim speed 200", "set anim off", "set status cwd jobs focus", "set status off"},
+			Run:      e.CmdSet,
+			Complete: e.completeSet,
+		},
+		{
+			Name:     "export",
+			Usage:    "export [name=value]...",
+			Summary:  "Set environment variables for programs started from here",
+			Examples: []string{"export EDITOR=vim", "export dir"},
+			Run:      e.CmdExport,
+		},
+		{
+			Name:     "unset",
+			Usage:    "unset <name>...",
+			Summary:  "Remove variables or functions",
+			Run:      e.CmdUnset,
+			Complete: e.completeVars,
+		},
+		{
+			Name:     "run",
+			Aliases:  []string{"source"},
+			Usage:    "run <file.rsh> [args...]",
+			Summary:  "Run a rootshell script",
+			Examples: []string{"run cleanup.rsh", "run backup.rsh ~/notes"},
+			Run:      e.CmdRun,
+		},
+		{
+			Name:        "alias",
+			Subcommands: []string{"add", "list", "rm", "export", "import"},
+			Usage:       "alias add|list|rm|export|import",
+			Summary:     "Manage command aliases",
+			Examples:    []string{`alias add gs "grep -i -n"`, `alias add proj "cd ~/projects/$1"`, "alias rm gs", "alias export team.json", "alias import team.json"},
+			Run:         e.CmdAlias,
+			Complete:    e.completeAlias,
+		},
+		{
+			Name:        "audit",
+			Subcommands: []string{"export"},
+			Usage:       "audit [--date d] [--since t] [--until t] [--verb v] [--status s] [--kind k] [--file f] [-n N] | audit export <file.jsonl> [filters]",
+			Summary:     "Show what commands ran and what they changed",
+			Examples:    []string{"audit", "audit --date yesterday --verb del", "audit --file notes.txt", "audit --status failed --since 2h", "audit export audit.jsonl --since 7d"},
+			Run:         e.CmdAudit,
+			Flags:       []string{"--date", "--since", "--until", "--verb", "--status", "--kind", "--file", "-n"},
+			DryRun:      true,
+			Complete:    e.completeAudit,
+		},
+		{
+			Name:        "policy",
+			Subcommands: []string{"show"},
+			Usage:       "policy show",
+			Summary:     "Show the active safety and sandbox rules",
+			Run:         e.CmdPolicy,
+		},
+		{
+			Name:     "exit",
+			Aliases:  []string{"quit"},
+			Usage:    "exit [code]",
+			Summary:  "Quit 0xRootShell",
+			Examples: []string{"exit", "exit 1"},
+			Run:      e.CmdExit,
+		},
 	}
-	parts := splitArgs(raw)
-	verb := strings.ToLower(parts[0])
-	args := parts[1:]
-
-	switch verb {
-	case "cd":
-		return e.CmdCd(args)
-	case "pwd":
-		return e.CmdPwd()
-	case "launch", "openapp", "start":
-		return commands.CmdLaunch(args)
-	case "clear":
-		if len(args) >= 2 && strings.ToLower(args[0]) == "browser" && strings.ToLower(args[1]) == "history" {
-			return commands.CmdClearBrowserHistory(args[2:])
-		}
-		return "clear: unknown target. Try 'clear browser history' or use your terminal to clear the screen."
-	case "browse":
-		if len(args) > 0 && strings.ToLower(args[0]) == "private" {
-			return commands.CmdBrowsePrivate(args[1:])
-		}
-		return "browse: unknown target. Try 'browse private'."
-	case "open":
-		return commands.CmdOpen(args)
-	case "find", "searchfile":
-		if e.MsgChan != nil {
-			qargs := append([]string(nil), args...)
-			go func(a []string) {
-				query := strings.Join(a, " ")
-				if query == "" {
-					query = "(empty)"
-				}
-				e.MsgChan <- sanitizeForUI(fmt.Sprintf("Searching for: %s", query))
-				res := commands.CmdFind(a)
-				e.MsgChan <- sanitizeForUI(fmt.Sprintf("=== Search results for: %s ===\n%s\n=== End results ===", query, res))
-			}(qargs)
-			return "Searching... results will appear below when ready."
-		}
-		return commands.CmdFind(args)
-	case "sys":
-		if len(args) == 0 {
-			return "sys: expected subcommand. Try 'sys status' or 'sys perf'"
-		}
-		sub := strings.ToLower(args[0])
-		switch sub {
-		case "status":
-			return commands.CmdSysStatus()
-		case "perf":
-			return commands.CmdSysPerf()
-		case "lock":
-			return commands.SysLock()
-		case "sleep":
-			return commands.SysSleep()
-		case "off", "shutdown":
-			for _, a := range args {
-				if a == "--confirm" || a == "-y" {
-					return commands.SysShutdown()
-				}
-			}
-			return "sys off: destructive action. append --confirm to actually shutdown."
-		case "bootlog":
-			return commands.SysBootLog()
-		case "update":
-			return "sys update: use OS update tool (Windows Settings / apt / dnf / etc.)"
-		default:
-			return "sys: unknown subcommand"
-		}
-	case "audio", "vol":
-		return commands.CmdAudio(args)
-	case "display", "brightness", "screen":
-		return commands.CmdDisplay(args)
-	case "net", "network":
-		return commands.CmdNet(args)
-	case "scan":
-		if e.MsgChan != nil {
-			go commands.StartScan(args, e.MsgChan)
-			return "Scan started... results will appear below."
-		}
-		return commands.CmdScan(args)
-	case "create":
-		if len(args) > 0 {
-			t := strings.ToLower(args[0])
-			if t == "folder" || t == "directory" || t == "dir" {
-				return commands.CmdMkdir(args[1:])
-			}
-			return commands.CmdMkdir(args)
-		}
-		return commands.CmdMkdir(args)
-	case "touch":
-		return commands.CmdTouch(args)
-	case "new":
-		if len(args) == 0 {
-			return commands.CmdMkdir(args)
-		}
-		first := strings.ToLower(args[0])
-		if first == "file" || first == "document" {
-			return commands.CmdTouch(args[1:])
-		}
-		if first == "folder" || first == "directory" || first == "dir" {
-			return commands.CmdMkdir(args[1:])
+	for _, c := range builtins {
+		if err := e.registry.Register(c); err != nil {
+			panic(err)
 		}
+	}
+}
 
-		if len(args) > 0 {
-			if ext := filepath.Ext(args[0]); ext != "" {
-				return commands.CmdTouch(args)
-			}
-		}
+func (e *Engine) Execute(raw string) commands.Result {
+	if strings.TrimSpace(raw) == "" {
+		return commands.OK("")
+	}
+	ctx, done := e.begin()
+	defer done()
+	return e.execute(ctx, raw)
+}
 
-		return commands.CmdMkdir(args)
-	case "remove", "delete":
-		if len(args) > 0 {
-			t := strings.ToLower(args[0])
-			if t == "folder" || t == "directory" || t == "dir" {
-				return commands.CmdRmdir(args[1:])
-			}
-			if t == "file" {
-				return commands.CmdDel(args[1:])
-			}
-			return commands.CmdDel(args)
-		}
-		return "remove: usage examples: 'remove folder <name>' or 'remove <file>'"
-
-	case "mkdir":
-		return commands.CmdMkdir(args)
-	case "rmdir":
-		return commands.CmdRmdir(args)
-	case "del", "deletefile":
-		return commands.CmdDel(args)
-	case "rm":
-		return commands.CmdRm(args)
-	case "copy", "cp":
-		return commands.CmdCp(args)
-	case "move", "mv":
-		return commands.CmdMv(args)
-	case "view", "read", "openfile":
-		return commands.CmdCat(args)
-	case "cat":
-		return commands.CmdCat(args)
-	case "search-in", "searchinside", "findin":
-		return commands.CmdGrep(args)
-	case "grep":
-		return commands.CmdGrep(args)
-	case "tasks", "processes":
-		return commands.CmdTasklist(args)
-	case "tasklist":
-		return commands.CmdTasklist(args)
-	case "kill", "end", "terminate", "stop":
-		return commands.CmdTaskkill(args)
-	case "taskkill":
-		return commands.CmdTaskkill(args)
-	case "drives", "volumes", "disk", "disks":
-		return commands.CmdGetVolume(args)
-	case "get-volume", "wmic":
-		return commands.CmdGetVolume(args)
-	case "save":
-		if len(args) > 0 && (strings.ToLower(args[0]) == "file" || strings.HasPrefix(args[0], ".") || filepath.Ext(args[0]) != "") {
-			return commands.CmdTouch(args[1:])
+// begin makes a new foreground context that Interrupt cancels. done must
+// be called when the foreground work ends.
+func (e *Engine) begin() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	e.mu.Lock()
+	e.fgCtx, e.cancel = ctx, cancel
+	e.mu.Unlock()
+	return ctx, func() {
+		e.mu.Lock()
+		if e.fgCtx == ctx {
+			e.fgCtx, e.cancel = nil, nil
 		}
-		return "save: try 'save file <name>'"
-	case "file", "files":
-		return commands.CmdFile(args)
-	case "compress", "zip", "extract":
-		return commands.CmdCompressArchive(append([]string{verb}, args...))
-	case "screenshot":
-		return commands.CmdScreenshot(args)
-	case "search", "web":
-		return commands.CmdSearch(args)
-	case "show":
-		if len(args) > 0 && strings.ToLower(args[0]) == "notifications" {
-			return commands.CmdShowNotifications()
-		}
-		return "show: unknown target. Try 'show notifications'"
-	case "remind":
-		return commands.CmdRemind(args)
-	case "goal":
-		return commands.CmdGoal(args)
-	case "focus":
-		if e.MsgChan != nil {
-			qargs := append([]string(nil), args...)
-			go commands.StartFocus(qargs, e.MsgChan)
-			return "Focus started..."
-		}
-		return commands.CmdFocus(args)
-	case "help":
-		return helpText()
-	case "history":
-		h, _ := e.store.ListHistory(30)
-		return strings.Join(h, "\n")
-	case "weather":
-		return commands.CmdWeather(args)
-	case "convert", "currency":
-		return commands.CmdConvert(args)
-	case "news":
-		return commands.CmdNews(args)
-	case "message", "msg":
-		return commands.CmdMessage(args)
-	case "mail":
-		return commands.CmdMail(args)
-	case "notify":
-		return commands.CmdNotify(args)
-	case "play":
-		return commands.CmdPlay(args)
-	case "pause", "next", "prev":
-		return commands.CmdMediaControl(append([]string{verb}, args...))
-	case "alarm", "timer":
-		if e.MsgChan != nil {
-			go commands.ScheduleTimer(args, e.MsgChan)
-			return "Timer scheduled."
-		}
-		return "Timer not scheduled: no message channel."
-	case "speedtest":
-		if e.MsgChan != nil {
-			qargs := append([]string(nil), args...)
-			go func(a []string) {
-				e.MsgChan <- sanitizeForUI("Starting speedtest...")
-				commands.CmdSpeedtestStream(a, e.MsgChan)
-				e.MsgChan <- sanitizeForUI("Speedtest finished.")
-			}(qargs)
-			return "Running speedtest... results will appear below."
-		}
-		return commands.CmdSpeedtest(args)
-	case "ls":
-		return commands.CmdLS(args)
-	case "calc":
-		return commands.CmdCalc(args)
-	default:
-		return "Unknown command. Try 'help'."
+		e.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// execute parses and runs one line.
+func (e *Engine) execute(ctx context.Context, raw string) commands.Result {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return commands.OK("")
 	}
+	links, err := parseChain(raw)
+	if err != nil {
+		e.setStatus(2)
+		return syntaxError(err)
+	}
+	res := e.runChain(ctx, links)
+	e.setStatus(res.Code)
+	return res
 }
 
-func helpText() string {
-	return `				0xRootShell
-Common commands:
-  sys <lock|sleep|off|bootlog|update|status|perf>
-  cd <dir>                		Change current directory
-  pwd                      		Print current directory
-  launch <app>             		Launch application or URL (aliases: openapp, start)
-  open <file|url>          		Open file or URL
-  clear browser history    		Clear browser history (use: 'clear browser history')
-  browse private <query>   		Private browsing helper (use: 'browse private')
-  find <pattern>           		Fuzzy file search (non-blocking; results appear when ready)
-  scan  	             		Start system scan (non-blocking; results appear when ready) 
-  audio vol <0-100>        		Set volume, audio mute/unmute
-  display bright <0-100>   		Set screen brightness (aliases: display, brightness, screen)
-  net wifi <on|off|list>   		Manage wifi (nmcli / netsh)
-  file <subcmd>            		File operations (aliases: files) — e.g. file move <src> <dst>
-  compress|zip <zip> <src>		Create zip archive
-  extract <zip> <dst>      		Extract zip
-  screenshot               		Save a screenshot to data/screenshots/
-  sys perf                  	Full system performance (CPU/Memory/Disk/Top processes)
-  show notifications       		Show saved notifications
-  search|web <query>       		Open browser with Google search
-  play <youtube|file|url>  		Play media / open URL
-  pause|next|prev          		Media controls (pause / next / prev)
-  remind <text>            		Save a quick reminder (stored locally)
-  history                 		Show command history
-  help                    		Show this help
-  weather <location?>     		Get weather
-  convert|currency <args> 		Currency / unit conversions
-  news <args>             		Fetch latest news
-  notify <args>           		Send a notification
-  alarm|timer <args>      		Schedule alarm/timer (if message channel available)
-  speedtest <args>        		Run internet speedtest (non-blocking; streamed output if available)
-  ls                      		List directory contents
-  calc <expression>       		Calculator
-  goal <args>             		Goal tracking helper
-  focus <args>            		Start focus session (non-blocking; results/updates streamed)
-  create folder <name>     		Create a new folder (alias: mkdir)
-  remove folder <name>     		Delete a folder (alias: rmdir)
-  delete <file>            		Delete a file (alias: del)
-  copy <src> <dst>         		Copy file or folder (alias: cp)
-  move <src> <dst>         		Move or rename file/folder (alias: mv)
-  read <file>         		Display contents of a file (alias: cat)
-  findin <pattern> <file>  	Search inside files (alias: grep)
-  tasks|processes          		Show running processes (alias: tasklist)
-  kill|end <pid|name>      		Terminate a process (alias: taskkill)
-  drives|volumes           		Show connected drives / volumes (alias: get-volume)
-  new file <name>         		Create new file
-  save <filename>				Save file
-`
+// Interrupt cancels the command running in the foreground, if any, and
+// reports whether there was one.
+func (e *Engine) Interrupt() bool {
+	return e.interrupt(nil)
+}
+
+// interrupt is Interrupt with the cause the command sees.
+func (e *Engine) interrupt(cause error) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cancel == nil {
+		return false
+	}
+	e.cancel(cause)
+	return true
 }
 
-func splitArgs(s string) []string {
-	var out []string
-	var cur strings.Builder
-	inQuote := false
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c == '"' {
-			inQuote = !inQuote
-			continue
+// ExitRequested reports whether `exit` has been run, and with which code.
+func (e *Engine) ExitRequested() (int, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.exitCode, e.exiting
+}
+
+func (e *Engine) CmdExit(ctx context.Context, args []string) commands.Result {
+	code := 0
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return commands.Failf("exit: invalid code '%s'", args[0])
 		}
-		if c == ' ' && !inQuote {
-			if cur.Len() > 0 {
-				out = append(out, cur.String())
-				cur.Reset()
+		code = n
+	}
+	e.mu.Lock()
+	e.exiting, e.exitCode = true, code
+	e.mu.Unlock()
+	return commands.OK("")
+}
+
+// dispatch runs cmd, in the background if it is async and the session
+// can show its output later; the job is returned then.
+func (e *Engine) dispatch(ctx context.Context, cmd *commands.Command, line string, args []string) (commands.Result, *Job) {
+	if cmd.Async && e.MsgChan != nil && !isSync(ctx) {
+		qargs := append([]string(nil), args...)
+		j := e.jobs.start(ctx, line, e.MsgChan, func(ctx context.Context, ch chan string) error {
+			return cmd.Start(ctx, qargs, ch)
+		})
+		return commands.OK(fmt.Sprintf("[%d] %s", j.ID, cmd.Pending)), j
+	}
+	return e.runSync(ctx, cmd, args), nil
+}
+
+// runSync runs cmd in the foreground. Async commands without a Run are
+// started and waited for, with everything they write collected as the
+// output.
+func (e *Engine) runSync(ctx context.Context, cmd *commands.Command, args []string) commands.Result {
+	if !cmd.Async || cmd.Run != nil {
+		return cmd.Run(ctx, args)
+	}
+	ch := make(chan string, 16)
+	var err error
+	go func() {
+		defer close(ch)
+		err = cmd.Start(ctx, args, ch)
+	}()
+	var lines []string
+	for s := range ch {
+		lines = append(lines, sanitizeForUI(s))
+	}
+	if ctx.Err() != nil {
+		return commands.Result{Output: strings.Join(lines, "\n"), Err: ctx.Err(), Code: 130}
+	}
+	if err != nil {
+		lines = append(lines, err.Error())
+		return commands.Fail(strings.Join(lines, "\n"))
+	}
+	return commands.OK(strings.Join(lines, "\n"))
+}
+
+func (e *Engine) CmdHelp(ctx context.Context, args []string) commands.Result {
+	if len(args) > 0 {
+		if _, ok := e.registry.Lookup(args[0]); !ok {
+			res := commands.Fail(e.registry.CommandHelp(args[0]))
+			if fix, ok := commands.Closest(args[0], e.registry.Names()); ok {
+				res.Output += fmt.Sprintf(". Did you mean '%s'?", fix)
+				res.Suggest = "help " + fix
 			}
-			continue
+			return res
 		}
-		cur.WriteByte(c)
+		return commands.OK(e.registry.CommandHelp(args[0]))
 	}
-	if cur.Len() > 0 {
-		out = append(out, cur.String())
+	return commands.OK(e.registry.HelpText() + "\n\n" + syntaxHelp)
+}
+
+const syntaxHelp = `Syntax:
+  cmd1 | cmd2              Feed the output of cmd1 into cmd2
+  cmd > file               Write output to file (>> appends)
+  cmd1 ; cmd2              Run cmd1, then cmd2
+  cmd1 && cmd2             Run cmd2 only if cmd1 succeeded
+  cmd1 || cmd2             Run cmd2 only if cmd1 failed
+  $name  $(cmd)            Insert a variable, or the output of cmd
+  'text'  "text"  \x       Quote literally, quote with $ expansion, or escape
+  ~  *.txt                 Home directory; files matching a pattern
+
+Scripts (run <file>) also support if/else, for ... in and func blocks,
+as in for f in $(find '*.log'); ~/.rootshrc runs at startup. Aliases (alias add) take $1..$9 and $@.`
+
+func (e *Engine) CmdPwd() commands.Result {
+	return commands.OK(e.Cwd())
+}
+
+// Cwd returns the engine's working directory.
+func (e *Engine) Cwd() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.cwd
+}
+
+// env captures what a command started now runs in.
+func (e *Engine) env() *commands.Env {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	env := &commands.Env{Dir: e.cwd, Environ: append([]string(nil), e.environ...), Journal: e.journal, DryRun: e.dryRun, Policy: e.policy}
+	env.Audit = func(kind, detail string) { e.audit(env.Line, kind, detail) }
+	env.Ask = func(ctx context.Context, p commands.Prompt) (string, error) { return e.ask(ctx, env.Line, p) }
+	if e.MsgChan != nil {
+		env.Stdout = e.MsgChan
 	}
-	return out
+	return env
 }
 
-func (e *Engine) CmdPwd() string {
-	return filepath.Clean(e.cwd)
+func (e *Engine) getenv(name string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return (&commands.Env{Environ: e.environ}).Getenv(name)
 }
 
-func (e *Engine) CmdCd(args []string) string {
+func (e *Engine) setenv(name, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	prefix := name + "="
+	for i, kv := range e.environ {
+		if strings.HasPrefix(kv, prefix) {
+			e.environ[i] = prefix + value
+			return
+		}
+	}
+	e.environ = append(e.environ, prefix+value)
+}
+
+func (e *Engine) CmdCd(ctx context.Context, args []string) commands.Result {
 	target := ""
 	if len(args) == 0 || args[0] == "" {
 		home, err := os.UserHomeDir()
 		if err != nil {
-			return "cd: cannot determine home directory"
+			return commands.Fail("cd: cannot determine home directory")
 		}
 		target = home
 	} else {
@@ -372,7 +421,7 @@ func (e *Engine) CmdCd(args []string) string {
 	if strings.HasPrefix(target, "~") {
 		home, err := os.UserHomeDir()
 		if err != nil {
-			return "cd: cannot determine home directory"
+			return commands.Fail("cd: cannot determine home directory")
 		}
 		if target == "~" {
 			target = home
@@ -382,23 +431,25 @@ func (e *Engine) CmdCd(args []string) string {
 	}
 
 	if !filepath.IsAbs(target) {
-		target = filepath.Join(e.cwd, target)
+		target = filepath.Join(e.Cwd(), target)
 	}
 
 	target = filepath.Clean(target)
+	if err := e.policy.CheckRoots(target); err != nil {
+		e.audit("cd "+quoteArgs(args), "sandbox", err.Error())
+		return commands.Fail("cd: " + err.Error())
+	}
 
 	info, err := os.Stat(target)
 	if err != nil {
-		return fmt.Sprintf("cd: %s: %v", target, err)
+		return commands.Failf("cd: %s: %v", target, err)
 	}
 	if !info.IsDir() {
-		return fmt.Sprintf("cd: %s: not a directory", target)
-	}
-
-	if err := os.Chdir(target); err != nil {
-		return fmt.Sprintf("cd: failed to change directory: %v", err)
+		return commands.Failf("cd: %s: not a directory", target)
 	}
 
+	e.mu.Lock()
 	e.cwd = target
-	return ""
+	e.mu.Unlock()
+	return commands.OK("")
 }
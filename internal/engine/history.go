@@ -0,0 +1,167 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+	"github.com/0xrootAnon/0xRootShell/internal/store"
+)
+
+func (e *Engine) CmdHistory(ctx context.Context, args []string) commands.Result {
+	n := 30
+	if len(args) > 0 {
+		v, err := strconv.Atoi(args[0])
+		if err != nil || v <= 0 {
+			return commands.Failf("history: invalid count '%s'", args[0])
+		}
+		n = v
+	}
+	h, err := e.store.History(0)
+	if err != nil {
+		return commands.Fail("history: " + err.Error())
+	}
+	first := max(len(h)-n, 0)
+	lines := make([]string, 0, len(h)-first)
+	for i := first; i < len(h); i++ {
+		lines = append(lines, fmt.Sprintf("%5d  %s  %s", h[i].Seq, h[i].Timestamp.Local().Format("2006-01-02 15:04:05"), h[i].Cmd))
+	}
+	return commands.OK(strings.Join(lines, "\n"))
+}
+
+// ExpandHistory replaces !! with the previous command, !n with command n
+// as numbered by `history`, and !-n with the nth latest one. A ! inside
+// single quotes or after a backslash is left alone. It reports whether
+// anything was replaced, so the caller can show the line that runs.
+func (e *Engine) ExpandHistory(line string) (string, bool, error) {
+	if !strings.Contains(line, "!") {
+		return line, false, nil
+	}
+	var h []store.HistoryEntry
+	event := func(n int) (string, bool) {
+		if h == nil {
+			h, _ = e.store.History(0)
+		}
+		if n < 0 {
+			if -n > len(h) {
+				return "", false
+			}
+			return h[len(h)+n].Cmd, true
+		}
+		for _, en := range h {
+			if en.Seq == uint64(n) {
+				return en.Cmd, true
+			}
+		}
+		return "", false
+	}
+	sb := &strings.Builder{}
+	changed, quoted := false, false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '\\' && !quoted && i+1 < len(line):
+			sb.WriteByte(c)
+			i++
+			c = line[i]
+		case c == '!' && !quoted && i+1 < len(line):
+			j := i + 1
+			n := -1
+			if line[j] == '!' {
+				j++
+			} else {
+				if line[j] == '-' {
+					j++
+				}
+				k := j
+				for k < len(line) && line[k] >= '0' && line[k] <= '9' {
+					k++
+				}
+				if k == j {
+					break
+				}
+				n, _ = strconv.Atoi(line[j:k])
+				if line[i+1] == '-' {
+					n = -n
+				}
+				j = k
+			}
+			cmd, ok := event(n)
+			if !ok {
+				return line, false, fmt.Errorf("%s: event not found", line[i:j])
+			}
+			sb.WriteString(cmd)
+			changed = true
+			i = j - 1
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), changed, nil
+}
+
+// Redact returns line as it may be saved to the history or the audit
+// trail, with the arguments a command marks Secret, such as a password,
+// replaced by '***'. A line with nothing to hide is returned as typed.
+func (e *Engine) Redact(line string) string {
+	toks, err := lexLine(line, nil)
+	if err != nil {
+		return line
+	}
+	redacted := false
+	var out []string
+	// stage holds the words of one command, and redir its redirection,
+	// which is not one of its arguments.
+	var stage, redir []string
+	flush := func() {
+		if len(stage) > 0 {
+			if cmd, ok := e.registry.Lookup(stage[0]); ok && cmd.Secret != nil {
+				for _, i := range cmd.Secret(stage[1:]) {
+					if i >= 0 && i+1 < len(stage) {
+						stage[i+1] = "***"
+						redacted = true
+					}
+				}
+			}
+			out = append(out, quoteArgs(stage))
+		}
+		out = append(out, redir...)
+		stage, redir = nil, nil
+	}
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch {
+		case !t.op:
+			stage = append(stage, t.text)
+		case t.text == ">" || t.text == ">>":
+			redir = append(redir, t.text)
+			if i+1 < len(toks) && !toks[i+1].op {
+				i++
+				redir = append(redir, quoteArgs([]string{toks[i].text}))
+			}
+		default:
+			flush()
+			out = append(out, t.text)
+		}
+	}
+	flush()
+	if !redacted {
+		return line
+	}
+	return strings.Join(out, " ")
+}
@@ -0,0 +1,57 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import "testing"
+
+func TestExpandHistory(t *testing.T) {
+	e := newTestEngine(t)
+	for _, cmd := range []string{"echo one", "echo two", "echo three"} {
+		if err := e.store.SaveHistory(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		line    string
+		want    string
+		changed bool
+		err     string
+	}{
+		{"!!", "echo three", true, ""},
+		{"!! | upper", "echo three | upper", true, ""},
+		{"!1", "echo one", true, ""},
+		{"!2 && !3", "echo two && echo three", true, ""},
+		{"!-1", "echo three", true, ""},
+		{"!-3", "echo one", true, ""},
+		{"!9", "", false, "!9: event not found"},
+		{"!-4", "", false, "!-4: event not found"},
+		{"echo 'hi!!'", "echo 'hi!!'", false, ""},
+		{"echo 'a' !!", "echo 'a' echo three", true, ""},
+		{`echo \!!`, `echo \!!`, false, ""},
+		{"echo hi!", "echo hi!", false, ""},
+		{"echo !- x", "echo !- x", false, ""},
+		{"echo !x", "echo !x", false, ""},
+		{"echo plain", "echo plain", false, ""},
+	}
+	for _, tt := range tests {
+		got, changed, err := e.ExpandHistory(tt.line)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ExpandHistory(%q) error = %v, want %q", tt.line, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want || changed != tt.changed {
+			t.Errorf("ExpandHistory(%q) = %q, %v, %v; want %q, %v", tt.line, got, changed, err, tt.want, tt.changed)
+		}
+	}
+}
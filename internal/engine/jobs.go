@@ -0,0 +1,346 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+type JobState int
+
+const (
+	JobRunning JobState = iota
+	JobDone
+	JobFailed
+	JobCancelled
+)
+
+func (s JobState) String() string {
+	switch s {
+	case JobRunning:
+		return "running"
+	case JobDone:
+		return "done"
+	case JobFailed:
+		return "failed"
+	case JobCancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// jobOutputLimit caps the lines kept per job for `fg`, and jobKeep the
+// finished jobs kept for `jobs` and `fg`; older ones are dropped.
+const (
+	jobOutputLimit = 500
+	jobKeep        = 50
+)
+
+// Job is a background command started by the engine.
+type Job struct {
+	ID      int
+	Line    string
+	Started time.Time
+
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	state  JobState
+	ended  time.Time
+	output []string
+	size   int
+}
+
+func (j *Job) State() JobState {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.state
+}
+
+// Elapsed is the run time so far, or the total run time once finished.
+func (j *Job) Elapsed() time.Duration {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.state == JobRunning {
+		return time.Since(j.Started)
+	}
+	return j.ended.Sub(j.Started)
+}
+
+// Output returns the lines the job has produced so far.
+func (j *Job) Output() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]string(nil), j.output...)
+}
+
+// OutputSize is the number of bytes the job has printed, including
+// lines no longer kept for fg.
+func (j *Job) OutputSize() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.size
+}
+
+// Cancel asks the job to stop; Done is closed once it has.
+func (j *Job) Cancel() {
+	j.cancel(nil)
+}
+
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+func (j *Job) record(s string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.size += len(s)
+	j.output = append(j.output, strings.Split(s, "\n")...)
+	if n := len(j.output) - jobOutputLimit; n > 0 {
+		j.output = j.output[n:]
+	}
+}
+
+func (j *Job) finish(cancelled bool, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.ended = time.Now()
+	switch {
+	case cancelled:
+		j.state = JobCancelled
+	case err != nil:
+		j.state = JobFailed
+	default:
+		j.state = JobDone
+	}
+}
+
+// shownError is a job error whose message fn has already written to its
+// channel, so start does not write it again.
+type shownError struct{ error }
+
+type jobTable struct {
+	mu   sync.Mutex
+	next int
+	jobs map[int]*Job
+}
+
+func newJobTable() *jobTable {
+	return &jobTable{next: 1, jobs: map[int]*Job{}}
+}
+
+// start runs fn in the background as a new job. Every line fn writes is
+// recorded on the job and forwarded to out prefixed with the job ID; a
+// final line reports how the job ended. fn's context keeps the values of
+// parent, including its Env, but only the job's own cancellation.
+func (t *jobTable) start(parent context.Context, line string, out chan string, fn func(ctx context.Context, ch chan string) error) *Job {
+	ctx, cancel := context.WithCancelCause(context.WithoutCancel(parent))
+	t.mu.Lock()
+	j := &Job{ID: t.next, Line: line, Started: time.Now(), cancel: cancel, done: make(chan struct{})}
+	t.jobs[j.ID] = j
+	t.next++
+	t.prune()
+	t.mu.Unlock()
+
+	ch := make(chan string, 16)
+	var err error
+	go func() {
+		defer close(ch)
+		if err = fn(ctx, ch); err != nil && ctx.Err() == nil && !errors.As(err, new(shownError)) {
+			ch <- err.Error()
+		}
+	}()
+	go func() {
+		for s := range ch {
+			j.record(s)
+			out <- prefixLines(fmt.Sprintf("[%d] ", j.ID), sanitizeForUI(s))
+		}
+		j.finish(ctx.Err() != nil, err)
+		cancel(nil)
+		out <- fmt.Sprintf("[%d] %s: %s (%s)", j.ID, j.State(), j.Line, formatElapsed(j.Elapsed()))
+		close(j.done)
+	}()
+	return j
+}
+
+// prune drops the oldest finished jobs beyond jobKeep. t.mu must be
+// held.
+func (t *jobTable) prune() {
+	var ended []int
+	for id, j := range t.jobs {
+		if j.State() != JobRunning {
+			ended = append(ended, id)
+		}
+	}
+	if len(ended) <= jobKeep {
+		return
+	}
+	sort.Ints(ended)
+	for _, id := range ended[:len(ended)-jobKeep] {
+		delete(t.jobs, id)
+	}
+}
+
+func (t *jobTable) get(id int) (*Job, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	j, ok := t.jobs[id]
+	return j, ok
+}
+
+// list returns all jobs ordered by ID.
+func (t *jobTable) list() []*Job {
+	t.mu.Lock()
+	out := make([]*Job, 0, len(t.jobs))
+	for _, j := range t.jobs {
+		out = append(out, j)
+	}
+	t.mu.Unlock()
+	sort.Slice(out, func(a, b int) bool { return out[a].ID < out[b].ID })
+	return out
+}
+
+func prefixLines(prefix, s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = prefix + l
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatElapsed(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// Close stops everything the session is running, in the foreground and
+// in the background, and closes Closed. What the jobs write until they
+// end is discarded. The commands see commands.ErrSessionClosed as the
+// cause.
+func (e *Engine) Close() {
+	e.closeOnce.Do(func() { close(e.closed) })
+	e.interrupt(commands.ErrSessionClosed)
+	jobs := e.jobs.list()
+	for _, j := range jobs {
+		j.cancel(commands.ErrSessionClosed)
+	}
+	if e.MsgChan == nil {
+		return
+	}
+	go func() {
+		for _, j := range jobs {
+			for done := false; !done; {
+				select {
+				case <-e.MsgChan:
+				case <-j.Done():
+					done = true
+				}
+			}
+		}
+	}()
+}
+
+// Closed is closed once the session is, so that nothing need wait on
+// MsgChan or Prompts after it.
+func (e *Engine) Closed() <-chan struct{} {
+	return e.closed
+}
+
+// Jobs returns the background jobs started in this session.
+func (e *Engine) Jobs() []*Job {
+	return e.jobs.list()
+}
+
+func (e *Engine) jobArg(verb string, args []string) (*Job, commands.Result) {
+	if len(args) == 0 {
+		jobs := e.jobs.list()
+		if len(jobs) == 0 {
+			return nil, commands.Fail(verb + ": no jobs")
+		}
+		return jobs[len(jobs)-1], commands.Result{}
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "%"))
+	if err != nil {
+		return nil, commands.Failf("%s: invalid job id '%s'", verb, args[0])
+	}
+	j, ok := e.jobs.get(id)
+	if !ok {
+		return nil, commands.Failf("%s: no such job %d", verb, id)
+	}
+	return j, commands.Result{}
+}
+
+func (e *Engine) CmdJobs(ctx context.Context, args []string) commands.Result {
+	jobs := e.jobs.list()
+	if len(jobs) == 0 {
+		return commands.OK("No jobs.")
+	}
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "%-4s %-10s %-8s %s\n", "ID", "STATUS", "ELAPSED", "COMMAND")
+	for _, j := range jobs {
+		fmt.Fprintf(sb, "%-4d %-10s %-8s %s\n", j.ID, j.State(), formatElapsed(j.Elapsed()), j.Line)
+	}
+	return commands.OK(strings.TrimRight(sb.String(), "\n"))
+}
+
+// CmdFg shows what a job has printed so far; without an ID it picks the
+// most recent job. A running job is then followed until it ends, and
+// interrupting the foreground cancels it.
+func (e *Engine) CmdFg(ctx context.Context, args []string) commands.Result {
+	j, res := e.jobArg("fg", args)
+	if j == nil {
+		return res
+	}
+	if env := commands.EnvOf(ctx); j.State() == JobRunning && env.Stdout != nil {
+		env.Print(prefixLines(fmt.Sprintf("[%d] ", j.ID), strings.Join(append([]string{j.Line}, j.Output()...), "\n")))
+		select {
+		case <-j.Done():
+		case <-ctx.Done():
+			j.Cancel()
+			<-j.Done()
+		}
+		return commands.OK("")
+	}
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "[%d] %s (%s, %s)", j.ID, j.Line, j.State(), formatElapsed(j.Elapsed()))
+	for _, l := range j.Output() {
+		sb.WriteString("\n" + l)
+	}
+	return commands.OK(sb.String())
+}
+
+func (e *Engine) CmdCancel(ctx context.Context, args []string) commands.Result {
+	if len(args) == 0 {
+		return commands.Fail("cancel: usage: cancel <id>")
+	}
+	j, res := e.jobArg("cancel", args)
+	if j == nil {
+		return res
+	}
+	if j.State() != JobRunning {
+		return commands.Failf("cancel: job %d is already %s", j.ID, j.State())
+	}
+	j.Cancel()
+	return commands.OK(fmt.Sprintf("Cancelling job %d: %s", j.ID, j.Line))
+}
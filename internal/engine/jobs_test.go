@@ -0,0 +1,83 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+// TestJobTablePrune checks that finished jobs beyond jobKeep are dropped,
+// oldest first, and running ones are kept.
+func TestJobTablePrune(t *testing.T) {
+	tbl := newJobTable()
+	out := make(chan string, 1000)
+	block := make(chan struct{})
+	running := tbl.start(context.Background(), "wait", out, func(ctx context.Context, ch chan string) error {
+		<-block
+		return nil
+	})
+	defer close(block)
+	for i := 0; i < jobKeep+10; i++ {
+		j := tbl.start(context.Background(), "quick", out, func(ctx context.Context, ch chan string) error { return nil })
+		<-j.Done()
+	}
+	tbl.start(context.Background(), "quick", out, func(ctx context.Context, ch chan string) error { return nil })
+	jobs := tbl.list()
+	if len(jobs) != jobKeep+2 {
+		t.Fatalf("kept %d jobs, want %d", len(jobs), jobKeep+2)
+	}
+	if jobs[0] != running {
+		t.Errorf("the running job was dropped")
+	}
+	if jobs[1].ID != 12 {
+		t.Errorf("oldest finished job kept is %d, want 12", jobs[1].ID)
+	}
+}
+
+// TestBackgroundPipelineFailureOutput checks that a background pipeline
+// that fails still shows what every stage printed, and its error only once.
+func TestBackgroundPipelineFailureOutput(t *testing.T) {
+	e := newTestEngine(t)
+	out := make(chan string, 100)
+	e.MsgChan = out
+	err := e.registry.Register(commands.Command{
+		Name:  "partial",
+		Async: true,
+		Start: func(ctx context.Context, args []string, ch chan string) error {
+			ch <- "first line"
+			return errors.New("partial: boom")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Execute("partial | echo tail")
+	var lines []string
+	for s := range out {
+		if strings.Contains(s, "failed:") {
+			break
+		}
+		lines = append(lines, s)
+	}
+	got := strings.Join(lines, "\n")
+	if !strings.Contains(got, "first line") || !strings.Contains(got, "] tail") {
+		t.Errorf("output lost: %q", got)
+	}
+	if n := strings.Count(got, "partial: boom"); n != 1 {
+		t.Errorf("error shown %d times: %q", n, got)
+	}
+}
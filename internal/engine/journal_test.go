@@ -0,0 +1,232 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fileTree returns the files under dir with their contents, so a test
+// can compare the tree before and after.
+func fileTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	out := map[string]string{}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || p == dir {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if info.IsDir() {
+			out[rel+"/"] = ""
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(p)
+			out[rel] = "-> " + target
+			return err
+		}
+		b, err := os.ReadFile(p)
+		out[rel] = string(b)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestUndoRoundTrip checks that undo puts the files a command changed
+// back as they were.
+func TestUndoRoundTrip(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"del", "del a.txt sub/b.txt"},
+		{"del dir", "del -r sub --confirm"},
+		{"mv", "mv a.txt renamed.txt"},
+		{"mv into dir", "mv a.txt sub"},
+		{"mv over file", "mv a.txt sub/b.txt"},
+		{"cp", "cp a.txt copy.txt"},
+		{"cp dir", "cp sub newsub"},
+		{"cp over file", "cp a.txt sub/b.txt"},
+		{"overwrite", "echo new > a.txt"},
+		{"append", "echo more >> a.txt"},
+		{"create", "echo new > fresh.txt"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"a.txt": "a\n", "sub/b.txt": "b\n"})
+		before := fileTree(t, dir)
+		e := newTestEngine(t)
+		e.RunScript("cd", "cd '"+dir+"'", nil, nil)
+		if res := e.Execute(tt.line); res.Failed() {
+			t.Errorf("%s: %q failed: %s", tt.name, tt.line, res.Output)
+			continue
+		}
+		if reflect.DeepEqual(fileTree(t, dir), before) {
+			t.Errorf("%s: %q changed nothing", tt.name, tt.line)
+			continue
+		}
+		if res := e.Execute("undo"); res.Failed() {
+			t.Errorf("%s: undo failed: %s", tt.name, res.Output)
+			continue
+		}
+		if got := fileTree(t, dir); !reflect.DeepEqual(got, before) {
+			t.Errorf("%s: after undo the tree is %v, want %v", tt.name, got, before)
+		}
+		if res := e.Execute("undo"); !res.Failed() {
+			t.Errorf("%s: a second undo found something to undo: %s", tt.name, res.Output)
+		}
+	}
+}
+
+// TestUndoStagedGone checks that an operation whose trashed file has
+// been removed since reports the step and stays in the journal, so undo
+// can be retried once the file is back.
+func TestUndoStagedGone(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.txt": "a\n"})
+	e := newTestEngine(t)
+	e.RunScript("cd", "cd '"+dir+"'", nil, nil)
+	if res := e.Execute("del a.txt"); res.Failed() {
+		t.Fatalf("del: %s", res.Output)
+	}
+	ops, err := e.store.Operations(1)
+	if err != nil || len(ops) != 1 {
+		t.Fatalf("journal: %v, %v", ops, err)
+	}
+	staged := ops[0].Steps[0].Staged
+	hidden := filepath.Join(data, "hidden")
+	if err := os.Rename(staged, hidden); err != nil {
+		t.Fatal(err)
+	}
+
+	res := e.Execute("undo")
+	if !res.Failed() || !strings.Contains(res.Output, "undo: delete "+filepath.Join(dir, "a.txt")) {
+		t.Errorf("undo with the trashed file gone: got %q", res.Output)
+	}
+	op, _, err := e.store.Operation(ops[0].ID)
+	if err != nil || op.Undone || len(op.Steps) != 1 {
+		t.Fatalf("after a failed undo the entry is %+v, %v", op, err)
+	}
+
+	if err := os.Rename(hidden, staged); err != nil {
+		t.Fatal(err)
+	}
+	if res := e.Execute("undo"); res.Failed() {
+		t.Fatalf("retried undo: %s", res.Output)
+	}
+	if got := fileTree(t, dir); !reflect.DeepEqual(got, map[string]string{"a.txt": "a\n"}) {
+		t.Errorf("after the retried undo the tree is %v", got)
+	}
+	list := e.Execute("undo list").Output
+	if lines := strings.Split(list, "\n"); len(lines) != 2 || !strings.Contains(lines[1], "undone") {
+		t.Errorf("undo list: %q", list)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(filepath.Dir(filepath.Dir(staged)), "info")); len(entries) != 0 {
+		t.Errorf("%d trash entries left behind", len(entries))
+	}
+}
+
+// TestRedirectTrash checks that redirections keep nothing in the trash:
+// `>>` records only the size to cut back to, and `>` keeps the old
+// contents in the journal's staging area.
+func TestRedirectTrash(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"log.txt": "start\n"})
+	e := newTestEngine(t)
+	e.RunScript("cd", "cd '"+dir+"'", nil, nil)
+	log := filepath.Join(dir, "log.txt")
+	for i := 0; i < 5; i++ {
+		if res := e.Execute("echo x >> log.txt"); res.Failed() {
+			t.Fatalf(">>: %s", res.Output)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(data, "Trash")); !os.IsNotExist(err) {
+		t.Errorf(">> used the trash: %v", fileTree(t, filepath.Join(data, "Trash")))
+	}
+	if _, err := os.Stat(filepath.Join("data", "undo")); !os.IsNotExist(err) {
+		t.Errorf(">> kept a copy: %v", fileTree(t, filepath.Join("data", "undo")))
+	}
+	if res := e.Execute("echo new > log.txt"); res.Failed() {
+		t.Fatalf(">: %s", res.Output)
+	}
+	if _, err := os.Stat(filepath.Join(data, "Trash")); !os.IsNotExist(err) {
+		t.Errorf("> used the trash: %v", fileTree(t, filepath.Join(data, "Trash")))
+	}
+	staged := fileTree(t, filepath.Join("data", "undo"))
+	if len(staged) != 1 {
+		t.Fatalf("staging area after >: %v", staged)
+	}
+	for _, body := range staged {
+		if body != "start\n"+strings.Repeat("x\n", 5) {
+			t.Errorf("staged copy is %q", body)
+		}
+	}
+
+	e.Execute("undo")
+	if len(fileTree(t, filepath.Join("data", "undo"))) != 0 {
+		t.Error("undo left the staged copy behind")
+	}
+	for i := 4; i >= 0; i-- {
+		if res := e.Execute("undo"); res.Failed() || !strings.Contains(res.Output, "Removed what was appended to "+log) {
+			t.Fatalf("undo >>: %s", res.Output)
+		}
+		if b, _ := os.ReadFile(log); string(b) != "start\n"+strings.Repeat("x\n", i) {
+			t.Fatalf("after undoing append %d: %q", i+1, b)
+		}
+	}
+
+	// An append whose file has since become shorter is not cut.
+	e.Execute("echo more >> log.txt")
+	if err := os.WriteFile(log, []byte("s"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if res := e.Execute("undo"); !res.Failed() || !strings.Contains(res.Output, "has changed since") {
+		t.Errorf("undo of a changed append: %q", res.Output)
+	}
+	if b, _ := os.ReadFile(log); string(b) != "s" {
+		t.Errorf("a refused undo changed the file: %q", b)
+	}
+
+	e.Execute("set dryrun on")
+	res := e.Execute("echo more >> log.txt")
+	if !strings.Contains(res.Output, "append to "+log) {
+		t.Errorf("dry run plan: %q", res.Output)
+	}
+	if res := e.Execute("undo --dry-run"); !strings.Contains(res.Output, "truncate") {
+		t.Errorf("dry run of undo: %q", res.Output)
+	}
+}
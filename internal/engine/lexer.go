@@ -0,0 +1,365 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+var (
+	errUnquotedSingle = errors.New(`syntax error: unterminated single quote (write \' for a literal apostrophe)`)
+	errUnquotedDouble = errors.New(`syntax error: unterminated double quote`)
+)
+
+type token struct {
+	text string
+	op   bool
+}
+
+// expander resolves `$name`, `$(command)` and globs while a line is lexed.
+type expander interface {
+	lookup(name string) string
+	positional() []string
+	substitute(cmd string) string
+	dir() string
+}
+
+// special lists what makes the lexer change a word: blanks, quotes,
+// operators, expansions and globs.
+const special = " \t'\"$|&;>*?[]~#(){}"
+
+// needsQuotes reports whether the lexer would change s, or split it, if
+// s were written as a word. A backslash matters only before a character
+// it escapes, which is special anyway, or at the end, where it would
+// escape what follows.
+func needsQuotes(s string) bool {
+	return strings.ContainsAny(s, special) || strings.HasSuffix(s, `\`)
+}
+
+// escapes reports whether a backslash before c escapes it. Outside
+// quotes that is only blanks, quotes, `$` and the operators; inside
+// double quotes only `"`, `\` and `$`. Before anything else, including
+// another backslash or a glob character, an unquoted backslash is kept,
+// so Windows paths such as C:\logs\*.log and \\server\share need no
+// doubling.
+func escapes(c byte, inQuote bool) bool {
+	if inQuote {
+		return strings.IndexByte("\"\\$", c) >= 0
+	}
+	return strings.IndexByte(" \t'\"$|&;>", c) >= 0
+}
+
+// lexLine splits a line into words and the unquoted operators `|`, `||`,
+// `&&`, `;`, `>` and `>>`. A lone `&` is kept as part of a word.
+//
+// Single quotes keep everything literally. Double quotes group words but
+// still expand `$`; inside them a backslash escapes only `"`, `\` and
+// `$`. An unterminated quote is an error.
+//
+// With x set, `$name`, `${name}`, `$1`, `$@`, `$?` and `$(command)` are
+// expanded outside single quotes, and unquoted `$@` and `$(...)` split
+// into one word per argument or line. A variable that is not set in the
+// shell falls back to the environment. An unquoted leading `~` becomes
+// the home directory, and words with unquoted `*`, `?` or `[` are
+// replaced by the matching paths, relative to x.dir(), in sorted order;
+// a pattern that matches nothing is kept as typed.
+func lexLine(s string, x expander) ([]token, error) {
+	l := &lexer{x: x}
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			inQuote = !inQuote
+			l.quoted = true
+			continue
+		case c == '\'' && !inQuote:
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errUnquotedSingle
+			}
+			l.quoted = true
+			l.lit(s[i+1:i+1+end], true)
+			i += end + 1
+			continue
+		case c == '\\' && i+1 < len(s):
+			if escapes(s[i+1], inQuote) {
+				l.lit(s[i+1:i+2], true)
+				i++
+				continue
+			}
+			l.lit(`\`, true)
+			continue
+		case c == '$' && x == nil && i+1 < len(s) && s[i+1] == '(':
+			// Keep `$(...)` as one literal chunk when not expanding.
+			if end := matchParen(s[i+1:]); end >= 0 {
+				l.lit(s[i:i+end+2], true)
+				i += end + 1
+				continue
+			}
+		case c == '$' && x != nil && i+1 < len(s):
+			n, err := l.expandVar(s[i+1:], inQuote)
+			if err != nil {
+				return nil, err
+			}
+			if n > 0 {
+				i += n
+				continue
+			}
+		case c == '~' && x != nil && !inQuote && l.cur.Len() == 0 && !l.quoted && (i+1 == len(s) || strings.IndexByte("/\\ \t|;>", s[i+1]) >= 0):
+			if home, err := os.UserHomeDir(); err == nil {
+				l.lit(home, true)
+				continue
+			}
+		}
+		if inQuote {
+			l.lit(s[i:i+1], true)
+			continue
+		}
+		switch c {
+		case ' ', '\t':
+			l.flush()
+		case '|':
+			l.flush()
+			if i+1 < len(s) && s[i+1] == '|' {
+				l.op("||")
+				i++
+			} else {
+				l.op("|")
+			}
+		case '&':
+			if i+1 < len(s) && s[i+1] == '&' {
+				l.flush()
+				l.op("&&")
+				i++
+			} else {
+				l.lit("&", false)
+			}
+		case ';':
+			l.flush()
+			l.op(";")
+		case '>':
+			l.flush()
+			if i+1 < len(s) && s[i+1] == '>' {
+				l.op(">>")
+				i++
+			} else {
+				l.op(">")
+			}
+		default:
+			l.lit(s[i:i+1], false)
+		}
+	}
+	if inQuote {
+		return nil, errUnquotedDouble
+	}
+	l.flush()
+	return l.out, nil
+}
+
+// lexer holds the word being built. pat is the same word as a glob
+// pattern, with quoted metacharacters escaped.
+type lexer struct {
+	x          expander
+	out        []token
+	cur, pat   strings.Builder
+	quoted     bool // the word had quotes, so it is kept even if empty
+	glob       bool // the word has unquoted metacharacters
+	metaQuoted bool // the word has quoted metacharacters
+}
+
+func (l *lexer) lit(s string, quoted bool) {
+	l.cur.WriteString(s)
+	if !quoted {
+		l.pat.WriteString(s)
+		l.glob = l.glob || strings.ContainsAny(s, "*?[")
+		return
+	}
+	l.metaQuoted = l.metaQuoted || strings.ContainsAny(s, "*?[")
+	if runtime.GOOS == "windows" {
+		// filepath.Match has no escaping on Windows; see flush.
+		l.pat.WriteString(s)
+		return
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`*?[\`, s[i]) >= 0 {
+			l.pat.WriteByte('\\')
+		}
+		l.pat.WriteByte(s[i])
+	}
+}
+
+func (l *lexer) op(s string) {
+	l.out = append(l.out, token{text: s, op: true})
+}
+
+func (l *lexer) flush() {
+	if l.cur.Len() > 0 || l.quoted {
+		word := l.cur.String()
+		matches := []string(nil)
+		if l.glob && l.x != nil && !(l.metaQuoted && runtime.GOOS == "windows") {
+			matches = globIn(l.x.dir(), l.pat.String())
+		}
+		if len(matches) == 0 {
+			matches = []string{word}
+		}
+		for _, m := range matches {
+			l.out = append(l.out, token{text: m})
+		}
+	}
+	l.cur.Reset()
+	l.pat.Reset()
+	l.quoted, l.glob, l.metaQuoted = false, false, false
+}
+
+// fields adds expanded text as separate words, the first one joined to
+// the word in progress.
+func (l *lexer) fields(fs []string) {
+	for i, f := range fs {
+		if i > 0 {
+			l.flush()
+		}
+		l.lit(f, true)
+	}
+}
+
+// globIn expands pattern against dir. Relative patterns give relative
+// matches, as typed.
+func globIn(dir, pattern string) []string {
+	abs := pattern
+	if !filepath.IsAbs(pattern) {
+		abs = filepath.Join(dir, pattern)
+	}
+	matches, err := filepath.Glob(abs)
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+	if !filepath.IsAbs(pattern) {
+		for i, m := range matches {
+			if rel, err := filepath.Rel(dir, m); err == nil {
+				matches[i] = rel
+			}
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// expandVar expands the reference at the start of s (just after a `$`)
+// and returns how many bytes it used; 0 means the `$` is literal.
+func (l *lexer) expandVar(s string, inQuote bool) (int, error) {
+	x := l.x
+	switch c := s[0]; {
+	case c == '(':
+		end := matchParen(s)
+		if end < 0 {
+			return 0, errors.New("syntax error: unterminated '$('")
+		}
+		out := strings.TrimRight(x.substitute(s[1:end]), "\n")
+		if inQuote {
+			l.lit(out, true)
+		} else {
+			var fields []string
+			for _, line := range strings.Split(out, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					fields = append(fields, line)
+				}
+			}
+			l.fields(fields)
+		}
+		return end + 1, nil
+	case c == '{':
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return 0, errors.New("syntax error: unterminated '${'")
+		}
+		l.lit(x.lookup(s[1:end]), true)
+		return end + 1, nil
+	case c == '@':
+		if inQuote {
+			l.lit(strings.Join(x.positional(), " "), true)
+		} else {
+			l.fields(x.positional())
+		}
+		return 1, nil
+	case c == '?' || c == '#' || (c >= '0' && c <= '9'):
+		l.lit(x.lookup(s[:1]), true)
+		return 1, nil
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		n := 1
+		for n < len(s) && isNameByte(s[n]) {
+			n++
+		}
+		l.lit(x.lookup(s[:n]), true)
+		return n, nil
+	}
+	return 0, nil
+}
+
+// skipQuoted returns the index of the last byte of the quoted string or
+// escape starting at s[i], which must be a quote or a backslash.
+func skipQuoted(s string, i int) (int, error) {
+	switch s[i] {
+	case '\\':
+		if i+1 < len(s) && escapes(s[i+1], false) {
+			return i + 1, nil
+		}
+		return i, nil
+	case '\'':
+		end := strings.IndexByte(s[i+1:], '\'')
+		if end < 0 {
+			return 0, errUnquotedSingle
+		}
+		return i + 1 + end, nil
+	}
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '"':
+			return j, nil
+		}
+	}
+	return 0, errUnquotedDouble
+}
+
+// matchParen returns the index of the `)` closing the `(` at s[0], or -1.
+func matchParen(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'', '\\':
+			j, err := skipQuoted(s, i)
+			if err != nil {
+				return -1
+			}
+			i = j
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,227 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// fakeScope is an expander with fixed variables and command output.
+type fakeScope struct {
+	vars map[string]string
+	args []string
+	subs map[string]string
+	wd   string
+}
+
+func (f fakeScope) lookup(name string) string { return f.vars[name] }
+func (f fakeScope) positional() []string      { return f.args }
+func (f fakeScope) substitute(cmd string) string {
+	return f.subs[cmd]
+}
+func (f fakeScope) dir() string { return f.wd }
+
+// words renders tokens with operators marked, so tables can compare them.
+func words(toks []token) []string {
+	out := []string{}
+	for _, t := range toks {
+		if t.op {
+			out = append(out, "<"+t.text+">")
+		} else {
+			out = append(out, t.text)
+		}
+	}
+	return out
+}
+
+func TestLexLine(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{`echo hello world`, []string{"echo", "hello", "world"}},
+		{`  echo   spaced	out  `, []string{"echo", "spaced", "out"}},
+		{`echo 'a b' "c d"`, []string{"echo", "a b", "c d"}},
+		{`echo 'it''s'`, []string{"echo", "its"}},
+		{`echo it\'s`, []string{"echo", "it's"}},
+		{`echo a\ b`, []string{"echo", "a b"}},
+		{`echo "say \"hi\""`, []string{"echo", `say "hi"`}},
+		{`echo "back\\slash"`, []string{"echo", `back\slash`}},
+		{`echo "keep \n"`, []string{"echo", `keep \n`}},
+		{`echo '$x | y'`, []string{"echo", "$x | y"}},
+		{`echo ''`, []string{"echo", ""}},
+		{`echo ""`, []string{"echo", ""}},
+		{`open C:\Users\me`, []string{"open", `C:\Users\me`}},
+		{`del C:\logs\*.log`, []string{"del", `C:\logs\*.log`}},
+		{`open \\server\share`, []string{"open", `\\server\share`}},
+		{`cd C:\~tmp\[old]`, []string{"cd", `C:\~tmp\[old]`}},
+		{`echo a\\ b`, []string{"echo", `a\ b`}},
+		{`echo \|`, []string{"echo", "|"}},
+		{`a | b`, []string{"a", "<|>", "b"}},
+		{`a|b`, []string{"a", "<|>", "b"}},
+		{`a || b && c ; d`, []string{"a", "<||>", "b", "<&&>", "c", "<;>", "d"}},
+		{`a > out.txt`, []string{"a", "<>>", "out.txt"}},
+		{`a >> out.txt`, []string{"a", "<>>>", "out.txt"}},
+		{`a>>out.txt`, []string{"a", "<>>>", "out.txt"}},
+		{`run me &`, []string{"run", "me", "&"}},
+		{`echo R&D`, []string{"echo", "R&D"}},
+		{`echo "a | b > c"`, []string{"echo", "a | b > c"}},
+		{`echo $(date)`, []string{"echo", "$(date)"}},
+		{`echo $(echo a; echo b)`, []string{"echo", "$(echo a; echo b)"}},
+		{`echo $x`, []string{"echo", "$x"}},
+	}
+	for _, tt := range tests {
+		toks, err := lexLine(tt.in, nil)
+		if err != nil {
+			t.Errorf("lexLine(%q): %v", tt.in, err)
+			continue
+		}
+		if got := words(toks); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lexLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLexLineErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{`echo 'open`, errUnquotedSingle},
+		{`echo "open`, errUnquotedDouble},
+		{`echo "it's`, errUnquotedDouble},
+		{`echo don't`, errUnquotedSingle},
+	}
+	for _, tt := range tests {
+		if _, err := lexLine(tt.in, nil); err != tt.want {
+			t.Errorf("lexLine(%q) error = %v, want %v", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestLexLineExpand(t *testing.T) {
+	x := fakeScope{
+		vars: map[string]string{"name": "world", "?": "1", "#": "2", "1": "one", "2": "two", "empty": ""},
+		args: []string{"one", "two"},
+		subs: map[string]string{"ls": "a.log\nb.log\n", "date": "today\n"},
+	}
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{`echo $name`, []string{"echo", "world"}},
+		{`echo ${name}s`, []string{"echo", "worlds"}},
+		{`echo "hello $name"`, []string{"echo", "hello world"}},
+		{`echo 'hello $name'`, []string{"echo", "hello $name"}},
+		{`echo \$name`, []string{"echo", "$name"}},
+		{`echo "\$name"`, []string{"echo", "$name"}},
+		{`echo $? $# $1`, []string{"echo", "1", "2", "one"}},
+		{`echo $@`, []string{"echo", "one", "two"}},
+		{`echo "$@"`, []string{"echo", "one two"}},
+		{`echo $empty`, []string{"echo"}},
+		{`echo "$empty"`, []string{"echo", ""}},
+		{`echo $ 5$`, []string{"echo", "$", "5$"}},
+		{`echo $(date)`, []string{"echo", "today"}},
+		{`rm $(ls)`, []string{"rm", "a.log", "b.log"}},
+		{`echo "$(ls)"`, []string{"echo", "a.log\nb.log"}},
+		{`echo x$(date)y`, []string{"echo", "xtodayy"}},
+	}
+	for _, tt := range tests {
+		toks, err := lexLine(tt.in, x)
+		if err != nil {
+			t.Errorf("lexLine(%q): %v", tt.in, err)
+			continue
+		}
+		if got := words(toks); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lexLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{`echo $(date`, `echo ${name`} {
+		if _, err := lexLine(in, x); err == nil {
+			t.Errorf("lexLine(%q): expected a syntax error", in)
+		}
+	}
+}
+
+func TestLexLineGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.log", "a.log", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// On Windows sub\x.dat is a file in sub; elsewhere the backslash is
+	// part of the name. Either way the pattern must still match it.
+	sub := `sub\x.dat`
+	if runtime.GOOS == "windows" {
+		if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, sub), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	x := fakeScope{wd: dir}
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{`ls *.log`, []string{"ls", "a.log", "b.log"}},
+		{`ls ?.txt`, []string{"ls", "c.txt"}},
+		{`ls *.md`, []string{"ls", "*.md"}},
+		{`ls '*.log'`, []string{"ls", "*.log"}},
+		{`ls "*.log"`, []string{"ls", "*.log"}},
+		{`ls \*.log`, []string{"ls", `\*.log`}},
+		{`ls sub\*.dat`, []string{"ls", sub}},
+	}
+	if runtime.GOOS != "windows" {
+		tests = append(tests, struct {
+			in   string
+			want []string
+		}{`ls ` + filepath.Join(dir, "a*"), []string{"ls", filepath.Join(dir, "a.log")}})
+	}
+	for _, tt := range tests {
+		toks, err := lexLine(tt.in, x)
+		if err != nil {
+			t.Errorf("lexLine(%q): %v", tt.in, err)
+			continue
+		}
+		if got := words(toks); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lexLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMatchParen(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"(a)", 2},
+		{"(a (b) c) d", 8},
+		{"(echo ')')", 9},
+		{`(echo "(")`, 9},
+		{`(echo \;)`, 8},
+		{`(echo \))`, 7},
+		{"(open", -1},
+		{"(echo 'x)", -1},
+	}
+	for _, tt := range tests {
+		if got := matchParen(tt.in); got != tt.want {
+			t.Errorf("matchParen(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
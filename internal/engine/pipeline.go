@@ -0,0 +1,383 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+// pipeline is one or more stages joined by `|`, optionally followed by
+// `> file` or `>> file`.
+type pipeline struct {
+	stages   [][]string
+	redirect string
+	append   bool
+	dryRun   bool // --dry-run after the file name
+}
+
+// chainLink is one pipeline of a command list together with the operator
+// that precedes it: "" for the first one, ";", "&&" or "||". The text is
+// lexed, and so expanded, only when the link runs, so `set x=1; echo $x`
+// sees the new value.
+type chainLink struct {
+	op   string
+	text string
+}
+
+// String renders the pipeline back as a command line.
+func (p *pipeline) String() string {
+	parts := make([]string, len(p.stages))
+	for i, st := range p.stages {
+		parts[i] = quoteArgs(st)
+	}
+	s := strings.Join(parts, " | ")
+	if p.redirect != "" {
+		op := ">"
+		if p.append {
+			op = ">>"
+		}
+		s += " " + op + " " + quoteArgs([]string{p.redirect})
+		if p.dryRun {
+			s += " --dry-run"
+		}
+	}
+	return s
+}
+
+// parseChain splits a line into pipelines joined by `;`, `&&` and `||`
+// and checks each one for syntax errors. A trailing `;` is allowed.
+func parseChain(raw string) ([]chainLink, error) {
+	var links []chainLink
+	op := ""
+	start, depth := 0, 0
+	for i := 0; i <= len(raw); i++ {
+		next := ""
+		if i < len(raw) {
+			switch c := raw[i]; {
+			case c == '"' || c == '\'' || c == '\\':
+				j, err := skipQuoted(raw, i)
+				if err != nil {
+					return nil, err
+				}
+				i = j
+			case c == '$' && i+1 < len(raw) && raw[i+1] == '(':
+				depth++
+				i++
+			case c == '(' && depth > 0:
+				depth++
+			case c == ')' && depth > 0:
+				depth--
+			case depth > 0:
+			case c == ';':
+				next = ";"
+			case (c == '&' || c == '|') && i+1 < len(raw) && raw[i+1] == c:
+				next = raw[i : i+2]
+			}
+			if next == "" {
+				continue
+			}
+		}
+		text := strings.TrimSpace(raw[start:i])
+		if text == "" {
+			if next != "" {
+				return nil, fmt.Errorf("syntax error near '%s'", next)
+			}
+			if op != "" && op != ";" {
+				return nil, fmt.Errorf("syntax error: '%s' expects a command", op)
+			}
+			break
+		}
+		toks, err := lexLine(text, nil)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := parsePipeline(toks); err != nil {
+			return nil, err
+		}
+		links = append(links, chainLink{op: op, text: text})
+		if next == "" {
+			break
+		}
+		op = next
+		i += len(next) - 1
+		start = i + 1
+	}
+	if len(links) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return links, nil
+}
+
+func parsePipeline(toks []token) (*pipeline, error) {
+	p := &pipeline{}
+	cur := []string{}
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if !t.op {
+			if p.redirect != "" && t.text == "--dry-run" {
+				p.dryRun = true
+				continue
+			}
+			if p.redirect != "" {
+				return nil, fmt.Errorf("syntax error: unexpected '%s' after redirection", t.text)
+			}
+			cur = append(cur, t.text)
+			continue
+		}
+		switch t.text {
+		case "|":
+			if p.redirect != "" {
+				return nil, errors.New("syntax error: '|' after redirection")
+			}
+			if len(cur) == 0 {
+				return nil, errors.New("syntax error near '|'")
+			}
+			p.stages = append(p.stages, cur)
+			cur = []string{}
+		case ">", ">>":
+			if p.redirect != "" {
+				return nil, errors.New("syntax error: only one redirection allowed")
+			}
+			if len(cur) == 0 {
+				return nil, fmt.Errorf("syntax error near '%s'", t.text)
+			}
+			if i+1 >= len(toks) || toks[i+1].op {
+				return nil, fmt.Errorf("syntax error: '%s' expects a file name", t.text)
+			}
+			p.stages = append(p.stages, cur)
+			cur = nil
+			p.redirect = toks[i+1].text
+			p.append = t.text == ">>"
+			i++
+		}
+	}
+	if p.redirect == "" {
+		if len(cur) == 0 {
+			if len(p.stages) > 0 {
+				return nil, errors.New("syntax error: '|' expects a command")
+			}
+			return nil, errors.New("empty command")
+		}
+		p.stages = append(p.stages, cur)
+	}
+	return p, nil
+}
+
+// runChain runs each pipeline in turn, skipping those whose `&&` or `||`
+// condition does not hold. Outputs are joined and the result carries the
+// status of the last pipeline that ran.
+// A cancelled ctx stops the chain with code 130, as after SIGINT.
+func (e *Engine) runChain(ctx context.Context, links []chainLink) commands.Result {
+	var outs []string
+	var last commands.Result
+	for _, l := range links {
+		if ctx.Err() != nil {
+			break
+		}
+		if (l.op == "&&" && last.Failed()) || (l.op == "||" && !last.Failed()) {
+			continue
+		}
+		last = e.runLink(ctx, l.text)
+		e.setStatus(last.Code)
+		if last.Output != "" {
+			outs = append(outs, last.Output)
+		}
+	}
+	if ctx.Err() != nil {
+		outs = append(outs, "interrupted")
+		last = commands.Result{Err: ctx.Err(), Code: 130}
+	}
+	last.Output = strings.Join(outs, "\n")
+	return last
+}
+
+// runLink expands and runs one pipeline of a command list.
+func (e *Engine) runLink(ctx context.Context, text string) commands.Result {
+	toks, err := lexLine(text, e.scope(ctx))
+	if err != nil {
+		return syntaxError(err)
+	}
+	p, err := parsePipeline(toks)
+	if err != nil {
+		return syntaxError(err)
+	}
+	return e.runPipeline(ctx, p)
+}
+
+// stripFlag removes flag from the arguments of a stage and reports
+// whether it was there.
+func stripFlag(stage []string, flag string) ([]string, bool) {
+	out := stage[:1:1]
+	for _, a := range stage[1:] {
+		if a != flag {
+			out = append(out, a)
+		}
+	}
+	return out, len(out) < len(stage)
+}
+
+func syntaxError(err error) commands.Result {
+	res := commands.Fail(err.Error())
+	res.Code = 2
+	return res
+}
+
+func (e *Engine) runPipeline(ctx context.Context, p *pipeline) commands.Result {
+	cmds := make([]*commands.Command, len(p.stages))
+	async, dry := false, p.dryRun
+	for i, st := range p.stages {
+		if expansion, ok := e.alias(ctx, st[0]); ok {
+			cmds[i] = e.aliasCommand(st[0], expansion)
+			continue
+		}
+		if body, ok := e.function(st[0]); ok {
+			cmds[i] = e.funcCommand(st[0], body)
+			continue
+		}
+		cmd, ok := e.registry.Lookup(st[0])
+		if !ok {
+			res := e.unknownCommand(p, i)
+			if commands.EnvOf(ctx).Files == nil {
+				env := e.env()
+				env.Line = p.String()
+				e.logExec(env, time.Now(), res, nil)
+			}
+			return res
+		}
+		cmds[i] = cmd
+		async = async || cmd.Async
+		if cmd.DryRun {
+			var found bool
+			p.stages[i], found = stripFlag(st, "--dry-run")
+			dry = dry || found
+		}
+	}
+	env := e.env()
+	env.DryRun = env.DryRun || dry || commands.EnvOf(ctx).DryRun
+	if res, ok := e.checkPolicy(ctx, cmds, p, env); !ok {
+		return res
+	}
+	env.Line = p.String()
+	// Pipelines run by an alias, function or script add their files to
+	// the line that ran them, which is the one that is logged.
+	outer := commands.EnvOf(ctx).Files
+	env.Files = outer
+	if outer == nil {
+		env.Files = &commands.FileLog{}
+	}
+	ctx = commands.WithEnv(ctx, env)
+
+	start := time.Now()
+	res, job := e.startPipeline(ctx, cmds, p, async)
+	if outer == nil || job != nil {
+		e.logExec(env, start, res, job)
+	}
+	return res
+}
+
+// startPipeline runs the stages of p, returning the job if they were
+// started in the background.
+func (e *Engine) startPipeline(ctx context.Context, cmds []*commands.Command, p *pipeline, async bool) (commands.Result, *Job) {
+	if len(cmds) == 1 && p.redirect == "" {
+		res, job := e.dispatch(ctx, cmds[0], p.String(), p.stages[0][1:])
+		return suggestSubcommand(cmds[0], p, res), job
+	}
+
+	if async && e.MsgChan != nil && !isSync(ctx) {
+		j := e.jobs.start(ctx, p.String(), e.MsgChan, func(ctx context.Context, ch chan string) error {
+			res := e.runStages(ctx, cmds, p)
+			if res.Output != "" {
+				ch <- res.Output
+			}
+			if res.Failed() {
+				err := res.Err
+				if err == nil {
+					err = fmt.Errorf("exit status %d", res.Code)
+				}
+				return shownError{err}
+			}
+			return nil
+		})
+		return commands.OK(fmt.Sprintf("[%d] Pipeline running in background... results will appear below.", j.ID)), j
+	}
+	return e.runStages(ctx, cmds, p), nil
+}
+
+// runStages runs every stage synchronously, feeding each output into the
+// next stage's input, and applies the redirection at the end. A stage
+// that fails feeds nothing on; its message is kept in the output and,
+// as with pipefail, its status wins unless a later stage fails too. A
+// failed last stage leaves the redirection target alone.
+func (e *Engine) runStages(ctx context.Context, cmds []*commands.Command, p *pipeline) commands.Result {
+	var res, failed commands.Result
+	var errs []string
+	for i, cmd := range cmds {
+		if ctx.Err() != nil {
+			return commands.Result{Err: ctx.Err(), Code: 130}
+		}
+		if i > 0 && res.Failed() {
+			errs = append(errs, res.Output)
+			failed = res
+			res = commands.OK("")
+		}
+		args := p.stages[i][1:]
+		if i > 0 && cmd.Pipe != nil {
+			res = cmd.Pipe(ctx, args, res.Output)
+		} else {
+			res = e.runSync(ctx, cmd, args)
+		}
+	}
+	if p.redirect != "" && !res.Failed() {
+		note, err := commands.Redirect(ctx, e.resolve(p.redirect), res.Output, p.append)
+		if err != nil {
+			res = commands.Fail("redirect: " + err.Error())
+		} else {
+			res.Output = note
+		}
+	}
+	if len(errs) == 0 {
+		return res
+	}
+	if res.Output != "" {
+		errs = append(errs, res.Output)
+	}
+	res.Output = strings.Join(errs, "\n")
+	if !res.Failed() {
+		res.Err, res.Code = failed.Err, failed.Code
+	}
+	return res
+}
+
+// resolve expands a leading ~ or ~/ and makes path absolute against the
+// engine's working directory. Other names starting with ~, such as
+// ~backup, are left as they are.
+func (e *Engine) resolve(path string) string {
+	if home, err := os.UserHomeDir(); err == nil {
+		if path == "~" {
+			path = home
+		} else if strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(e.Cwd(), path)
+	}
+	return path
+}
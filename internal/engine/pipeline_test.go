@@ -0,0 +1,296 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+func TestParseChain(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []chainLink
+	}{
+		{"echo a", []chainLink{{"", "echo a"}}},
+		{"a; b", []chainLink{{"", "a"}, {";", "b"}}},
+		{"a;b;", []chainLink{{"", "a"}, {";", "b"}}},
+		{"a && b || c", []chainLink{{"", "a"}, {"&&", "b"}, {"||", "c"}}},
+		{"a | b && c > f", []chainLink{{"", "a | b"}, {"&&", "c > f"}}},
+		{"echo 'a; b' && echo \"c || d\"", []chainLink{{"", "echo 'a; b'"}, {"&&", "echo \"c || d\""}}},
+		{`echo a\; b`, []chainLink{{"", `echo a\; b`}}},
+		{"echo $(a; b) ; c", []chainLink{{"", "echo $(a; b)"}, {";", "c"}}},
+		{"echo $(a && (b)) || c", []chainLink{{"", "echo $(a && (b))"}, {"||", "c"}}},
+		{"run job &", []chainLink{{"", "run job &"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseChain(tt.in)
+		if err != nil {
+			t.Errorf("parseChain(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseChain(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseChainErrors(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "empty command"},
+		{"   ", "empty command"},
+		{";", "syntax error near ';'"},
+		{"a ;; b", "syntax error near ';'"},
+		{"&& a", "syntax error near '&&'"},
+		{"a &&", "syntax error: '&&' expects a command"},
+		{"a ||", "syntax error: '||' expects a command"},
+		{"a && | b", "syntax error near '|'"},
+		{"a > f && b >", "syntax error: '>' expects a file name"},
+		{"echo 'open && b", errUnquotedSingle.Error()},
+		{`echo "open; b`, errUnquotedDouble.Error()},
+	}
+	for _, tt := range tests {
+		_, err := parseChain(tt.in)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("parseChain(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestParsePipeline(t *testing.T) {
+	tests := []struct {
+		in   string
+		want pipeline
+	}{
+		{"echo a", pipeline{stages: [][]string{{"echo", "a"}}}},
+		{"ls | grep go | sort", pipeline{stages: [][]string{{"ls"}, {"grep", "go"}, {"sort"}}}},
+		{"ls > out.txt", pipeline{stages: [][]string{{"ls"}}, redirect: "out.txt"}},
+		{"ls | sort >> 'my log.txt'", pipeline{stages: [][]string{{"ls"}, {"sort"}}, redirect: "my log.txt", append: true}},
+		{"ls > out.txt --dry-run", pipeline{stages: [][]string{{"ls"}}, redirect: "out.txt", dryRun: true}},
+		{"echo --dry-run > out.txt", pipeline{stages: [][]string{{"echo", "--dry-run"}}, redirect: "out.txt"}},
+		{"echo '>' '|'", pipeline{stages: [][]string{{"echo", ">", "|"}}}},
+	}
+	for _, tt := range tests {
+		toks, err := lexLine(tt.in, nil)
+		if err != nil {
+			t.Fatalf("lexLine(%q): %v", tt.in, err)
+		}
+		got, err := parsePipeline(toks)
+		if err != nil {
+			t.Errorf("parsePipeline(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("parsePipeline(%q) = %+v, want %+v", tt.in, *got, tt.want)
+		}
+	}
+}
+
+func TestParsePipelineErrors(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"| a", "syntax error near '|'"},
+		{"a | | b", "syntax error near '|'"},
+		{"a |", "syntax error: '|' expects a command"},
+		{"> f", "syntax error near '>'"},
+		{"a >", "syntax error: '>' expects a file name"},
+		{"a >> | b", "syntax error: '>>' expects a file name"},
+		{"a > f > g", "syntax error: only one redirection allowed"},
+		{"a > f | b", "syntax error: '|' after redirection"},
+		{"a > f extra", "syntax error: unexpected 'extra' after redirection"},
+	}
+	for _, tt := range tests {
+		toks, err := lexLine(tt.in, nil)
+		if err != nil {
+			t.Fatalf("lexLine(%q): %v", tt.in, err)
+		}
+		_, err = parsePipeline(toks)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("parsePipeline(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+// TestPipelineString checks that a parsed pipeline renders back to a line
+// that parses the same way.
+func TestPipelineString(t *testing.T) {
+	for _, in := range []string{
+		"echo a",
+		"echo 'a b' | grep a",
+		"ls >> 'my log.txt'",
+		"ls > out.txt --dry-run",
+		`echo "it's" '$x'`,
+	} {
+		toks, _ := lexLine(in, nil)
+		p, err := parsePipeline(toks)
+		if err != nil {
+			t.Fatalf("parsePipeline(%q): %v", in, err)
+		}
+		toks, err = lexLine(p.String(), nil)
+		if err != nil {
+			t.Errorf("%q renders as %q, which does not lex: %v", in, p.String(), err)
+			continue
+		}
+		back, err := parsePipeline(toks)
+		if err != nil || !reflect.DeepEqual(back, p) {
+			t.Errorf("%q renders as %q, which parses as %+v", in, p.String(), back)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	dir := t.TempDir()
+	e := newTestEngine(t)
+	e.RunScript("cd", "cd '"+dir+"'", nil, nil)
+	tests := []struct {
+		in, want string
+	}{
+		{"~", home},
+		{"~/notes.txt", filepath.Join(home, "notes.txt")},
+		{"~backup", filepath.Join(dir, "~backup")},
+		{"out.txt", filepath.Join(dir, "out.txt")},
+		{"sub/../out.txt", filepath.Join(dir, "out.txt")},
+		{dir, dir},
+	}
+	for _, tt := range tests {
+		if got := e.resolve(tt.in); got != tt.want {
+			t.Errorf("resolve(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// pipeEngine is a test engine in dir with two more commands: `status n`
+// fails with code n, printing "status n", and `upper` prints its piped
+// input in capitals, or "upper: no input".
+func pipeEngine(t *testing.T, dir string) *Engine {
+	t.Helper()
+	e := newTestEngine(t)
+	e.cwd = dir
+	for _, c := range []commands.Command{
+		{
+			Name: "status",
+			Run: func(ctx context.Context, args []string) commands.Result {
+				n, _ := strconv.Atoi(args[0])
+				return commands.Result{Output: "status " + args[0], Code: n}
+			},
+		},
+		{
+			Name: "upper",
+			Run:  func(ctx context.Context, args []string) commands.Result { return commands.OK("upper: no input") },
+			Pipe: func(ctx context.Context, args []string, stdin string) commands.Result {
+				return commands.OK(strings.ToUpper(stdin))
+			},
+		},
+	} {
+		if err := e.registry.Register(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return e
+}
+
+func TestRunPipeline(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("todo: one\ndone: two\ntodo: three\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := pipeEngine(t, dir)
+	for _, tc := range []struct {
+		line, out string
+		code      int
+	}{
+		{"echo hello | upper", "HELLO", 0},
+		{"cat notes.txt | grep todo | upper", "TODO: ONE\nTODO: THREE", 0},
+		{"cat notes.txt | grep -n todo", "1: todo: one\n3: todo: three", 0},
+		// A stage without piped input runs as it would alone.
+		{"echo hello | status 0 | upper", "STATUS 0", 0},
+		// A failed stage feeds nothing on, and its status wins.
+		{"status 3 | upper", "status 3", 3},
+		{"cat missing.txt | grep todo", "cat: missing.txt", 1},
+		{"status 4 | echo after", "status 4\nafter", 4},
+		// Unless a later stage fails too.
+		{"status 4 | status 5", "status 4\nstatus 5", 5},
+		{"echo hello | grep nothing", "grep: no matches", 1},
+	} {
+		res := e.Execute(tc.line)
+		if res.Code != tc.code || !strings.HasPrefix(res.Output, tc.out) {
+			t.Errorf("%s: got %q (code %d), want %q (code %d)", tc.line, res.Output, res.Code, tc.out, tc.code)
+		}
+		if st := e.Execute("echo $?"); st.Output != strconv.Itoa(tc.code) {
+			t.Errorf("%s: $? is %s, want %d", tc.line, st.Output, tc.code)
+		}
+	}
+}
+
+func TestRunPipelineRedirect(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	e := pipeEngine(t, dir)
+	out := filepath.Join(dir, "out.txt")
+	if res := e.Execute("echo hello | upper > out.txt"); res.Failed() {
+		t.Fatal(res.Output)
+	}
+	if res := e.Execute("echo again | upper >> out.txt"); res.Failed() {
+		t.Fatal(res.Output)
+	}
+	if b, _ := os.ReadFile(out); string(b) != "HELLO\nAGAIN\n" {
+		t.Errorf("out.txt = %q", b)
+	}
+	// A failed last stage leaves the target alone.
+	if res := e.Execute("echo x | status 2 > out.txt"); res.Code != 2 {
+		t.Errorf("failed stage: code %d, %q", res.Code, res.Output)
+	}
+	if b, _ := os.ReadFile(out); string(b) != "HELLO\nAGAIN\n" {
+		t.Errorf("out.txt after a failed pipeline = %q", b)
+	}
+}
+
+func TestRunChain(t *testing.T) {
+	e := pipeEngine(t, t.TempDir())
+	for _, tc := range []struct {
+		line, out string
+		code      int
+	}{
+		{"status 1 && echo x", "status 1", 1},
+		{"status 0 && echo x", "status 0\nx", 0},
+		{"status 1 || echo x", "status 1\nx", 0},
+		{"status 0 || echo x", "status 0", 0},
+		{"status 2; echo x", "status 2\nx", 0},
+		{"echo a; status 3", "a\nstatus 3", 3},
+		{"echo a; echo b; echo c", "a\nb\nc", 0},
+		// A skipped link keeps the status the next one tests.
+		{"status 1 && echo x || echo y", "status 1\ny", 0},
+		{"status 0 || echo x && echo y", "status 0\ny", 0},
+		{"status 1 && echo x && echo y; echo z", "status 1\nz", 0},
+		{"status 1 || status 2 || echo y", "status 1\nstatus 2\ny", 0},
+		{"echo hi | status 4 && echo x; echo $?", "status 4\n4", 0},
+	} {
+		res := e.Execute(tc.line)
+		if res.Code != tc.code || res.Output != tc.out {
+			t.Errorf("%s: got %q (code %d), want %q (code %d)", tc.line, res.Output, res.Code, tc.out, tc.code)
+		}
+	}
+}
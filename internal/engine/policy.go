@@ -0,0 +1,100 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+var policyFile = filepath.Join("data", "policy.json")
+
+// checkPolicy looks for stages of p that the policy says must be
+// confirmed. --confirm is the confirmation and is removed from the
+// arguments of every stage. A dry run needs none for stages whose
+// command supports dry runs, since they change nothing; others would
+// really run, so they are confirmed as usual. Otherwise an interactive
+// session asks the user, and the pipeline runs only if they say yes;
+// any other session refuses it. env records whether the line was
+// confirmed, for commands that write files.
+func (e *Engine) checkPolicy(ctx context.Context, cmds []*commands.Command, p *pipeline, env *commands.Env) (commands.Result, bool) {
+	dry := env.DryRun
+	confirmed, anyGiven := false, false
+	for i, cmd := range cmds {
+		var given bool
+		p.stages[i], given = stripFlag(p.stages[i], "--confirm")
+		anyGiven = anyGiven || given
+		rule, risky := e.policy.ConfirmRule(cmd, p.stages[i][1:])
+		if !risky || given || confirmed || (dry && cmd.DryRun) {
+			continue
+		}
+		if !e.Interactive {
+			return commands.Failf("%s: needs confirmation (policy rule '%s'); add --confirm to run it", p.stages[i][0], rule), false
+		}
+		msg := fmt.Sprintf("%s: needs confirmation (policy rule '%s'). Run it?", p, rule)
+		a, err := e.ask(ctx, p.String(), commands.Prompt{Kind: commands.PromptConfirm, Message: msg})
+		if err != nil || a != "y" {
+			return commands.Failf("%s: cancelled", p.stages[i][0]), false
+		}
+		confirmed = true
+	}
+	env.Confirmed = confirmed || anyGiven
+	if p.redirect == "" || env.Confirmed || dry {
+		return commands.Result{}, true
+	}
+	if rule, risky := e.policy.ConfirmRedirect(e.resolve(p.redirect), p.append); risky {
+		if !e.Interactive {
+			return commands.Failf("redirect: %s needs confirmation (policy rule '%s'); add --confirm before the redirection to run it", p.redirect, rule), false
+		}
+		msg := fmt.Sprintf("%s: needs confirmation (policy rule '%s'). Run it?", p, rule)
+		a, err := e.ask(ctx, p.String(), commands.Prompt{Kind: commands.PromptConfirm, Message: msg})
+		if err != nil || a != "y" {
+			return commands.Fail("redirect: cancelled"), false
+		}
+		env.Confirmed = true
+	}
+	return commands.Result{}, true
+}
+
+func (e *Engine) CmdPolicy(ctx context.Context, args []string) commands.Result {
+	if len(args) > 0 && strings.ToLower(args[0]) != "show" {
+		return commands.Fail("policy: unknown subcommand. Try 'policy show'")
+	}
+	p := e.policy
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "Policy file: %s\n", policyFile)
+	if e.policyErr != nil {
+		fmt.Fprintf(sb, "  not used: %v; these are the defaults\n", e.policyErr)
+	}
+	list := func(title string, items []string) {
+		fmt.Fprintf(sb, "%s:\n", title)
+		if len(items) == 0 {
+			sb.WriteString("  (none)\n")
+		}
+		for _, it := range items {
+			fmt.Fprintf(sb, "  %s\n", it)
+		}
+	}
+	list("Confirm before running", p.Confirm)
+	list("Protected paths", p.Protected)
+	list("Protected trees", p.ProtectedTrees)
+	if p.Sandboxed() {
+		list("Sandbox roots (paths elsewhere are refused)", p.Roots)
+	} else {
+		sb.WriteString("Sandbox: off (no roots configured)\n")
+	}
+	return commands.OK(strings.TrimRight(sb.String(), "\n"))
+}
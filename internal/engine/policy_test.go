@@ -0,0 +1,56 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDryRunConfirmation checks that a dry run waives confirmation only
+// for commands that honour it.
+func TestDryRunConfirmation(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		src    string
+		refuse bool
+	}{
+		{"risky", "rm -r sub", true},
+		{"dry run flag", "rm -r sub --dry-run", false},
+		{"session dry run", "set dryrun on\nrm -r sub", false},
+		{"redirect dry run", "rm -r sub > out.txt --dry-run", false},
+		// kill ignores dry runs, so it would really run. The pid is
+		// beyond any real one in case it does.
+		{"session dry run, no dry run support", "set dryrun on\nkill 99999999", true},
+		{"redirect dry run, no dry run support", "kill 99999999 > out.txt --dry-run", true},
+	}
+	for _, tt := range tests {
+		e := newTestEngine(t)
+		e.RunScript("cd", "cd '"+dir+"'", nil, nil)
+		res := e.RunScript(tt.name, tt.src, nil, nil)
+		refused := strings.Contains(res.Output, "needs confirmation")
+		if refused != tt.refuse {
+			t.Errorf("%s: refused = %v, want %v (%q)", tt.name, refused, tt.refuse, res.Output)
+		}
+		if _, err := os.Stat(sub); err != nil {
+			t.Fatalf("%s: the dry run removed the directory", tt.name)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"sync"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+// PromptRequest is a question from a running command, which waits until
+// it is answered or cancelled. Line is the command line that asks.
+type PromptRequest struct {
+	commands.Prompt
+	Line string
+
+	ctx   context.Context
+	reply chan promptReply
+	once  sync.Once
+}
+
+type promptReply struct {
+	answer string
+	err    error
+}
+
+// Answer replies to the question. An answer that does not fit it is
+// refused, and the question stays open.
+func (r *PromptRequest) Answer(s string) error {
+	a, err := r.Parse(s)
+	if err != nil {
+		return err
+	}
+	r.send(promptReply{answer: a})
+	return nil
+}
+
+// Cancel declines to answer.
+func (r *PromptRequest) Cancel() {
+	r.send(promptReply{err: commands.ErrPromptCancelled})
+}
+
+// Expired reports whether the command stopped waiting for an answer.
+func (r *PromptRequest) Expired() bool {
+	return r.ctx.Err() != nil
+}
+
+func (r *PromptRequest) send(rep promptReply) {
+	r.once.Do(func() { r.reply <- rep })
+}
+
+// Prompts delivers the questions commands ask in an interactive session,
+// one at a time, to be answered by the UI.
+func (e *Engine) Prompts() <-chan *PromptRequest {
+	return e.prompts
+}
+
+// ask puts p to the user and waits for the answer, or for ctx to end.
+func (e *Engine) ask(ctx context.Context, line string, p commands.Prompt) (string, error) {
+	if !e.Interactive {
+		return "", commands.ErrNoPrompt
+	}
+	r := &PromptRequest{Prompt: p, Line: line, ctx: ctx, reply: make(chan promptReply, 1)}
+	select {
+	case e.prompts <- r:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	select {
+	case rep := <-r.reply:
+		return rep.answer, rep.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
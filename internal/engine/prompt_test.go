@@ -0,0 +1,146 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+func TestPromptRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &PromptRequest{Prompt: commands.Prompt{Kind: commands.PromptConfirm}, ctx: ctx, reply: make(chan promptReply, 1)}
+	if err := r.Answer("maybe"); err == nil {
+		t.Error("an answer that does not fit was taken")
+	}
+	select {
+	case rep := <-r.reply:
+		t.Fatalf("a refused answer was sent: %+v", rep)
+	default:
+	}
+	if err := r.Answer("yes"); err != nil {
+		t.Fatal(err)
+	}
+	r.Cancel()
+	if rep := <-r.reply; rep.answer != "y" || rep.err != nil {
+		t.Errorf("reply %+v, want y", rep)
+	}
+	select {
+	case rep := <-r.reply:
+		t.Errorf("a second reply was sent: %+v", rep)
+	default:
+	}
+
+	r = &PromptRequest{ctx: ctx, reply: make(chan promptReply, 1)}
+	r.Cancel()
+	if rep := <-r.reply; !errors.Is(rep.err, commands.ErrPromptCancelled) {
+		t.Errorf("cancel replied %+v", rep)
+	}
+	if r.Expired() {
+		t.Error("expired before the command stopped waiting")
+	}
+	cancel()
+	if !r.Expired() {
+		t.Error("not expired after the command stopped waiting")
+	}
+}
+
+// TestAsk runs a command that asks for a password, answering it the
+// way the UI does.
+func TestAsk(t *testing.T) {
+	e := newTestEngine(t)
+	e.registry.Register(commands.Command{
+		Name: "login",
+		Run: func(ctx context.Context, args []string) commands.Result {
+			pw, err := commands.AskHidden(ctx, "Password:")
+			if err != nil {
+				return commands.Fail("login: " + err.Error())
+			}
+			return commands.OK("got " + pw)
+		},
+	})
+
+	if res := e.Execute("login"); !res.Failed() || res.Output != "login: "+commands.ErrNoPrompt.Error() {
+		t.Errorf("login with nobody to ask: %q", res.Output)
+	}
+
+	e.Interactive = true
+	go func() {
+		r := <-e.Prompts()
+		if r.Line != "login" || r.Kind != commands.PromptHidden || r.Message != "Password:" {
+			t.Errorf("asked %+v", r)
+		}
+		r.Answer("s3cret")
+		r = <-e.Prompts()
+		r.Cancel()
+	}()
+	if res := e.Execute("login"); res.Failed() || res.Output != "got s3cret" {
+		t.Errorf("answered login: %q", res.Output)
+	}
+	if res := e.Execute("login"); !res.Failed() || res.Output != "login: cancelled" {
+		t.Errorf("cancelled login: %q", res.Output)
+	}
+
+	// A question nobody answers ends with the command's context.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := e.ask(ctx, "login", commands.Prompt{Kind: commands.PromptText}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unanswered question: %v", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := e.ask(ctx, "login", commands.Prompt{Kind: commands.PromptText})
+		done <- err
+	}()
+	r := <-e.Prompts()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("question dropped by the command: %v", err)
+	}
+	if !r.Expired() {
+		t.Error("the dropped question is not expired")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	e := newTestEngine(t)
+	e.registry.Register(commands.Command{
+		Name:    "login",
+		Aliases: []string{"signin"},
+		Run:     func(ctx context.Context, args []string) commands.Result { return commands.OK("") },
+		Secret: func(args []string) []int {
+			if len(args) == 2 {
+				return []int{1}
+			}
+			return nil
+		},
+	})
+	tests := []struct{ line, want string }{
+		{"login bob  hunter2", "login bob '***'"},
+		{"signin bob 'a b'", "signin bob '***'"},
+		{"echo hi && login bob pw | upper > out.txt", "echo hi && login bob '***' | upper > out.txt"},
+		{"login bob pw >> 'my log'; echo  'x y'", "login bob '***' >> 'my log' ; echo 'x y'"},
+		{"login  bob", "login  bob"},
+		{"echo login bob pw", "echo login bob pw"},
+		{"echo 'unterminated", "echo 'unterminated"},
+	}
+	for _, tt := range tests {
+		if got := e.Redact(tt.line); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+// sandboxed returns an engine confined to a new root, which it starts
+// in, and a directory outside the root that root/link points to.
+func sandboxed(t *testing.T) (e *Engine, root, outside string) {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside, err = filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{"in.txt": "in\n"})
+	writeFiles(t, outside, map[string]string{
+		"x.txt":        "x\n",
+		"s.rsh":        "echo ran\n",
+		"aliases.json": `{"gs": "grep -i"}`,
+	})
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skipf("cannot make a symlink: %v", err)
+	}
+	e = newTestEngine(t)
+	e.policy = commands.DefaultPolicy()
+	e.policy.Roots = []string{root}
+	e.policy.Protected = append(e.policy.Protected, filepath.Join(root, "keep.txt"))
+	if res := e.Execute("cd '" + root + "'"); res.Failed() {
+		t.Fatalf("cd into the root: %s", res.Output)
+	}
+	return e, root, outside
+}
+
+// TestSandboxRefuses checks that no command can read or change a path
+// outside the roots, whether named directly or reached through a
+// symlink inside them.
+func TestSandboxRefuses(t *testing.T) {
+	e, root, outside := sandboxed(t)
+	before, beforeRoot := fileTree(t, outside), fileTree(t, root)
+	var lines []string
+	for _, target := range []string{outside, "link"} {
+		lines = append(lines,
+			"cd '"+target+"'",
+			"echo x > '"+filepath.Join(target, "y.txt")+"'",
+			"echo x >> '"+filepath.Join(target, "x.txt")+"'",
+			"cp in.txt '"+target+"'",
+			"cp '"+filepath.Join(target, "x.txt")+"' copy.txt",
+			"mv in.txt '"+target+"'",
+			"mv '"+filepath.Join(target, "x.txt")+"' moved.txt",
+			"del '"+filepath.Join(target, "x.txt")+"'",
+			"alias export '"+filepath.Join(target, "a.json")+"'",
+			"alias import '"+filepath.Join(target, "aliases.json")+"'",
+			"run '"+filepath.Join(target, "s.rsh")+"'",
+		)
+	}
+	for _, line := range lines {
+		res := e.Execute(line)
+		if !res.Failed() || !strings.Contains(res.Output, commands.ErrOutsideRoots.Error()) {
+			t.Errorf("%s: got %q, want it refused", line, res.Output)
+		}
+	}
+	if got := fileTree(t, outside); !reflect.DeepEqual(got, before) {
+		t.Errorf("outside the root changed: %v, was %v", got, before)
+	}
+	if got := fileTree(t, root); !reflect.DeepEqual(got, beforeRoot) {
+		t.Errorf("the root changed: %v, was %v", got, beforeRoot)
+	}
+	if e.Cwd() != root {
+		t.Errorf("cwd = %s, want %s", e.Cwd(), root)
+	}
+	if res := e.Execute("gs"); !res.Failed() {
+		t.Errorf("an alias was imported from outside the root")
+	}
+}
+
+// TestAliasExportPolicy checks that alias export writes like any other
+// file command: journaled, refused on protected paths, and only planned
+// on a dry run.
+func TestAliasExportPolicy(t *testing.T) {
+	e, root, _ := sandboxed(t)
+	e.Execute("alias add gs 'grep -i'")
+	if res := e.Execute("alias export keep.txt"); !res.Failed() || !strings.Contains(res.Output, "protected") {
+		t.Errorf("export to a protected file: got %q", res.Output)
+	}
+	e.Execute("set dryrun on")
+	if res := e.Execute("alias export a.json"); res.Failed() || !strings.HasPrefix(res.Output, "Dry run") {
+		t.Errorf("dry run export: got %q", res.Output)
+	}
+	e.Execute("set dryrun off")
+	if _, err := os.Stat(filepath.Join(root, "a.json")); err == nil {
+		t.Fatalf("the dry run wrote the file")
+	}
+	res := e.Execute("alias export a.json")
+	if res.Failed() || !strings.Contains(res.Output, "undo with") {
+		t.Fatalf("export: got %q", res.Output)
+	}
+	e.Execute("alias rm gs")
+	if res := e.Execute("alias import a.json"); res.Output != "Imported 1 aliases" {
+		t.Errorf("import: got %q", res.Output)
+	}
+	if res := e.Execute("undo"); res.Failed() {
+		t.Fatalf("undo: %s", res.Output)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.json")); err == nil {
+		t.Errorf("undo left the exported file")
+	}
+}
@@ -0,0 +1,588 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+// Scripts (.rsh) are line based. Besides ordinary command lines they
+// support:
+//
+//	set name=value
+//	if [!] <command> {      } else if <command> {      } else {      }
+//	for name in <words> {   }
+//	func name {   }          called like a command; $1..$9, $@ and $# are its arguments
+//
+// Every command line goes through the same parser as interactive input,
+// and async commands run in the foreground so a script sees their status.
+
+type stmtKind int
+
+const (
+	cmdStmt stmtKind = iota
+	ifStmt
+	forStmt
+	funcStmt
+)
+
+type stmt struct {
+	kind   stmtKind
+	line   int
+	text   string // command, condition or loop words
+	name   string // loop variable or function name
+	negate bool
+	body   []stmt
+	els    []stmt
+}
+
+type scriptParser struct {
+	name  string
+	lines []string
+	pos   int
+}
+
+func parseScript(name, src string) ([]stmt, error) {
+	p := &scriptParser{name: name, lines: strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")}
+	body, term, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if term != "" {
+		return nil, p.errorf(p.pos, "unexpected '%s'", strings.TrimSpace(p.lines[p.pos-1]))
+	}
+	return body, nil
+}
+
+func (p *scriptParser) errorf(line int, format string, a ...any) error {
+	return fmt.Errorf("%s:%d: %s", p.name, line, fmt.Sprintf(format, a...))
+}
+
+// block parses statements up to a closing line and returns what closed
+// it: "}" , "else", "elseif <cond>", or "" at the end of the input.
+func (p *scriptParser) block() ([]stmt, string, error) {
+	var out []stmt
+	for p.pos < len(p.lines) {
+		line := strings.TrimSpace(p.lines[p.pos])
+		p.pos++
+		n := p.pos
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch {
+		case line == "}":
+			return out, "}", nil
+		case line == "} else {":
+			return out, "else", nil
+		case strings.HasPrefix(line, "} else if ") && strings.HasSuffix(line, "{"):
+			return out, "elseif " + strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "} else if "), "{")), nil
+		case strings.HasPrefix(line, "if ") && strings.HasSuffix(line, "{"):
+			st, err := p.parseIf(n, strings.TrimSpace(strings.TrimSuffix(line[3:], "{")))
+			if err != nil {
+				return nil, "", err
+			}
+			out = append(out, st)
+		case strings.HasPrefix(line, "for ") && strings.HasSuffix(line, "{"):
+			f := strings.Fields(strings.TrimSuffix(line, "{"))
+			if len(f) < 3 || f[2] != "in" || !isName(f[1]) {
+				return nil, "", p.errorf(n, "expected 'for <name> in <words> {'")
+			}
+			head := strings.TrimSpace(strings.TrimSuffix(line, "{"))
+			words := strings.TrimSpace(head[strings.Index(head, " in ")+4:])
+			body, err := p.closed(n, "for")
+			if err != nil {
+				return nil, "", err
+			}
+			out = append(out, stmt{kind: forStmt, line: n, name: f[1], text: words, body: body})
+		case strings.HasPrefix(line, "func ") && strings.HasSuffix(line, "{"):
+			name := strings.TrimSuffix(strings.TrimSpace(strings.TrimSuffix(line[5:], "{")), "()")
+			if !isName(name) {
+				return nil, "", p.errorf(n, "invalid function name '%s'", name)
+			}
+			body, err := p.closed(n, "func")
+			if err != nil {
+				return nil, "", err
+			}
+			out = append(out, stmt{kind: funcStmt, line: n, name: name, body: body})
+		case line == "else" || strings.HasPrefix(line, "else "):
+			return nil, "", p.errorf(n, "'else' must follow '}' on the same line")
+		default:
+			out = append(out, stmt{kind: cmdStmt, line: n, text: line})
+		}
+	}
+	return out, "", nil
+}
+
+func (p *scriptParser) parseIf(line int, cond string) (stmt, error) {
+	st := stmt{kind: ifStmt, line: line}
+	if strings.HasPrefix(cond, "!") {
+		st.negate = true
+		cond = strings.TrimSpace(cond[1:])
+	}
+	if cond == "" {
+		return st, p.errorf(line, "'if' expects a command")
+	}
+	st.text = cond
+	body, term, err := p.block()
+	if err != nil {
+		return st, err
+	}
+	st.body = body
+	switch {
+	case term == "}":
+	case term == "else":
+		if st.els, err = p.closed(line, "else"); err != nil {
+			return st, err
+		}
+	case strings.HasPrefix(term, "elseif "):
+		next, err := p.parseIf(p.pos, strings.TrimPrefix(term, "elseif "))
+		if err != nil {
+			return st, err
+		}
+		st.els = []stmt{next}
+	default:
+		return st, p.errorf(line, "missing '}' for 'if'")
+	}
+	return st, nil
+}
+
+// closed parses a block that must end with a plain `}`.
+func (p *scriptParser) closed(line int, what string) ([]stmt, error) {
+	body, term, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if term != "}" {
+		return nil, p.errorf(line, "missing '}' for '%s'", what)
+	}
+	return body, nil
+}
+
+func isName(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isNameByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+type ctxKey int
+
+const (
+	syncKey ctxKey = iota
+	argsKey
+	depthKey
+)
+
+// maxDepth is how deeply function calls and `run` may nest, so runaway
+// recursion ends in an error rather than exhausting the process.
+const maxDepth = 100
+
+// deeper counts one more nested call in ctx, refusing to go past
+// maxDepth.
+func deeper(ctx context.Context, what string) (context.Context, error) {
+	d, _ := ctx.Value(depthKey).(int)
+	if d >= maxDepth {
+		return ctx, fmt.Errorf("%s: nested too deeply (more than %d calls)", what, maxDepth)
+	}
+	return context.WithValue(ctx, depthKey, d+1), nil
+}
+
+// withSync marks ctx so async commands run in the foreground.
+func withSync(ctx context.Context) context.Context {
+	return context.WithValue(ctx, syncKey, true)
+}
+
+func isSync(ctx context.Context) bool {
+	v, _ := ctx.Value(syncKey).(bool)
+	return v
+}
+
+func withArgs(ctx context.Context, args []string) context.Context {
+	return context.WithValue(ctx, argsKey, args)
+}
+
+func argsOf(ctx context.Context) []string {
+	a, _ := ctx.Value(argsKey).([]string)
+	return a
+}
+
+// scope expands references for lines run under ctx.
+type scope struct {
+	e   *Engine
+	ctx context.Context
+}
+
+func (e *Engine) scope(ctx context.Context) *scope {
+	return &scope{e: e, ctx: ctx}
+}
+
+// Outside a script, function or alias there are no positional arguments
+// and `$1`, `$#` and `$@` are left as typed, so `alias add g "grep $1"`
+// stores the reference rather than an empty string.
+func (s *scope) lookup(name string) string {
+	if name == "?" {
+		return strconv.Itoa(s.e.status())
+	}
+	args, ok := s.ctx.Value(argsKey).([]string)
+	if name == "#" {
+		if !ok {
+			return "$#"
+		}
+		return strconv.Itoa(len(args))
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		if !ok {
+			return "$" + name
+		}
+		if n >= 1 && n <= len(args) {
+			return args[n-1]
+		}
+		return ""
+	}
+	if v, ok := s.e.Var(name); ok {
+		return v
+	}
+	return s.e.getenv(name)
+}
+
+func (s *scope) dir() string {
+	return s.e.Cwd()
+}
+
+func (s *scope) positional() []string {
+	args, ok := s.ctx.Value(argsKey).([]string)
+	if !ok {
+		return []string{"$@"}
+	}
+	return args
+}
+
+// substitute runs cmd for `$(cmd)`. Like stderr in a shell, the message
+// of a command that fails is not part of the text inserted.
+func (s *scope) substitute(cmd string) string {
+	res := s.e.execute(withSync(s.ctx), cmd)
+	if res.Failed() {
+		return ""
+	}
+	return res.Output
+}
+
+// Var returns a variable set with `set name=value`.
+func (e *Engine) Var(name string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	v, ok := e.vars[name]
+	return v, ok
+}
+
+func (e *Engine) SetVar(name, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.vars[name] = value
+}
+
+// status is the code of the last pipeline run, for `$?`.
+func (e *Engine) status() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lastCode
+}
+
+func (e *Engine) setStatus(code int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastCode = code
+}
+
+func (e *Engine) function(name string) ([]stmt, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	body, ok := e.funcs[strings.ToLower(name)]
+	return body, ok
+}
+
+// funcCommand wraps a script function so it can be dispatched like any
+// other command.
+func (e *Engine) funcCommand(name string, body []stmt) *commands.Command {
+	return &commands.Command{
+		Name: name,
+		Run: func(ctx context.Context, args []string) commands.Result {
+			ctx, err := deeper(ctx, name)
+			if err != nil {
+				return commands.Fail(err.Error())
+			}
+			return e.collect(withArgs(withSync(ctx), args), body)
+		},
+	}
+}
+
+// collect runs body and joins the output of every command in it.
+func (e *Engine) collect(ctx context.Context, body []stmt) commands.Result {
+	var outs []string
+	res := e.runBlock(ctx, body, func(_ string, r commands.Result) {
+		if r.Output != "" {
+			outs = append(outs, r.Output)
+		}
+	})
+	res.Output = strings.Join(outs, "\n")
+	return res
+}
+
+// runBlock runs statements in order and returns the result of the last
+// command. emit sees every command line and its result as it completes.
+func (e *Engine) runBlock(ctx context.Context, body []stmt, emit func(string, commands.Result)) commands.Result {
+	var last commands.Result
+	for _, st := range body {
+		if ctx.Err() != nil {
+			return commands.Result{Err: ctx.Err(), Code: 130}
+		}
+		if _, ok := e.ExitRequested(); ok {
+			break
+		}
+		switch st.kind {
+		case cmdStmt:
+			last = e.execute(ctx, st.text)
+			emit(st.text, last)
+		case ifStmt:
+			cond := e.execute(ctx, st.text)
+			emit(st.text, cond)
+			last = commands.OK("")
+			if cond.Failed() == st.negate {
+				last = e.runBlock(ctx, st.body, emit)
+			} else if st.els != nil {
+				last = e.runBlock(ctx, st.els, emit)
+			}
+		case forStmt:
+			toks, err := lexLine(st.text, e.scope(ctx))
+			if err != nil {
+				last = syntaxError(err)
+				emit(st.text, last)
+				return last
+			}
+			last = commands.OK("")
+			for _, t := range toks {
+				if t.op {
+					last = syntaxError(fmt.Errorf("syntax error near '%s'", t.text))
+					emit(st.text, last)
+					return last
+				}
+				e.SetVar(st.name, t.text)
+				last = e.runBlock(ctx, st.body, emit)
+				if ctx.Err() != nil {
+					break
+				}
+			}
+		case funcStmt:
+			e.mu.Lock()
+			e.funcs[strings.ToLower(st.name)] = st.body
+			e.mu.Unlock()
+			last = commands.OK("")
+		}
+	}
+	return last
+}
+
+// RunScript runs src as a script with positional arguments args. emit, if
+// not nil, is called with each command and its result as it completes.
+// As with Execute, Interrupt stops it.
+func (e *Engine) RunScript(name, src string, args []string, emit func(line string, res commands.Result)) commands.Result {
+	ctx, done := e.begin()
+	defer done()
+	return e.runScript(ctx, name, src, args, emit)
+}
+
+func (e *Engine) runScript(ctx context.Context, name, src string, args []string, emit func(string, commands.Result)) commands.Result {
+	body, err := parseScript(name, src)
+	if err != nil {
+		res := syntaxError(err)
+		if emit != nil {
+			emit(name, res)
+		}
+		return res
+	}
+	if emit == nil {
+		return e.collect(withArgs(withSync(ctx), args), body)
+	}
+	return e.runBlock(withArgs(withSync(ctx), args), body, emit)
+}
+
+// CmdRun runs a script file. `exit` inside it ends the script, not the
+// shell, and becomes the script's status.
+func (e *Engine) CmdRun(ctx context.Context, args []string) commands.Result {
+	if len(args) == 0 {
+		return commands.Fail("run: usage: run <file> [args...]")
+	}
+	return e.runFile(ctx, args[0], args[1:])
+}
+
+func (e *Engine) runFile(ctx context.Context, file string, args []string) commands.Result {
+	ctx, err := deeper(ctx, "run")
+	if err != nil {
+		return commands.Fail(err.Error())
+	}
+	path := e.resolve(file)
+	b, err := commands.ReadFile(ctx, path)
+	if err != nil {
+		return commands.Failf("run: %v", err)
+	}
+	res := e.runScript(ctx, filepath.Base(path), string(b), args, nil)
+	e.mu.Lock()
+	if e.exiting {
+		res.Code = e.exitCode
+		e.exiting, e.exitCode = false, 0
+	}
+	e.mu.Unlock()
+	return res
+}
+
+// RunRC runs ~/.rootshrc if it exists. It is meant to be called once when
+// an interactive session starts, and also reports a policy file that
+// could not be read.
+func (e *Engine) RunRC() commands.Result {
+	res := commands.OK("")
+	if e.policyErr != nil {
+		res = commands.Fail("policy: " + e.policyErr.Error() + " (using the default rules)")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return res
+	}
+	rc := filepath.Join(home, ".rootshrc")
+	if _, err := os.Stat(rc); err != nil {
+		return res
+	}
+	ctx, done := e.begin()
+	defer done()
+	rcRes := e.runFile(ctx, rc, nil)
+	if res.Failed() {
+		rcRes.Output = strings.TrimSpace(res.Output + "\n" + rcRes.Output)
+		rcRes.Code, rcRes.Err = res.Code, res.Err
+	}
+	return rcRes
+}
+
+func (e *Engine) CmdSet(ctx context.Context, args []string) commands.Result {
+	if len(args) == 0 {
+		e.mu.Lock()
+		names := make([]string, 0, len(e.vars))
+		for n := range e.vars {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		lines := make([]string, len(names))
+		for i, n := range names {
+			lines[i] = n + "=" + e.vars[n]
+		}
+		e.mu.Unlock()
+		if len(lines) == 0 {
+			return commands.OK("No variables set.")
+		}
+		return commands.OK(strings.Join(lines, "\n"))
+	}
+	if strings.ToLower(args[0]) == "dryrun" {
+		return e.setDryRun(args[1:])
+	}
+	if len(args) > 1 {
+		name := strings.ToLower(args[0] + " " + args[1])
+		if _, ok := settings[name]; ok {
+			return e.setSetting(name, args[2:])
+		}
+	}
+	if _, ok := settings[strings.ToLower(args[0])]; ok {
+		return e.setSetting(strings.ToLower(args[0]), args[1:])
+	}
+	name, value, ok := strings.Cut(strings.Join(args, " "), "=")
+	name = strings.TrimSpace(name)
+	if !ok {
+		return commands.Fail("set: usage: set name=value")
+	}
+	if !isName(name) {
+		return commands.Failf("set: invalid variable name '%s'", name)
+	}
+	e.SetVar(name, strings.TrimSpace(value))
+	return commands.OK("")
+}
+
+// setDryRun turns session-wide dry runs on or off: every command that
+// supports --dry-run then behaves as if it was given.
+func (e *Engine) setDryRun(args []string) commands.Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(args) == 0 {
+		if e.dryRun {
+			return commands.OK("dryrun is on")
+		}
+		return commands.OK("dryrun is off")
+	}
+	switch strings.ToLower(args[0]) {
+	case "on", "true", "1":
+		e.dryRun = true
+		return commands.OK("Dry run on: file commands now only show what they would change.")
+	case "off", "false", "0":
+		e.dryRun = false
+		return commands.OK("Dry run off.")
+	}
+	return commands.Fail("set dryrun: usage: set dryrun on|off")
+}
+
+// CmdExport sets a variable in the environment that programs started by
+// this engine get; `export name` exports a shell variable.
+func (e *Engine) CmdExport(ctx context.Context, args []string) commands.Result {
+	if len(args) == 0 {
+		e.mu.Lock()
+		env := append([]string(nil), e.environ...)
+		e.mu.Unlock()
+		sort.Strings(env)
+		return commands.OK(strings.Join(env, "\n"))
+	}
+	for _, a := range args {
+		name, value, ok := strings.Cut(a, "=")
+		if !isName(name) {
+			return commands.Failf("export: invalid variable name '%s'", name)
+		}
+		if !ok {
+			v, set := e.Var(name)
+			if !set {
+				return commands.Failf("export: '%s' is not set", name)
+			}
+			value = v
+		}
+		e.setenv(name, value)
+	}
+	return commands.OK("")
+}
+
+func (e *Engine) CmdUnset(ctx context.Context, args []string) commands.Result {
+	if len(args) == 0 {
+		return commands.Fail("unset: usage: unset <name>...")
+	}
+	e.mu.Lock()
+	for _, n := range args {
+		delete(e.vars, n)
+		delete(e.funcs, strings.ToLower(n))
+	}
+	e.mu.Unlock()
+	return commands.OK("")
+}
@@ -0,0 +1,200 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+func TestParseScript(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []stmt
+	}{
+		{"commands", "echo a\n\n# note\n  echo b  \r\n", []stmt{
+			{kind: cmdStmt, line: 1, text: "echo a"},
+			{kind: cmdStmt, line: 4, text: "echo b"},
+		}},
+		{"if", "if ls {\necho yes\n}", []stmt{
+			{kind: ifStmt, line: 1, text: "ls", body: []stmt{{kind: cmdStmt, line: 2, text: "echo yes"}}},
+		}},
+		{"if not else", "if ! ls x {\necho no\n} else {\necho yes\n}", []stmt{
+			{kind: ifStmt, line: 1, text: "ls x", negate: true,
+				body: []stmt{{kind: cmdStmt, line: 2, text: "echo no"}},
+				els:  []stmt{{kind: cmdStmt, line: 4, text: "echo yes"}}},
+		}},
+		{"else if", "if a {\n} else if b {\necho b\n} else {\necho c\n}", []stmt{
+			{kind: ifStmt, line: 1, text: "a", els: []stmt{
+				{kind: ifStmt, line: 2, text: "b",
+					body: []stmt{{kind: cmdStmt, line: 3, text: "echo b"}},
+					els:  []stmt{{kind: cmdStmt, line: 5, text: "echo c"}}},
+			}},
+		}},
+		{"for", "for f in *.log 'a b' {\necho $f\n}", []stmt{
+			{kind: forStmt, line: 1, name: "f", text: "*.log 'a b'", body: []stmt{{kind: cmdStmt, line: 2, text: "echo $f"}}},
+		}},
+		{"for substitution", "for f in $(find '*.log') {\necho $f\n}", []stmt{
+			{kind: forStmt, line: 1, name: "f", text: "$(find '*.log')", body: []stmt{{kind: cmdStmt, line: 2, text: "echo $f"}}},
+		}},
+		{"func", "func greet() {\nfor n in $@ {\necho hi $n\n}\n}\ngreet a b", []stmt{
+			{kind: funcStmt, line: 1, name: "greet", body: []stmt{
+				{kind: forStmt, line: 2, name: "n", text: "$@", body: []stmt{{kind: cmdStmt, line: 3, text: "echo hi $n"}}},
+			}},
+			{kind: cmdStmt, line: 6, text: "greet a b"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := parseScript("t.rsh", tt.src)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"}", "t.rsh:1: unexpected '}'"},
+		{"echo a\n} else {", "t.rsh:2: unexpected '} else {'"},
+		{"if ls {\necho a", "t.rsh:1: missing '}' for 'if'"},
+		{"if ls {\n} else {\necho a", "t.rsh:1: missing '}' for 'else'"},
+		{"if {\n}", "t.rsh:1: 'if' expects a command"},
+		{"if ! {\n}", "t.rsh:1: 'if' expects a command"},
+		{"if ls {\n}\nelse {\n}", "t.rsh:3: 'else' must follow '}' on the same line"},
+		{"for f {\n}", "t.rsh:1: expected 'for <name> in <words> {'"},
+		{"for 1x in a {\n}", "t.rsh:1: expected 'for <name> in <words> {'"},
+		{"for f in a {\necho $f", "t.rsh:1: missing '}' for 'for'"},
+		{"func my-func {\n}", "t.rsh:1: invalid function name 'my-func'"},
+		{"func f {\nif a {\n}", "t.rsh:1: missing '}' for 'func'"},
+	}
+	for _, tt := range tests {
+		_, err := parseScript("t.rsh", tt.src)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("parseScript(%q) error = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.log", "a.log", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name string
+		src  string
+		args []string
+		want string
+	}{
+		{"if", "if ls {\necho yes\n} else {\necho no\n}", nil, "yes"},
+		{"if not", "if ! ls no-such-dir {\necho missing\n}", nil, "missing"},
+		{"else if", "if ls no-such-dir {\necho a\n} else if ls {\necho b\n}", nil, "b"},
+		{"for glob", "for f in *.log {\necho got $f\n}", nil, "got a.log\ngot b.log"},
+		{"for substitution", "for f in $(echo x) y {\necho $f\n}", nil, "x\ny"},
+		{"args", "for a in $@ {\necho [$a]\n}\necho $#", []string{"one", "two words"}, "[one]\n[two words]\n2"},
+		{"func", "func greet {\necho hi $1\n}\ngreet bob\ngreet amy", nil, "hi bob\nhi amy"},
+		{"chain", "echo a && echo b || echo c; echo d", nil, "a\nb\nd"},
+	}
+	for _, tt := range tests {
+		e := newTestEngine(t)
+		e.RunScript("cd", "cd '"+dir+"'", nil, nil)
+		// What ls prints as a condition depends on the directory, so it
+		// is left out.
+		var out []string
+		e.RunScript(tt.name, tt.src, tt.args, func(line string, res commands.Result) {
+			if !strings.HasPrefix(line, "ls") && !res.Failed() {
+				out = append(out, res.Output)
+			}
+		})
+		if got := strings.Join(out, "\n"); got != tt.want {
+			t.Errorf("%s: output %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunScriptRecursion(t *testing.T) {
+	e := newTestEngine(t)
+	res := e.RunScript("t.rsh", "func loop {\nloop\n}\nloop", nil, nil)
+	if !res.Failed() || !strings.Contains(res.Output+errText(res), "nested too deeply") {
+		t.Errorf("runaway recursion: got %+v", res)
+	}
+}
+
+func errText(res commands.Result) string {
+	if res.Err == nil {
+		return ""
+	}
+	return res.Err.Error()
+}
+
+func TestScriptVariables(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"set", "set dir = logs\necho $dir", "logs"},
+		{"unset", "set x=1\nunset x\necho [$x]", "[]"},
+		{"status", "ls no-such-dir\necho $?\necho $?", "1\n0"},
+		{"export", "set greeting=hi\nexport greeting\nunset greeting\necho $greeting", "hi"},
+		{"func args", "func show {\necho $#\n}\nshow a b c", "3"},
+		{"unset func", "func f {\necho f\n}\nunset f\nf", ""},
+	}
+	for _, tt := range tests {
+		e := newTestEngine(t)
+		var out []string
+		e.RunScript(tt.name, tt.src, nil, func(line string, res commands.Result) {
+			if !res.Failed() && res.Output != "" {
+				out = append(out, res.Output)
+			}
+		})
+		if got := strings.Join(out, "\n"); got != tt.want {
+			t.Errorf("%s: output %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestRunFile checks that run passes arguments to a script file and that
+// exit inside it ends the script with its code but not the shell.
+func TestRunFile(t *testing.T) {
+	dir := t.TempDir()
+	src := "echo start $1\nexit 3\necho unreachable\n"
+	if err := os.WriteFile(filepath.Join(dir, "s.rsh"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := newTestEngine(t)
+	e.RunScript("cd", "cd '"+dir+"'", nil, nil)
+	res := e.Execute("run s.rsh bob")
+	if res.Code != 3 || res.Output != "start bob" {
+		t.Errorf("run s.rsh: got %+v, want code 3 and %q", res, "start bob")
+	}
+	if _, exiting := e.ExitRequested(); exiting {
+		t.Errorf("exit in a script ended the shell")
+	}
+	if res := e.Execute("run missing.rsh"); !res.Failed() {
+		t.Errorf("run missing.rsh: got %+v, want a failure", res)
+	}
+}
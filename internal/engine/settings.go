@@ -0,0 +1,118 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+// setting is an option `set` keeps in the store, so it lasts across
+// sessions. A name of two words, like "anim speed", is set with
+// `set anim speed <n>`. check validates a value and returns it in
+// canonical form; choices, if any, are the values it accepts.
+type setting struct {
+	def     string
+	usage   string
+	choices []string
+	check   func(v string) (string, error)
+}
+
+var settings = map[string]setting{
+	"scrollback": {def: "5000", usage: "set scrollback <lines>", check: atLeast(100, "lines")},
+	"anim":       {def: "on", usage: "set anim on|off", choices: []string{"on", "off"}},
+	"anim speed": {def: "55", usage: "set anim speed <characters per second>", check: atLeast(1, "characters per second")},
+	"anim lines": {def: "20", usage: "set anim lines <n>; longer outputs print at once", check: atLeast(0, "lines")},
+	"anim boot":  {def: "on", usage: "set anim boot on|off", choices: []string{"on", "off"}},
+	"status":     {def: strings.Join(StatusSegments, " "), usage: "set status <segments...>|off", choices: append([]string{"off"}, StatusSegments...), check: checkStatus},
+	"colors":     {def: "auto", usage: "set colors auto|16|256|truecolor|none", choices: []string{"auto", "16", "256", "truecolor", "none"}},
+}
+
+func init() {
+	for name, s := range settings {
+		if s.check == nil {
+			s.check = oneOf(s.choices)
+			settings[name] = s
+		}
+	}
+}
+
+// StatusSegments are what the status bar can show, in the default order.
+var StatusSegments = []string{"cwd", "clock", "jobs", "focus", "remind", "net"}
+
+// checkStatus accepts the status bar segments to show, in order, or off.
+func checkStatus(v string) (string, error) {
+	fields := strings.FieldsFunc(strings.ToLower(v), func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		return "", errors.New("expects segments or off")
+	}
+	if len(fields) == 1 && (fields[0] == "off" || fields[0] == "none") {
+		return "off", nil
+	}
+	for _, f := range fields {
+		if !slices.Contains(StatusSegments, f) {
+			return "", fmt.Errorf("no segment '%s'; choose from %s, or off", f, strings.Join(StatusSegments, " "))
+		}
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// atLeast accepts whole numbers from min up.
+func atLeast(least int, unit string) func(v string) (string, error) {
+	return func(v string) (string, error) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < least {
+			return "", fmt.Errorf("expects a number of %s, at least %d", unit, least)
+		}
+		return strconv.Itoa(n), nil
+	}
+}
+
+// oneOf accepts any of choices, in any case.
+func oneOf(choices []string) func(v string) (string, error) {
+	return func(v string) (string, error) {
+		for _, c := range choices {
+			if strings.EqualFold(v, c) {
+				return c, nil
+			}
+		}
+		return "", errors.New("expects one of " + strings.Join(choices, ", "))
+	}
+}
+
+// Setting returns the value of a saved option, or its default.
+func (e *Engine) Setting(name string) string {
+	if v, ok := e.store.Setting(name); ok {
+		return v
+	}
+	return settings[name].def
+}
+
+func (e *Engine) setSetting(name string, args []string) commands.Result {
+	s := settings[name]
+	if len(args) == 0 {
+		return commands.OK(name + " is " + e.Setting(name))
+	}
+	v, err := s.check(strings.Join(args, " "))
+	if err != nil {
+		return commands.Failf("set %s: %v (usage: %s)", name, err, s.usage)
+	}
+	if err := e.store.SetSetting(name, v); err != nil {
+		return commands.Fail("set " + name + ": " + err.Error())
+	}
+	return commands.OK(name + " set to " + v)
+}
@@ -0,0 +1,51 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnimSettings(t *testing.T) {
+	e := newTestEngine(t)
+	for _, tc := range []struct {
+		line, out string
+		failed    bool
+	}{
+		{"set anim", "anim is on", false},
+		{"set anim speed", "anim speed is 55", false},
+		{"set anim lines", "anim lines is 20", false},
+		{"set anim boot", "anim boot is on", false},
+		{"set anim OFF", "anim set to off", false},
+		{"set anim maybe", "set anim: expects one of on, off (usage: set anim on|off)", true},
+		{"set Anim Speed 120", "anim speed set to 120", false},
+		{"set anim speed 007", "anim speed set to 7", false},
+		{"set anim speed 0", "set anim speed: expects a number of characters per second, at least 1", true},
+		{"set anim speed fast", "expects a number of characters per second", true},
+		{"set anim lines 0", "anim lines set to 0", false},
+		{"set anim lines -1", "set anim lines: expects a number of lines, at least 0", true},
+		{"set anim boot off", "anim boot set to off", false},
+		{"set anim boot 1", "set anim boot: expects one of on, off", true},
+	} {
+		res := e.RunScript("t", tc.line, nil, nil)
+		if res.Failed() != tc.failed || !strings.Contains(res.Output, tc.out) {
+			t.Errorf("%s: got %q (failed %v), want %q", tc.line, res.Output, res.Failed(), tc.out)
+		}
+	}
+	// A refused value leaves the saved one alone.
+	for name, want := range map[string]string{"anim": "off", "anim speed": "7", "anim lines": "0", "anim boot": "off"} {
+		if got := e.Setting(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+// verbs lists everything that can start a command: built-in names and
+// their aliases, user aliases and script functions.
+func (e *Engine) verbs() []string {
+	out := e.registry.Names()
+	if m, err := e.store.Aliases(); err == nil {
+		for n := range m {
+			out = append(out, n)
+		}
+	}
+	e.mu.Lock()
+	for n := range e.funcs {
+		out = append(out, n)
+	}
+	e.mu.Unlock()
+	return out
+}
+
+// unknownCommand reports that stage i of p names no command, suggesting
+// the closest verb when there is one.
+func (e *Engine) unknownCommand(p *pipeline, i int) commands.Result {
+	name := strings.ToLower(p.stages[i][0])
+	res := commands.Failf("Unknown command '%s'. Try 'help'.", name)
+	if fix, ok := commands.Closest(name, e.verbs()); ok {
+		res = commands.Failf("Unknown command '%s'. Did you mean '%s'?", name, fix)
+		res.Suggest = p.with(i, 0, fix).String()
+	}
+	res.Code = 127
+	return res
+}
+
+// suggestSubcommand adds a hint to a failed single-stage result when its
+// first argument looks like a misspelt subcommand of cmd.
+func suggestSubcommand(cmd *commands.Command, p *pipeline, res commands.Result) commands.Result {
+	st := p.stages[0]
+	if !res.Failed() || res.Code == 130 || len(cmd.Subcommands) == 0 || len(st) < 2 {
+		return res
+	}
+	fix, ok := commands.Closest(st[1], cmd.Subcommands)
+	if !ok {
+		return res
+	}
+	res.Suggest = p.with(0, 1, fix).String()
+	res.Output = strings.TrimRight(res.Output, "\n") + fmt.Sprintf("\nDid you mean '%s %s'?", strings.ToLower(st[0]), fix)
+	return res
+}
+
+// with returns a copy of p with word j of stage i replaced.
+func (p *pipeline) with(i, j int, word string) *pipeline {
+	q := *p
+	q.stages = make([][]string, len(p.stages))
+	for k, st := range p.stages {
+		q.stages[k] = append([]string(nil), st...)
+	}
+	q.stages[i][j] = word
+	return &q
+}
@@ -0,0 +1,78 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+func TestUnknownCommand(t *testing.T) {
+	e := newTestEngine(t)
+	if err := e.store.SetAlias("deploy", "echo deploying"); err != nil {
+		t.Fatal(err)
+	}
+	e.RunScript("t", "func greet {\necho hi\n}", nil, nil)
+	for _, tc := range []struct {
+		line, out, suggest string
+	}{
+		{"ecoh hi there", "Unknown command 'ecoh'. Did you mean 'echo'?", "echo hi there"},
+		{"ECOH hi", "Unknown command 'ecoh'. Did you mean 'echo'?", "echo hi"},
+		{"deplyo now", "Unknown command 'deplyo'. Did you mean 'deploy'?", "deploy now"},
+		{"gret", "Unknown command 'gret'. Did you mean 'greet'?", "greet"},
+		{"echo 'a b' | grpe a > out.txt", "Unknown command 'grpe'. Did you mean 'grep'?", "echo 'a b' | grep a > out.txt"},
+		{"xyzzy", "Unknown command 'xyzzy'. Try 'help'.", ""},
+	} {
+		res := e.Execute(tc.line)
+		if res.Code != 127 || res.Output != tc.out || res.Suggest != tc.suggest {
+			t.Errorf("%s: got %q (code %d, suggest %q), want %q, suggest %q", tc.line, res.Output, res.Code, res.Suggest, tc.out, tc.suggest)
+		}
+	}
+}
+
+func TestSuggestSubcommand(t *testing.T) {
+	e := newTestEngine(t)
+	err := e.registry.Register(commands.Command{
+		Name:        "box",
+		Subcommands: []string{"open", "close", "list"},
+		Run: func(ctx context.Context, args []string) commands.Result {
+			switch args[0] {
+			case "open", "close", "list":
+				return commands.OK(args[0])
+			case "stuck":
+				return commands.Result{Err: context.Canceled, Code: 130}
+			}
+			return commands.Failf("box: unknown subcommand '%s'\n", args[0])
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		line, out, suggest string
+	}{
+		{"box lsit -a", "box: unknown subcommand 'lsit'\nDid you mean 'box list'?", "box list -a"},
+		{"BOX opne", "box: unknown subcommand 'opne'\nDid you mean 'box open'?", "BOX open"},
+		{"box xyzzy", "box: unknown subcommand 'xyzzy'\n", ""},
+		{"box stuck", "", ""},
+		{"box list", "list", ""},
+		// Only a command run alone is corrected.
+		{"echo x | box lsit", "box: unknown subcommand 'lsit'\n", ""},
+	} {
+		res := e.Execute(tc.line)
+		if res.Output != tc.out || res.Suggest != tc.suggest {
+			t.Errorf("%s: got %q (suggest %q), want %q (suggest %q)", tc.line, res.Output, res.Suggest, tc.out, tc.suggest)
+		}
+	}
+}
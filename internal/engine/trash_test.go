@@ -0,0 +1,106 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestTrashRestoreCollision checks that two deleted files with the same
+// name get separate trash entries, and that restoring one whose path is
+// taken again fails without losing it.
+func TestTrashRestoreCollision(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the trash is only under XDG_DATA_HOME on Linux")
+	}
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	dir := t.TempDir()
+	e := newTestEngine(t)
+	e.RunScript("cd", "cd '"+dir+"'", nil, nil)
+
+	writeFiles(t, dir, map[string]string{"notes.txt": "first\n"})
+	if res := e.Execute("del notes.txt"); res.Failed() {
+		t.Fatalf("del: %s", res.Output)
+	}
+	writeFiles(t, dir, map[string]string{"notes.txt": "second\n"})
+	if res := e.Execute("del notes.txt"); res.Failed() {
+		t.Fatalf("del: %s", res.Output)
+	}
+	files := filepath.Join(data, "Trash", "files")
+	for name, want := range map[string]string{"notes.txt": "first\n", "notes.2.txt": "second\n"} {
+		if b, err := os.ReadFile(filepath.Join(files, name)); err != nil || string(b) != want {
+			t.Errorf("trash item %s = %q, %v; want %q", name, b, err, want)
+		}
+	}
+
+	writeFiles(t, dir, map[string]string{"notes.txt": "third\n"})
+	res := e.Execute("trash restore notes.2.txt")
+	if !res.Failed() || !strings.Contains(res.Output, "already exists") {
+		t.Errorf("restore over an existing file: got %q", res.Output)
+	}
+	if _, err := os.Stat(filepath.Join(files, "notes.2.txt")); err != nil {
+		t.Errorf("the refused item left the trash: %v", err)
+	}
+	if got := fileTree(t, dir); !reflect.DeepEqual(got, map[string]string{"notes.txt": "third\n"}) {
+		t.Errorf("a refused restore changed the directory: %v", got)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if res := e.Execute("trash restore notes.2.txt"); res.Failed() {
+		t.Fatalf("restore: %s", res.Output)
+	}
+	if got := fileTree(t, dir); !reflect.DeepEqual(got, map[string]string{"notes.txt": "second\n"}) {
+		t.Errorf("after restore the directory is %v", got)
+	}
+	list := e.Execute("trash list").Output
+	if strings.Contains(list, "notes.2.txt") || !strings.Contains(list, "notes.txt") {
+		t.Errorf("trash list after restore: %q", list)
+	}
+
+	// Undoing the restore sends the file back to the trash.
+	if res := e.Execute("undo"); res.Failed() {
+		t.Fatalf("undo restore: %s", res.Output)
+	}
+	if got := fileTree(t, dir); len(got) != 0 {
+		t.Errorf("after undoing the restore the directory is %v", got)
+	}
+}
+
+// TestTrashRestoreByName checks that an original file name restores the
+// most recently deleted item with that name.
+func TestTrashRestoreByName(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	e := newTestEngine(t)
+	e.RunScript("cd", "cd '"+dir+"'", nil, nil)
+	writeFiles(t, dir, map[string]string{"a/notes.txt": "old\n"})
+	if res := e.Execute("del a/notes.txt"); res.Failed() {
+		t.Fatalf("del: %s", res.Output)
+	}
+	if res := e.Execute("trash restore missing.txt"); !res.Failed() {
+		t.Errorf("restoring an unknown name: got %q", res.Output)
+	}
+	if res := e.Execute("trash restore notes.txt"); res.Failed() {
+		t.Fatalf("restore: %s", res.Output)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "a", "notes.txt")); err != nil || string(b) != "old\n" {
+		t.Errorf("restored file = %q, %v", b, err)
+	}
+}
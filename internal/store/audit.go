@@ -0,0 +1,144 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// auditKeep is how many audit events are kept; older ones are dropped as
+// new ones are added.
+const auditKeep = 50000
+
+// AuditEvent is one entry of the audit trail. Kind "exec" is a command
+// line that ran: its status is "ok", "failed" or "cancelled", and Files
+// lists what it changed. Other kinds, such as "sandbox" or "protected",
+// record something the safety policy refused; their status is "refused".
+type AuditEvent struct {
+	ID       uint64    `json:"id"`
+	Time     time.Time `json:"ts"`
+	Kind     string    `json:"kind"`
+	Command  string    `json:"cmd,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+	Cwd      string    `json:"cwd,omitempty"`
+	Duration int64     `json:"duration_ms,omitempty"`
+	Code     int       `json:"code,omitempty"`
+	Output   int       `json:"output_bytes,omitempty"`
+	Files    []string  `json:"files,omitempty"`
+	Job      int       `json:"job,omitempty"`
+	DryRun   bool      `json:"dry_run,omitempty"`
+}
+
+// Verb is the first word of the event's command line.
+func (ev AuditEvent) Verb() string {
+	if f := strings.Fields(ev.Command); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+// AuditFilter selects audit events. Empty fields match everything; File
+// matches part of a touched path or of the command line.
+type AuditFilter struct {
+	Since, Until time.Time
+	Kind         string
+	Verb         string
+	Status       string
+	File         string
+}
+
+func (f AuditFilter) Match(ev AuditEvent) bool {
+	if !f.Since.IsZero() && ev.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !ev.Time.Before(f.Until) {
+		return false
+	}
+	if f.Kind != "" && !strings.EqualFold(ev.Kind, f.Kind) {
+		return false
+	}
+	if f.Verb != "" && !strings.EqualFold(ev.Verb(), f.Verb) {
+		return false
+	}
+	if f.Status != "" && !strings.EqualFold(ev.Status, f.Status) {
+		return false
+	}
+	if f.File != "" {
+		found := strings.Contains(ev.Command, f.File)
+		for _, p := range ev.Files {
+			found = found || strings.Contains(p, f.File)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// AddAudit appends ev to the audit trail.
+func (s *Store) AddAudit(ev AuditEvent) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(auditBucket))
+		if bk == nil {
+			return errors.New("audit bucket missing")
+		}
+		id, err := bk.NextSequence()
+		if err != nil {
+			return err
+		}
+		ev.ID = id
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		if id > auditKeep {
+			if err := bk.Delete(seqKey(id - auditKeep)); err != nil {
+				return err
+			}
+		}
+		return bk.Put(seqKey(id), b)
+	})
+}
+
+// AuditEvents returns the events that match f, newest first. A limit of
+// 0 or less returns all of them.
+func (s *Store) AuditEvents(f AuditFilter, limit int) ([]AuditEvent, error) {
+	out := []AuditEvent{}
+	err := s.view(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(auditBucket))
+		if bk == nil {
+			return nil
+		}
+		c := bk.Cursor()
+		for k, v := c.Last(); k != nil && (limit <= 0 || len(out) < limit); k, v = c.Prev() {
+			var ev AuditEvent
+			if err := json.Unmarshal(v, &ev); err != nil {
+				continue
+			}
+			if f.Match(ev) {
+				out = append(out, ev)
+			}
+		}
+		return nil
+	})
+	// Jobs are logged when they end but carry their start time, so the
+	// stored order is not quite the time order.
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+	return out, err
+}
@@ -0,0 +1,125 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Operation is one entry of the undo journal: the file changes a single
+// command made, in the order it made them.
+type Operation struct {
+	ID      uint64    `json:"id"`
+	Time    time.Time `json:"ts"`
+	Command string    `json:"cmd"`
+	Steps   []Step    `json:"steps"`
+	Undone  bool      `json:"undone,omitempty"`
+}
+
+// Step is a single change. Kind is "move" (From was renamed to To),
+// "delete" (From was moved to Staged), "create" (an empty directory or
+// file was made at To), "copy" (To was written by a copy), "overwrite"
+// (From was changed in place; Staged holds its old contents), "append"
+// (data was added to From, which was Size bytes long before) or
+// "restore" (a trash item was moved back to To).
+type Step struct {
+	Kind   string `json:"kind"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Staged string `json:"staged,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+}
+
+func seqKey(id uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, id)
+	return k
+}
+
+// AddOperation appends op to the journal and returns its ID.
+func (s *Store) AddOperation(op Operation) (uint64, error) {
+	err := s.update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(journalBucket))
+		if bk == nil {
+			return errors.New("journal bucket missing")
+		}
+		id, err := bk.NextSequence()
+		if err != nil {
+			return err
+		}
+		op.ID = id
+		b, err := json.Marshal(op)
+		if err != nil {
+			return err
+		}
+		return bk.Put(seqKey(id), b)
+	})
+	return op.ID, err
+}
+
+// SaveOperation overwrites an existing journal entry.
+func (s *Store) SaveOperation(op Operation) error {
+	b, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(journalBucket))
+		if bk == nil {
+			return errors.New("journal bucket missing")
+		}
+		return bk.Put(seqKey(op.ID), b)
+	})
+}
+
+func (s *Store) Operation(id uint64) (Operation, bool, error) {
+	var op Operation
+	found := false
+	err := s.view(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(journalBucket))
+		if bk == nil {
+			return nil
+		}
+		v := bk.Get(seqKey(id))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &op)
+	})
+	return op, found, err
+}
+
+// Operations returns up to limit journal entries, newest first.
+func (s *Store) Operations(limit int) ([]Operation, error) {
+	out := []Operation{}
+	err := s.view(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(journalBucket))
+		if bk == nil {
+			return nil
+		}
+		c := bk.Cursor()
+		for k, v := c.Last(); k != nil && len(out) < limit; k, v = c.Prev() {
+			var op Operation
+			if err := json.Unmarshal(v, &op); err == nil {
+				out = append(out, op)
+			}
+		}
+		return nil
+	})
+	return out, err
+}
@@ -16,6 +16,9 @@ import (
 	"errors"
 	"os"
 	"path"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
@@ -24,13 +27,43 @@ import (
 const (
 	historyBucket = "history"
 	metaBucket    = "meta"
+	aliasBucket   = "aliases"
+	journalBucket = "journal"
+	auditBucket   = "audit"
+
+	// historyIndexBucket maps each command to its key in historyBucket.
+	historyIndexBucket = "history_index"
 )
 
+// Store keeps what outlives a session in a bbolt database. The database
+// is opened for each read or write and closed again, rather than held
+// open, because bbolt locks the file for as long as it is open: this way
+// every rootshell running, not just the tabs of one, shares the history,
+// aliases and settings.
 type Store struct {
-	db *bolt.DB
+	// mu makes the reads and writes of this process take turns, since
+	// each holds the file lock; path is empty once the store is closed.
+	mu   sync.Mutex
+	path string
 }
 
+// lockWait is how long a read or write waits for another rootshell to
+// release the database.
+const lockWait = 2 * time.Second
+
+// schemaVersion is the layout of the database NewStore upgrades to, and
+// schemaKey where the meta bucket records the layout it has. Version 1
+// keys history entries by their Seq.
+const (
+	schemaVersion = 1
+	schemaKey     = "schema"
+)
+
+// HistoryEntry is one command line run at the prompt. Seq numbers the
+// runs in order and never changes, so `history` and !n agree on it even
+// after an earlier run of the same line is dropped.
 type HistoryEntry struct {
+	Seq       uint64    `json:"seq,omitempty"`
 	Timestamp time.Time `json:"ts"`
 	Cmd       string    `json:"cmd"`
 }
@@ -41,70 +74,248 @@ func NewStore(pathStr string) (*Store, error) {
 		_ = os.MkdirAll(dir, 0755)
 	}
 
-	db, err := bolt.Open(pathStr, 0600, &bolt.Options{Timeout: 1 * time.Second})
-	if err != nil {
-		return nil, err
-	}
-	err = db.Update(func(tx *bolt.Tx) error {
-		if _, e := tx.CreateBucketIfNotExists([]byte(historyBucket)); e != nil {
-			return e
-		}
-		if _, e := tx.CreateBucketIfNotExists([]byte(metaBucket)); e != nil {
-			return e
+	s := &Store{path: pathStr}
+	err := s.update(func(tx *bolt.Tx) error {
+		for _, name := range []string{historyBucket, historyIndexBucket, metaBucket, aliasBucket, journalBucket, auditBucket} {
+			if _, e := tx.CreateBucketIfNotExists([]byte(name)); e != nil {
+				return e
+			}
 		}
-		return nil
+		return upgrade(tx)
 	})
 	if err != nil {
-		db.Close()
 		return nil, err
 	}
-	return &Store{db: db}, nil
+	return s, nil
 }
 
-func (s *Store) Close() error {
-	if s.db == nil {
+// upgrade brings a database saved by an older rootshell to
+// schemaVersion.
+func upgrade(tx *bolt.Tx) error {
+	meta := tx.Bucket([]byte(metaBucket))
+	version, _ := strconv.Atoi(string(meta.Get([]byte(schemaKey))))
+	if version >= schemaVersion {
 		return nil
 	}
-	return s.db.Close()
+	if version < 1 {
+		if err := migrateHistory(tx.Bucket([]byte(historyBucket)), tx.Bucket([]byte(historyIndexBucket))); err != nil {
+			return err
+		}
+	}
+	return meta.Put([]byte(schemaKey), []byte(strconv.Itoa(schemaVersion)))
 }
 
-func (s *Store) SaveHistory(cmd string) error {
-	if s.db == nil {
+// Close makes later reads and writes fail. The database is not held
+// open, so there is nothing else to release.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path = ""
+	return nil
+}
+
+// update runs fn in a read-write transaction.
+func (s *Store) update(fn func(tx *bolt.Tx) error) error {
+	return s.with(false, func(db *bolt.DB) error { return db.Update(fn) })
+}
+
+// view runs fn in a read-only transaction. Other rootshells may read at
+// the same time.
+func (s *Store) view(fn func(tx *bolt.Tx) error) error {
+	return s.with(true, func(db *bolt.DB) error { return db.View(fn) })
+}
+
+func (s *Store) with(readOnly bool, fn func(db *bolt.DB) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
 		return errors.New("db not opened")
 	}
-	entry := HistoryEntry{Timestamp: time.Now().UTC(), Cmd: cmd}
-	b, _ := json.Marshal(entry)
-	return s.db.Update(func(tx *bolt.Tx) error {
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: lockWait, ReadOnly: readOnly})
+	if err != nil {
+		return err
+	}
+	err = fn(db)
+	if cerr := db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// SaveHistory adds cmd to the history. An earlier run of the same
+// command is dropped, so each command appears once, at its latest run.
+func (s *Store) SaveHistory(cmd string) error {
+	return s.update(func(tx *bolt.Tx) error {
 		bk := tx.Bucket([]byte(historyBucket))
-		if bk == nil {
+		idx := tx.Bucket([]byte(historyIndexBucket))
+		if bk == nil || idx == nil {
 			return errors.New("history bucket missing")
 		}
-		key := []byte(entry.Timestamp.Format(time.RFC3339Nano))
+		seq, err := bk.NextSequence()
+		if err != nil {
+			return err
+		}
+		entry := HistoryEntry{Seq: seq, Timestamp: time.Now().UTC(), Cmd: cmd}
+		b, _ := json.Marshal(entry)
+		if old := idx.Get([]byte(cmd)); old != nil {
+			if err := bk.Delete(old); err != nil {
+				return err
+			}
+		}
+		key := seqKey(seq)
+		if err := idx.Put([]byte(cmd), key); err != nil {
+			return err
+		}
 		return bk.Put(key, b)
 	})
 }
 
-func (s *Store) ListHistory(limit int) ([]string, error) {
-	if s.db == nil {
-		return nil, errors.New("db not opened")
+// migrateHistory moves entries saved under their timestamp, as text, to
+// keys made from their Seq, so the cursor order is the order they were
+// run in even within one second. Entries saved before they were numbered
+// get a Seq in that order. The index is updated to the new keys.
+func migrateHistory(bk, idx *bolt.Bucket) error {
+	type saved struct {
+		key []byte
+		en  HistoryEntry
+		ok  bool
 	}
-	out := []string{}
-	err := s.db.View(func(tx *bolt.Tx) error {
+	var old []saved
+	c := bk.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if len(k) == 8 {
+			continue
+		}
+		o := saved{key: append([]byte(nil), k...)}
+		o.ok = json.Unmarshal(v, &o.en) == nil
+		old = append(old, o)
+	}
+	sort.SliceStable(old, func(i, j int) bool { return old[i].en.Timestamp.Before(old[j].en.Timestamp) })
+	for _, o := range old {
+		if err := bk.Delete(o.key); err != nil {
+			return err
+		}
+		if !o.ok {
+			continue
+		}
+		if o.en.Seq == 0 {
+			seq, err := bk.NextSequence()
+			if err != nil {
+				return err
+			}
+			o.en.Seq = seq
+		}
+		b, _ := json.Marshal(o.en)
+		if err := bk.Put(seqKey(o.en.Seq), b); err != nil {
+			return err
+		}
+		if err := idx.Put([]byte(o.en.Cmd), seqKey(o.en.Seq)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// History returns up to limit of the latest commands, oldest first and
+// each only once. A limit of 0 or less returns all of them.
+func (s *Store) History(limit int) ([]HistoryEntry, error) {
+	out := []HistoryEntry{}
+	err := s.view(func(tx *bolt.Tx) error {
 		bk := tx.Bucket([]byte(historyBucket))
 		if bk == nil {
 			return nil
 		}
+		seen := map[string]bool{}
 		c := bk.Cursor()
-		k, v := c.Last()
-		count := 0
-		for ; k != nil && count < limit; k, v = c.Prev() {
+		for k, v := c.Last(); k != nil && (limit <= 0 || len(out) < limit); k, v = c.Prev() {
 			var en HistoryEntry
-			if err := json.Unmarshal(v, &en); err == nil {
-				out = append(out, en.Timestamp.Format("2006-01-02 15:04:05")+"  "+en.Cmd)
+			if err := json.Unmarshal(v, &en); err != nil || seen[en.Cmd] {
+				continue
+			}
+			seen[en.Cmd] = true
+			out = append(out, en)
+		}
+		return nil
+	})
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, err
+}
+
+func (s *Store) SetAlias(name, expansion string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(aliasBucket))
+		if bk == nil {
+			return errors.New("aliases bucket missing")
+		}
+		return bk.Put([]byte(name), []byte(expansion))
+	})
+}
+
+// DeleteAlias removes an alias and reports whether it existed.
+func (s *Store) DeleteAlias(name string) (bool, error) {
+	found := false
+	err := s.update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(aliasBucket))
+		if bk == nil {
+			return nil
+		}
+		found = bk.Get([]byte(name)) != nil
+		return bk.Delete([]byte(name))
+	})
+	return found, err
+}
+
+func (s *Store) Alias(name string) (string, bool) {
+	var out []byte
+	_ = s.view(func(tx *bolt.Tx) error {
+		if bk := tx.Bucket([]byte(aliasBucket)); bk != nil {
+			if v := bk.Get([]byte(name)); v != nil {
+				out = append([]byte(nil), v...)
 			}
-			count++
 		}
 		return nil
 	})
+	return string(out), out != nil
+}
+
+func (s *Store) Aliases() (map[string]string, error) {
+	out := map[string]string{}
+	err := s.view(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(aliasBucket))
+		if bk == nil {
+			return nil
+		}
+		return bk.ForEach(func(k, v []byte) error {
+			out[string(k)] = string(v)
+			return nil
+		})
+	})
 	return out, err
 }
+
+// SetSetting saves an option that outlives the session.
+func (s *Store) SetSetting(name, value string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(metaBucket))
+		if bk == nil {
+			return errors.New("meta bucket missing")
+		}
+		return bk.Put([]byte("setting:"+name), []byte(value))
+	})
+}
+
+// Setting returns a saved option and whether it was set.
+func (s *Store) Setting(name string) (string, bool) {
+	var out []byte
+	_ = s.view(func(tx *bolt.Tx) error {
+		if bk := tx.Bucket([]byte(metaBucket)); bk != nil {
+			if v := bk.Get([]byte("setting:" + name)); v != nil {
+				out = append([]byte(nil), v...)
+			}
+		}
+		return nil
+	})
+	return string(out), out != nil
+}
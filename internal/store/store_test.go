@@ -0,0 +1,243 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func historyCmds(t *testing.T, s *Store) ([]string, []uint64) {
+	t.Helper()
+	h, err := s.History(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cmds []string
+	var seqs []uint64
+	for _, en := range h {
+		cmds = append(cmds, en.Cmd)
+		seqs = append(seqs, en.Seq)
+	}
+	return cmds, seqs
+}
+
+func TestSaveHistory(t *testing.T) {
+	s, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	// Many lines a second apart by less than the clock's resolution, or
+	// with timestamps that end in zeros, must keep their order.
+	var want []string
+	for i := 0; i < 200; i++ {
+		cmd := "echo " + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		want = append(want, cmd)
+		if err := s.SaveHistory(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := historyCmds(t, s); !reflect.DeepEqual(got, want) {
+		t.Fatalf("history out of order: %q", got)
+	}
+
+	s.SaveHistory(want[0])
+	got, seqs := historyCmds(t, s)
+	if len(got) != 200 || got[199] != want[0] || seqs[199] != 201 || got[0] != want[1] || seqs[0] != 2 {
+		t.Errorf("after running %q again: %q, seqs %v", want[0], got[len(got)-3:], seqs[len(seqs)-3:])
+	}
+	if h, _ := s.History(2); len(h) != 2 || h[1].Cmd != want[0] {
+		t.Errorf("History(2) = %+v", h)
+	}
+}
+
+// TestMigrateHistory checks that entries saved under their timestamp
+// are moved to numbered keys in the order they were run, although
+// their keys did not sort that way.
+func TestMigrateHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := []HistoryEntry{
+		{Timestamp: base.Add(100 * time.Millisecond), Cmd: "first"},
+		{Timestamp: base.Add(150 * time.Millisecond), Cmd: "second"},
+		{Timestamp: base.Add(200 * time.Millisecond), Cmd: "third"},
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bk, err := tx.CreateBucket([]byte(historyBucket))
+		if err != nil {
+			return err
+		}
+		for _, en := range old {
+			b, _ := json.Marshal(en)
+			if err := bk.Put([]byte(en.Timestamp.Format(time.RFC3339Nano)), b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	cmds, seqs := historyCmds(t, s)
+	if !reflect.DeepEqual(cmds, []string{"first", "second", "third"}) || !reflect.DeepEqual(seqs, []uint64{1, 2, 3}) {
+		t.Fatalf("migrated history: %q, seqs %v", cmds, seqs)
+	}
+	if err := s.SaveHistory("first"); err != nil {
+		t.Fatal(err)
+	}
+	cmds, seqs = historyCmds(t, s)
+	if !reflect.DeepEqual(cmds, []string{"second", "third", "first"}) || !reflect.DeepEqual(seqs, []uint64{2, 3, 4}) {
+		t.Errorf("after running first again: %q, seqs %v", cmds, seqs)
+	}
+}
+
+// TestMigrateOnce checks that the history is migrated when an old
+// database is first opened and not scanned again after that.
+func TestMigrateOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stray := []byte("2025-06-01T12:00:00Z")
+	err = db.Update(func(tx *bolt.Tx) error {
+		if v := tx.Bucket([]byte(metaBucket)).Get([]byte(schemaKey)); string(v) != fmt.Sprint(schemaVersion) {
+			return fmt.Errorf("schema version %q, want %d", v, schemaVersion)
+		}
+		return tx.Bucket([]byte(historyBucket)).Put(stray, []byte(`{"cmd":"stray"}`))
+	})
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	err = s.view(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(historyBucket)).Get(stray) == nil {
+			return fmt.Errorf("a store already migrated was migrated again")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// TestSharedStore checks that two stores on one file, as two rootshells
+// have, can both write at once and see each other's history.
+func TestSharedStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	a, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	var wg sync.WaitGroup
+	for i, s := range []*Store{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := s.SaveHistory(fmt.Sprintf("echo %d-%d", i, j)); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, s := range []*Store{a, b} {
+		if cmds, _ := historyCmds(t, s); len(cmds) != 20 {
+			t.Errorf("history has %d lines, want 20: %q", len(cmds), cmds)
+		}
+	}
+
+	a.Close()
+	if err := a.SaveHistory("late"); err == nil {
+		t.Error("a closed store saved history")
+	}
+	if err := b.SaveHistory("still open"); err != nil {
+		t.Errorf("closing one store broke the other: %v", err)
+	}
+}
+
+// TestSharedStoreProcess checks that history saved by another process
+// shows up in a store that is open, and that the open store does not
+// keep the other process out.
+func TestSharedStoreProcess(t *testing.T) {
+	if path := os.Getenv("STORE_TEST_SAVE"); path != "" {
+		s, err := NewStore(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := s.SaveHistory("from the other process"); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.SaveHistory("from this process"); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSharedStoreProcess$")
+	cmd.Env = append(os.Environ(), "STORE_TEST_SAVE="+path)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("other process: %v: %s", err, out)
+	}
+	if cmds, _ := historyCmds(t, s); !reflect.DeepEqual(cmds, []string{"from this process", "from the other process"}) {
+		t.Errorf("history: %q", cmds)
+	}
+}
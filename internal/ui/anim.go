@@ -0,0 +1,106 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// frame is the shortest time between two steps of typing out output;
+// faster speeds type several runes per frame.
+const frame = 16 * time.Millisecond
+
+// printTickMsg asks for the next step of typing out the output of run
+// seq, so a tick left over from output that was flushed is ignored.
+type printTickMsg int
+
+// loadAnim picks up the `set anim` options.
+func (m *session) loadAnim() {
+	m.animOn = m.engine.Setting("anim") == "on"
+	m.animSpeed, _ = strconv.Atoi(m.engine.Setting("anim speed"))
+	m.animLines, _ = strconv.Atoi(m.engine.Setting("anim lines"))
+	m.animSpeed = max(m.animSpeed, 1)
+}
+
+// animate reports whether output of n lines is typed out rather than
+// shown at once.
+func (m session) animate(n int) bool {
+	return m.animOn && n <= m.animLines
+}
+
+// printStep is how long each step of typing out takes and how many runes
+// it types.
+func (m session) printStep() (time.Duration, int) {
+	d := time.Second / time.Duration(m.animSpeed)
+	if d >= frame {
+		return d, 1
+	}
+	return frame, int((frame + d - 1) / d)
+}
+
+func (m session) printTick(d time.Duration) tea.Cmd {
+	seq := m.runSeq
+	return tea.Tick(d, func(time.Time) tea.Msg { return printTickMsg(seq) })
+}
+
+// linePause is the wait after each line, as long as typing a few
+// characters.
+func (m session) linePause() time.Duration {
+	d, n := m.printStep()
+	return max(d, 7*d/time.Duration(n))
+}
+
+// typeOut types the next runes of the output being printed.
+func (m session) typeOut() (tea.Model, tea.Cmd) {
+	if m.printLineIndex >= len(m.printLines) {
+		m.printing = false
+		m.printPlaceholderIdx = -1
+		return m, nil
+	}
+	idx := m.printPlaceholderIdx + m.printLineIndex
+	for idx >= len(m.outputBuf) {
+		m.outputBuf = append(m.outputBuf, outLine{kind: m.printKind})
+	}
+	runes := []rune(m.printLines[m.printLineIndex])
+	d, n := m.printStep()
+	if m.printCharIndex < len(runes) {
+		end := min(m.printCharIndex+n, len(runes))
+		m.outputBuf[idx].text += string(runes[m.printCharIndex:end])
+		m.printCharIndex = end
+		return m, m.printTick(d)
+	}
+	m.printLineIndex++
+	m.printCharIndex = 0
+	if m.printLineIndex < len(m.printLines) {
+		m.outputBuf = append(m.outputBuf, outLine{kind: m.printKind})
+		return m, m.printTick(m.linePause())
+	}
+	m.printing = false
+	m.printPlaceholderIdx = -1
+	return m, nil
+}
+
+// flushPrint shows the rest of the output being printed at once.
+func (m *session) flushPrint() {
+	for i := m.printLineIndex; i < len(m.printLines); i++ {
+		idx := m.printPlaceholderIdx + i
+		for idx >= len(m.outputBuf) {
+			m.outputBuf = append(m.outputBuf, outLine{kind: m.printKind})
+		}
+		m.outputBuf[idx].text = m.printLines[i]
+	}
+	m.printing = false
+	m.printPlaceholderIdx = -1
+}
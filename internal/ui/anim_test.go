@@ -0,0 +1,83 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPrintStep(t *testing.T) {
+	for _, tc := range []struct {
+		speed int
+		d     time.Duration
+		n     int
+	}{
+		{1, time.Second, 1},
+		{55, time.Second / 55, 1},
+		{63, frame, 2},
+		{120, frame, 2},
+		{1000, frame, 16},
+		{100000, frame, 1600},
+	} {
+		m := session{animSpeed: tc.speed}
+		if d, n := m.printStep(); d != tc.d || n != tc.n {
+			t.Errorf("speed %d: step %v × %d, want %v × %d", tc.speed, d, n, tc.d, tc.n)
+		}
+		if p := m.linePause(); p < tc.d {
+			t.Errorf("speed %d: line pause %v is shorter than a step", tc.speed, p)
+		}
+	}
+}
+
+func TestAnimate(t *testing.T) {
+	m := session{animOn: true, animLines: 3}
+	for n, want := range map[int]bool{1: true, 3: true, 4: false} {
+		if got := m.animate(n); got != want {
+			t.Errorf("animate(%d) = %v with anim lines 3", n, got)
+		}
+	}
+	m.animLines = 0
+	if m.animate(1) {
+		t.Error("anim lines 0 still types out output")
+	}
+	m = session{animLines: 20}
+	if m.animate(1) {
+		t.Error("anim off still types out output")
+	}
+}
+
+// TestTypeOut types out two lines a few runes at a time, then skips the
+// rest as a keypress does.
+func TestTypeOut(t *testing.T) {
+	m := session{animOn: true, animSpeed: 120, animLines: 20}
+	m.outputBuf = []outLine{{text: "$ echo"}, {}}
+	m.printLines = []string{"hello", "world"}
+	m.printing = true
+	m.printPlaceholderIdx = 1
+	next, cmd := m.typeOut()
+	m = next.(session)
+	if m.outputBuf[1].text != "he" || cmd == nil {
+		t.Fatalf("first step typed %q", m.outputBuf[1].text)
+	}
+	for i := 0; i < 3; i++ {
+		next, _ = m.typeOut()
+		m = next.(session)
+	}
+	if m.outputBuf[1].text != "hello" || len(m.outputBuf) != 3 || m.printLineIndex != 1 {
+		t.Fatalf("after the first line: %+v, line %d", m.outputBuf, m.printLineIndex)
+	}
+	m.flushPrint()
+	if m.printing || m.printPlaceholderIdx != -1 || m.outputBuf[2].text != "world" || len(m.outputBuf) != 3 {
+		t.Errorf("after skipping: printing %v, %+v", m.printing, m.outputBuf)
+	}
+}
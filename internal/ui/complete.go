@@ -0,0 +1,163 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+	"github.com/0xrootAnon/0xRootShell/internal/engine"
+)
+
+// popupRows is how many candidates the completion popup shows at once.
+const popupRows = 8
+
+type completionMsg struct {
+	line string
+	pos  int
+	comp engine.Completion
+}
+
+// complete asks the engine, off the UI goroutine, what could finish the
+// word before the cursor.
+func (m session) complete() tea.Cmd {
+	line, pos := m.input.Value(), m.input.Position()
+	eng := m.engine
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		before := string([]rune(line)[:pos])
+		return completionMsg{line: line, pos: pos, comp: eng.Complete(ctx, before)}
+	}
+}
+
+// showCompletion fills in a single candidate, or the part all of them
+// share and a popup to pick from. It is dropped if the input has changed
+// since Tab was pressed.
+func (m session) showCompletion(msg completionMsg) (tea.Model, tea.Cmd) {
+	if msg.line != m.input.Value() || msg.pos != m.input.Position() || len(msg.comp.Items) == 0 {
+		return m, nil
+	}
+	runes := []rune(msg.line)
+	m.compBefore = string(runes[:msg.pos])[:msg.comp.Start]
+	m.compRest = string(runes[msg.pos:])
+	items := msg.comp.Items
+	if len(items) == 1 {
+		text := items[0].Text
+		if !strings.HasSuffix(strings.TrimRight(text, `'"`), "/") && !strings.HasPrefix(m.compRest, " ") {
+			text += " "
+		}
+		m.fillCompletion(text)
+		return m, nil
+	}
+	m.fillCompletion(commonPrefix(items))
+	m.comp, m.compSel = items, -1
+	return m, nil
+}
+
+// completionKey handles Tab and Shift+Tab while the popup is open,
+// stepping through the candidates, and Esc, which closes it. Any other
+// key closes it too but is then handled as usual.
+func (m *session) completionKey(key string) bool {
+	switch key {
+	case "tab":
+		m.compSel = (m.compSel + 1) % len(m.comp)
+	case "shift+tab":
+		if m.compSel <= 0 {
+			m.compSel = len(m.comp)
+		}
+		m.compSel--
+	case "esc":
+		m.comp = nil
+		return true
+	default:
+		m.comp = nil
+		return false
+	}
+	m.fillCompletion(m.comp[m.compSel].Text)
+	return true
+}
+
+func (m *session) fillCompletion(text string) {
+	m.input.SetValue(m.compBefore + text + m.compRest)
+	m.input.SetCursor(utf8.RuneCountInString(m.compBefore + text))
+}
+
+func commonPrefix(items []commands.Candidate) string {
+	p := items[0].Text
+	for _, it := range items[1:] {
+		for !strings.HasPrefix(it.Text, p) {
+			_, size := utf8.DecodeLastRuneInString(p)
+			p = p[:len(p)-size]
+		}
+	}
+	return p
+}
+
+// popup returns what the popup above the prompt lists, if anything: Tab
+// completions or history search matches, and the one selected.
+func (m session) popup() ([]commands.Candidate, int, string) {
+	if m.rsearching {
+		return m.rmatches, m.rsel, "Ctrl+R/↑↓ to choose"
+	}
+	return m.comp, m.compSel, "Tab/Shift+Tab to choose, Esc to close"
+}
+
+// popupHeight is how many lines the popup takes.
+func (m session) popupHeight() int {
+	items, _, _ := m.popup()
+	if len(items) > popupRows {
+		return popupRows + 1
+	}
+	return len(items)
+}
+
+// renderPopup lists the items around the selected one, with their
+// notes.
+func (m session) renderPopup() string {
+	items, sel, hint := m.popup()
+	first := 0
+	if sel >= popupRows {
+		first = sel - popupRows + 1
+	}
+	last := min(first+popupRows, len(items))
+	width := 0
+	for _, it := range items[first:last] {
+		width = max(width, utf8.RuneCountInString(it.Text))
+	}
+	sb := &strings.Builder{}
+	for i := first; i < last; i++ {
+		it := items[i]
+		line := fmt.Sprintf("  %-*s", width, it.Text)
+		if it.Note != "" {
+			line += "  " + it.Note
+		}
+		if m.width > 0 && utf8.RuneCountInString(line) > m.width {
+			line = string([]rune(line)[:m.width-1]) + "…"
+		}
+		style := popupStyle
+		if i == sel {
+			style = popupSelStyle
+		}
+		sb.WriteString(style.Render(line) + "\n")
+	}
+	if len(items) > popupRows {
+		sb.WriteString(footerStyle.Render(fmt.Sprintf("  %d of %d — %s", max(sel+1, 0), len(items), hint)) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,197 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+)
+
+// rsearchMax is how many history matches Ctrl+R lists.
+const rsearchMax = 50
+
+// idle reports whether the prompt is waiting for a command line.
+func (m session) idle() bool {
+	return !m.booting && !m.printing && !m.running && m.prompt == nil && !m.searching
+}
+
+// historyStep moves through the stored history with Up (dir -1) and Down
+// (dir 1). Only lines starting with what was typed before the first Up
+// are visited, and stepping down past the newest brings that back. The
+// history is read once, on the first Up. All tabs share one store, so
+// lines run in other tabs show up too; a second rootshell process can't
+// open the store while this one holds its lock.
+func (m *session) historyStep(dir int) {
+	if m.hist == nil {
+		if dir > 0 {
+			return
+		}
+		entries, err := m.store.History(0)
+		if err != nil {
+			m.outputBuf = append(m.outputBuf, outLine{text: "history: " + err.Error(), kind: lineError})
+			return
+		}
+		m.histDraft = m.input.Value()
+		m.hist = []string{}
+		for _, e := range entries {
+			if strings.HasPrefix(e.Cmd, m.histDraft) && e.Cmd != m.histDraft {
+				m.hist = append(m.hist, e.Cmd)
+			}
+		}
+		m.histPos = len(m.hist)
+	}
+	pos := m.histPos + dir
+	if pos < 0 || pos > len(m.hist) {
+		return
+	}
+	m.histPos = pos
+	if pos == len(m.hist) {
+		m.input.SetValue(m.histDraft)
+	} else {
+		m.input.SetValue(m.hist[pos])
+	}
+	m.input.CursorEnd()
+}
+
+// startReverseSearch opens the Ctrl+R prompt, which searches the whole
+// stored history as the query is typed. The history is read once here
+// and each query is matched against that copy.
+func (m *session) startReverseSearch() {
+	entries, err := m.store.History(0)
+	if err != nil {
+		m.outputBuf = append(m.outputBuf, outLine{text: "history: " + err.Error(), kind: lineError})
+		return
+	}
+	m.rhist = entries
+	m.rsearching = true
+	m.rdraft = m.input.Value()
+	m.rsearch.SetValue(m.rdraft)
+	m.rsearch.CursorEnd()
+	m.rsearch.Focus()
+	m.setReverseQuery(m.rdraft)
+}
+
+func (m *session) endReverseSearch() {
+	m.rsearching = false
+	m.rhist, m.rmatches = nil, nil
+	m.rsearch.Blur()
+}
+
+// setReverseQuery lists the history lines that contain the letters of q
+// in order, best matches first and newer ones before older ones on a tie.
+func (m *session) setReverseQuery(q string) {
+	m.rmatches, m.rsel = nil, 0
+	type scored struct {
+		c     commands.Candidate
+		score int
+	}
+	var found []scored
+	for i := len(m.rhist) - 1; i >= 0; i-- {
+		e := m.rhist[i]
+		if s, ok := fuzzyScore(e.Cmd, q); ok {
+			found = append(found, scored{commands.Candidate{Text: e.Cmd, Note: e.Timestamp.Local().Format("Jan 2 15:04")}, s})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+	for _, f := range found[:min(len(found), rsearchMax)] {
+		m.rmatches = append(m.rmatches, f.c)
+	}
+}
+
+// fuzzyScore reports whether the runes of q appear in s in order, ignoring
+// case, and scores the match: runs of adjacent letters, letters at the
+// start of a word and an early first letter count for more.
+func fuzzyScore(s, q string) (int, bool) {
+	if q == "" {
+		return 0, true
+	}
+	if strings.Contains(strings.ToLower(s), strings.ToLower(q)) {
+		// A plain substring beats any scattered match.
+		return 1000 - strings.Index(strings.ToLower(s), strings.ToLower(q)), true
+	}
+	qr := []rune(strings.ToLower(q))
+	score, qi, prev := 0, 0, -2
+	var last rune = ' '
+	for i, r := range []rune(s) {
+		if qi < len(qr) && unicode.ToLower(r) == qr[qi] {
+			switch {
+			case i == prev+1:
+				score += 5
+			case !unicode.IsLetter(last) && !unicode.IsDigit(last):
+				score += 3
+			}
+			if qi == 0 {
+				score -= min(i, 20)
+			}
+			prev = i
+			qi++
+		}
+		last = r
+	}
+	return score, qi == len(qr)
+}
+
+// reverseSearchKey handles a key typed at the Ctrl+R prompt.
+func (m session) reverseSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+g":
+		m.endReverseSearch()
+		m.input.SetValue(m.rdraft)
+		m.input.CursorEnd()
+		return m, nil
+	case "ctrl+r", "up", "ctrl+p":
+		if m.rsel < len(m.rmatches)-1 {
+			m.rsel++
+		}
+		return m, nil
+	case "down", "ctrl+n", "ctrl+s":
+		if m.rsel > 0 {
+			m.rsel--
+		}
+		return m, nil
+	case "tab", "right":
+		if len(m.rmatches) > 0 {
+			m.input.SetValue(m.rmatches[m.rsel].Text)
+			m.input.CursorEnd()
+		}
+		m.endReverseSearch()
+		return m, nil
+	case "enter":
+		line := m.rsearch.Value()
+		if len(m.rmatches) > 0 {
+			line = m.rmatches[m.rsel].Text
+		}
+		m.endReverseSearch()
+		return m.submit(line)
+	}
+	prev := m.rsearch.Value()
+	var cmd tea.Cmd
+	m.rsearch, cmd = m.rsearch.Update(msg)
+	if m.rsearch.Value() != prev {
+		m.setReverseQuery(m.rsearch.Value())
+	}
+	return m, cmd
+}
+
+// reverseSearchFooter says how the Ctrl+R search is going.
+func (m session) reverseSearchFooter() string {
+	switch {
+	case len(m.rmatches) == 0:
+		return "history: no matches — Esc cancels"
+	}
+	return "history: Enter runs, Tab edits, Ctrl+R/↑ older, ↓ newer, Esc cancels"
+}
@@ -11,6 +11,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -22,28 +23,73 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
 	"github.com/0xrootAnon/0xRootShell/internal/engine"
 	"github.com/0xrootAnon/0xRootShell/internal/store"
 )
 
-var (
-	artStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#68FF6B")).Bold(true)
-	outputStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#A8FF60"))
-	promptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#B2FF9E"))
-	footerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6BFFB8")).Italic(true)
-	uiAnsiRe    = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
-)
+var uiAnsiRe = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
 
 type tickMsg time.Time
 type bootDoneMsg struct{}
-type printDoneMsg struct{}
 type asyncMsg string
 
-type Model struct {
+// rcDoneMsg carries what ~/.rootshrc did.
+type rcDoneMsg struct{ res commands.Result }
+
+// promptMsg carries a question from a running command.
+type promptMsg struct{ req *engine.PromptRequest }
+
+type execDoneMsg struct {
+	seq int
+	res commands.Result
+}
+
+// outLine is a line of scrollback; kind says how to render it.
+type outLine struct {
+	text string
+	kind lineKind
+}
+
+type lineKind int
+
+const (
+	lineOutput  lineKind = iota
+	lineError            // output of a command that did not succeed
+	lineWarn             // a question or hint from the shell itself
+	lineAsync            // output of a background job
+	lineCommand          // a command line as it was run
+)
+
+func (k lineKind) style() lipgloss.Style {
+	switch k {
+	case lineError:
+		return errorStyle
+	case lineWarn:
+		return warnStyle
+	case lineAsync:
+		return asyncStyle
+	case lineCommand:
+		return promptStyle
+	}
+	return outputStyle
+}
+
+func resultKind(res commands.Result) lineKind {
+	if res.Failed() {
+		return lineError
+	}
+	return lineOutput
+}
+
+// session is one tab: a shell with its own engine, and so its own working
+// directory, jobs and async channel, and its own scrollback and prompt.
+type session struct {
+	id    int
 	ascii string
 	input textinput.Model
 
-	outputBuf []string
+	outputBuf []outLine
 	store     *store.Store
 	lastExec  time.Time
 	engine    *engine.Engine
@@ -61,12 +107,75 @@ type Model struct {
 	printLineIndex      int
 	printCharIndex      int
 	printPlaceholderIdx int
+	printKind           lineKind
 
 	asyncCh chan string
 
-	passwordMode       bool
-	passwordTargetIdx  int
-	passwordTargetSSID string
+	// Scrollback beyond scrollCap lines is spilled to disk; spilled
+	// counts those lines. The viewport follows new output unless pinned
+	// to line top. query, when set, is highlighted and match is the line
+	// of the current match.
+	scrollCap int
+	spilled   int
+	spillFile string
+	spillErr  error
+	pinned    bool
+	top       int
+	searching bool
+	search    textinput.Model
+	query     *regexp.Regexp
+	match     int
+
+	// comp holds the Tab completions shown in the popup and compSel the
+	// one filled in, -1 before Tab picks one. compBefore and compRest are
+	// the input around the word being completed.
+	comp       []commands.Candidate
+	compSel    int
+	compBefore string
+	compRest   string
+
+	// hist is the history Up and Down step through, from the first Up
+	// until another key is pressed; histPos is the line shown and
+	// histDraft what was typed before. rsearching is the Ctrl+R prompt,
+	// with rhist the history read when it opened and rmatches the lines
+	// it found, newest and best first.
+	hist       []string
+	histPos    int
+	histDraft  string
+	rsearching bool
+	rsearch    textinput.Model
+	rhist      []store.HistoryEntry
+	rmatches   []commands.Candidate
+	rsel       int
+	rdraft     string
+
+	theme     string
+	animOn    bool
+	animSpeed int
+	animLines int
+
+	// statusSegs are the status bar segments to show and status what
+	// the last poll found.
+	statusSegs []string
+	status     statusInfo
+
+	// activity is set when output arrives while the tab is in the
+	// background. inTabs is set when there are other tabs, so quitting
+	// closes just this one; closed asks for it to be closed.
+	activity bool
+	inTabs   bool
+	closed   bool
+
+	rc        commands.Result
+	running   bool
+	runSeq    int
+	quitArmed bool
+	suggest   string
+
+	// prompt is the question a command is waiting on, if any; the line
+	// being typed when it came is kept in promptDraft.
+	prompt      *engine.PromptRequest
+	promptDraft string
 }
 
 func sanitizeForUI(s string) string {
@@ -76,7 +185,9 @@ func sanitizeForUI(s string) string {
 	return s
 }
 
-func NewModel(st *store.Store, ascii string) Model {
+// newSession starts a session in dir, or the process working directory if
+// dir is empty. Only a session that boots plays the boot sequence.
+func newSession(st *store.Store, ascii, dir string, boot bool) session {
 	ti := textinput.New()
 	ti.Placeholder = "type a command — e.g. launch chrome, find resume, sys status"
 	ti.Focus()
@@ -91,153 +202,450 @@ func NewModel(st *store.Store, ascii string) Model {
 		"Ready.",
 	}
 
+	search := textinput.New()
+	search.Prompt = ""
+	search.CharLimit = 256
+	search.Width = 70
+	rsearch := search
+
 	ch := make(chan string, 16)
-	m := Model{
+	eng := engine.NewEngine(st, ch)
+	if err := eng.Registry().Register(themeCommand(st)); err != nil {
+		panic(err)
+	}
+	m := session{
 		ascii:               ascii,
 		input:               ti,
 		store:               st,
-		engine:              engine.NewEngine(st, ch),
+		engine:              eng,
 		booting:             true,
 		bootLines:           bootMsgs,
 		printPlaceholderIdx: -1,
 		asyncCh:             ch,
+		search:              search,
+		rsearch:             rsearch,
+		match:               -1,
+	}
+	if dir != "" {
+		eng.CmdCd(context.Background(), []string{dir})
+	}
+	// ~/.rootshrc runs from Init, off the UI goroutine; until it is done
+	// the session counts as running a command.
+	m.running = true
+	m.loadSettings()
+	if !boot || !m.animOn || m.engine.Setting("anim boot") == "off" {
+		m.finishBoot()
 	}
 	return m
 }
 
-func (m Model) Init() tea.Cmd {
-	return tea.Batch(bootTickCmd(), listenCmd(m.asyncCh))
+// loadSettings picks up options changed with `set` and `theme`.
+func (m *session) loadSettings() {
+	if n, err := strconv.Atoi(m.engine.Setting("scrollback")); err == nil {
+		m.scrollCap = n
+	}
+	m.loadAnim()
+	m.loadStatus()
+	setColorProfile(m.engine.Setting("colors"))
+	name, _ := m.store.Setting("theme")
+	m.setTheme(name)
+}
+
+func (m session) Init() tea.Cmd {
+	cmds := []tea.Cmd{m.runRC(), listenCmd(m.asyncCh, m.engine.Closed()), listenPrompts(m.engine.Prompts(), m.engine.Closed())}
+	if m.booting {
+		cmds = append(cmds, bootTickCmd())
+	}
+	return tea.Batch(cmds...)
 }
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+// runRC runs ~/.rootshrc. Nothing answers prompts until it is done, so it
+// runs as a script would; the session is interactive afterwards.
+func (m session) runRC() tea.Cmd {
+	eng := m.engine
+	return func() tea.Msg {
+		return rcDoneMsg{eng.RunRC()}
+	}
+}
+
+func (m session) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
+	if !m.booting && !m.printing {
+		m.capScrollback()
+	}
 
 	switch msg := msg.(type) {
 	case tickMsg:
 		return m.handleTick()
+	case printTickMsg:
+		if int(msg) != m.runSeq || !m.printing {
+			return m, nil
+		}
+		return m.typeOut()
 	case asyncMsg:
-		m.outputBuf = append(m.outputBuf, sanitizeForUI(string(msg)))
-		return m, listenCmd(m.asyncCh)
+		m.outputBuf = append(m.outputBuf, outLine{text: sanitizeForUI(string(msg)), kind: lineAsync})
+		return m, listenCmd(m.asyncCh, m.engine.Closed())
+	case promptMsg:
+		return m.showPrompt(msg.req), nil
+	case rcDoneMsg:
+		m.engine.Interactive = true
+		m.running = false
+		m.rc = msg.res
+		m.loadSettings()
+		if !m.booting {
+			m.showRC()
+		}
+		return m, nil
+	case execDoneMsg:
+		if msg.seq != m.runSeq || !m.running {
+			return m, nil
+		}
+		m.running = false
+		if m.prompt != nil && m.prompt.Expired() {
+			m.endPrompt()
+			cmd = listenPrompts(m.engine.Prompts(), m.engine.Closed())
+		}
+		if _, ok := m.engine.ExitRequested(); ok {
+			m.closed = true
+			return m, cmd
+		}
+		m.loadSettings()
+		next, show := m.showResult(msg.res)
+		return next, tea.Batch(cmd, show)
+	case completionMsg:
+		return m.showCompletion(msg)
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress {
+			switch msg.Button {
+			case tea.MouseButtonWheelUp:
+				m.scrollBy(-3)
+			case tea.MouseButtonWheelDown:
+				m.scrollBy(3)
+			}
+		}
+		return m, nil
 	case tea.KeyMsg:
+		if msg.String() != "ctrl+c" {
+			m.quitArmed = false
+			// A key skips the animation and is then handled as usual.
+			if m.booting {
+				m.finishBoot()
+			}
+			if m.printing {
+				m.flushPrint()
+			}
+		}
+		if handled := m.scrollKey(msg.String()); handled {
+			return m, nil
+		}
+		if m.searching && msg.String() != "ctrl+c" {
+			return m.searchKey(msg)
+		}
+		if m.rsearching && msg.String() != "ctrl+c" {
+			return m.reverseSearchKey(msg)
+		}
+		if k := msg.String(); k != "up" && k != "down" {
+			m.hist = nil
+		}
+		if len(m.comp) > 0 && m.completionKey(msg.String()) {
+			return m, nil
+		}
 		switch msg.String() {
-		case "ctrl+c", "esc":
-			return m, tea.Quit
-		case "enter":
-			if m.booting || m.printing {
+		case "ctrl+c":
+			return m.interrupt()
+		case "tab":
+			if !m.booting && !m.printing && !m.running && m.prompt == nil {
+				return m, m.complete()
+			}
+			return m, nil
+		case "/":
+			if m.input.Value() == "" && !m.booting && !m.printing && !m.running && m.prompt == nil {
+				m.startSearch()
 				return m, nil
 			}
-			if m.passwordMode {
-				pwd := strings.TrimSpace(m.input.Value())
-				m.input.SetValue("")
-				if setter, ok := interface{}(&m.input).(interface {
-					SetEchoMode(int)
-					SetEchoCharacter(rune)
-				}); ok {
-					setter.SetEchoMode(0)
-					setter.SetEchoCharacter('*')
+		case "up", "down":
+			if m.idle() && len(m.comp) == 0 {
+				dir := -1
+				if msg.String() == "down" {
+					dir = 1
 				}
-				m.passwordMode = false
-				cmdline := fmt.Sprintf("net wifi connect %d %s", m.passwordTargetIdx+1, pwd)
-				if err := m.store.SaveHistory(cmdline); err != nil {
-					m.outputBuf = append(m.outputBuf, "history save error: "+err.Error())
-				}
-				rawOut := m.engine.Execute(cmdline)
-				lines := strings.Split(rawOut, "\n")
-				sLines := make([]string, 0, len(lines))
-				for _, l := range lines {
-					sLines = append(sLines, sanitizeForUI(l))
-				}
-				m.printLines = sLines
-				m.printLineIndex = 0
-				m.printCharIndex = 0
-				m.printing = true
-				m.outputBuf = append(m.outputBuf, sanitizeForUI(fmt.Sprintf("> %s", cmdline)))
-				m.outputBuf = append(m.outputBuf, "")
-				m.printPlaceholderIdx = len(m.outputBuf) - 1
-				return m, printTickCmd()
+				m.historyStep(dir)
 			}
-
-			val := strings.TrimSpace(m.input.Value())
-			if val != "" {
-				if err := m.store.SaveHistory(val); err != nil {
-					m.outputBuf = append(m.outputBuf, "history save error: "+err.Error())
-				}
-				rawOut := m.engine.Execute(val)
-
-				if strings.HasPrefix(rawOut, "PROMPT_PASSWORD:") {
-					parts := strings.SplitN(rawOut[len("PROMPT_PASSWORD:"):], ":", 2)
-					if len(parts) == 2 {
-						i, _ := strconv.Atoi(parts[0])
-						m.passwordTargetIdx = i
-						m.passwordTargetSSID = parts[1]
-						m.passwordMode = true
-						m.input.SetValue("")
-						if setter, ok := interface{}(&m.input).(interface {
-							SetEchoMode(int)
-							SetEchoCharacter(rune)
-						}); ok {
-							setter.SetEchoMode(1)
-							setter.SetEchoCharacter('*')
-						}
-						m.outputBuf = append(m.outputBuf, sanitizeForUI(fmt.Sprintf("> %s", val)))
-						m.outputBuf = append(m.outputBuf, sanitizeForUI(fmt.Sprintf("(enter password for '%s')", m.passwordTargetSSID)))
-						return m, nil
-					}
-				}
-
-				lines := strings.Split(rawOut, "\n")
-				sLines := make([]string, 0, len(lines))
-				for _, l := range lines {
-					sLines = append(sLines, sanitizeForUI(l))
-				}
-				m.printLines = sLines
-				m.printLineIndex = 0
-				m.printCharIndex = 0
-				m.printing = true
-				m.outputBuf = append(m.outputBuf, sanitizeForUI(fmt.Sprintf("> %s", val)))
-				m.outputBuf = append(m.outputBuf, "") // placeholder for first output line
-				m.printPlaceholderIdx = len(m.outputBuf) - 1
-				m.input.SetValue("")
-				return m, printTickCmd()
+			return m, nil
+		case "ctrl+r":
+			if m.idle() {
+				m.startReverseSearch()
+			}
+			return m, nil
+		case "ctrl+y":
+			if m.suggest == "" || m.booting || m.printing || m.running || m.prompt != nil {
+				return m, nil
+			}
+			line := m.suggest
+			if err := m.store.SaveHistory(m.engine.Redact(line)); err != nil {
+				m.outputBuf = append(m.outputBuf, outLine{text: "history save error: " + err.Error(), kind: lineError})
 			}
+			m.input.SetValue("")
+			return m.run(line)
+		case "enter":
+			if m.prompt != nil {
+				return m.answerPrompt()
+			}
+			if m.booting || m.printing || m.running {
+				return m, nil
+			}
+			return m.submit(m.input.Value())
 		}
 	}
 
-	if !m.booting && !m.printing {
+	if !m.booting && !m.printing && (!m.running || m.prompt != nil) {
 		m.input, cmd = m.input.Update(msg)
 	}
 	return m, cmd
 }
 
-func (m Model) View() string {
+// submit runs a line typed at the prompt, after history expansion, and
+// saves it to the history with any password in it hidden.
+func (m session) submit(val string) (tea.Model, tea.Cmd) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return m, nil
+	}
+	m.input.SetValue("")
+	line, _, err := m.engine.ExpandHistory(val)
+	if err != nil {
+		m.outputBuf = append(m.outputBuf, outLine{text: sanitizeForUI("> " + val), kind: lineCommand}, outLine{text: err.Error(), kind: lineError})
+		return m, nil
+	}
+	if err := m.store.SaveHistory(m.engine.Redact(line)); err != nil {
+		m.outputBuf = append(m.outputBuf, outLine{text: "history save error: " + err.Error(), kind: lineError})
+	}
+	return m.run(line)
+}
+
+// run echoes line and executes it off the UI goroutine so the command can
+// be interrupted; the result comes back as an execDoneMsg.
+func (m session) run(line string) (tea.Model, tea.Cmd) {
+	m.pinned = false
+	m.outputBuf = append(m.outputBuf, outLine{text: sanitizeForUI(fmt.Sprintf("> %s", line)), kind: lineCommand})
+	return m.exec(func() commands.Result { return m.engine.Execute(line) })
+}
+
+func (m session) exec(fn func() commands.Result) (tea.Model, tea.Cmd) {
+	m.suggest = ""
+	m.running = true
+	m.runSeq++
+	seq := m.runSeq
+	return m, func() tea.Msg {
+		return execDoneMsg{seq: seq, res: fn()}
+	}
+}
+
+// showPrompt puts a command's question to the user, setting aside
+// whatever was being typed or searched.
+func (m session) showPrompt(req *engine.PromptRequest) session {
+	if m.booting {
+		m.finishBoot()
+	}
+	if m.printing {
+		m.flushPrint()
+	}
+	if m.rsearching {
+		m.endReverseSearch()
+		m.input.SetValue(m.rdraft)
+	}
+	if m.searching {
+		m.endSearch()
+	}
+	m.comp = nil
+	m.hist = nil
+	m.pinned = false
+	m.prompt = req
+	m.promptDraft = m.input.Value()
+	m.input.SetValue("")
+	m.setPasswordEcho(req.Kind == commands.PromptHidden)
+	m.outputBuf = append(m.outputBuf, outLine{text: sanitizeForUI(req.Message), kind: lineWarn})
+	for i, c := range req.Choices {
+		m.outputBuf = append(m.outputBuf, outLine{text: sanitizeForUI(fmt.Sprintf("  %d) %s", i+1, c)), kind: lineWarn})
+	}
+	return m
+}
+
+// answerPrompt hands the typed answer to the waiting command. An answer
+// that does not fit the question is refused and the question stays. The
+// answer is never saved to history.
+func (m session) answerPrompt() (tea.Model, tea.Cmd) {
+	answer := m.input.Value()
+	if m.prompt.Expired() {
+		m.endPrompt()
+		return m, listenPrompts(m.engine.Prompts(), m.engine.Closed())
+	}
+	if err := m.prompt.Answer(answer); err != nil {
+		m.input.SetValue("")
+		m.outputBuf = append(m.outputBuf, outLine{text: err.Error(), kind: lineError})
+		return m, nil
+	}
+	if m.prompt.Kind == commands.PromptHidden {
+		answer = "(hidden)"
+	}
+	m.outputBuf = append(m.outputBuf, outLine{text: sanitizeForUI("> " + answer), kind: lineCommand})
+	m.endPrompt()
+	return m, listenPrompts(m.engine.Prompts(), m.engine.Closed())
+}
+
+// endPrompt closes the question and brings back the line being typed.
+func (m *session) endPrompt() {
+	m.prompt = nil
+	m.setPasswordEcho(false)
+	m.input.SetValue(m.promptDraft)
+	m.input.CursorEnd()
+	m.promptDraft = ""
+}
+
+// promptLabel stands in for the > prompt while a question is open.
+func (m session) promptLabel() string {
+	switch m.prompt.Kind {
+	case commands.PromptConfirm:
+		return "[y/N] "
+	case commands.PromptHidden:
+		return "(hidden) "
+	case commands.PromptChoice:
+		return fmt.Sprintf("[1-%d] ", len(m.prompt.Choices))
+	}
+	return "? "
+}
+
+func (m session) showResult(res commands.Result) (tea.Model, tea.Cmd) {
+	lines := strings.Split(res.Output, "\n")
+	sLines := make([]string, 0, len(lines))
+	for _, l := range lines {
+		sLines = append(sLines, sanitizeForUI(l))
+	}
+	if res.Suggest != "" {
+		m.suggest = res.Suggest
+		sLines = append(sLines, sanitizeForUI(fmt.Sprintf("(press Ctrl+Y to run: %s)", res.Suggest)))
+	}
+	m.printLines = sLines
+	m.printLineIndex = 0
+	m.printCharIndex = 0
+	m.printing = true
+	m.printKind = resultKind(res)
+	m.outputBuf = append(m.outputBuf, outLine{kind: m.printKind}) // placeholder for first output line
+	m.printPlaceholderIdx = len(m.outputBuf) - 1
+	if !m.animate(len(sLines)) {
+		m.flushPrint()
+		return m, nil
+	}
+	return m.typeOut()
+}
+
+// interrupt handles Ctrl+C: it stops whatever is in progress and returns
+// to the prompt. At an idle, empty prompt a second Ctrl+C quits.
+func (m session) interrupt() (tea.Model, tea.Cmd) {
+	switch {
+	case m.prompt != nil:
+		m.prompt.Cancel()
+		m.endPrompt()
+		m.outputBuf = append(m.outputBuf, outLine{text: "^C", kind: lineError})
+		return m, listenPrompts(m.engine.Prompts(), m.engine.Closed())
+	case m.rsearching:
+		m.endReverseSearch()
+		m.input.SetValue(m.rdraft)
+	case m.searching:
+		m.endSearch()
+	case m.booting:
+		m.finishBoot()
+	case m.running:
+		m.engine.Interrupt()
+		m.running = false
+		m.outputBuf = append(m.outputBuf, outLine{text: "^C", kind: lineError})
+	case m.printing:
+		m.printing = false
+		m.printPlaceholderIdx = -1
+		m.outputBuf = append(m.outputBuf, outLine{text: "^C", kind: lineError})
+	case m.input.Value() != "":
+		m.input.SetValue("")
+	case m.quitArmed:
+		m.closed = true
+	case m.inTabs:
+		m.quitArmed = true
+		m.outputBuf = append(m.outputBuf, outLine{text: "(press Ctrl+C again to close this tab, or type 'exit')", kind: lineWarn})
+	default:
+		m.quitArmed = true
+		m.outputBuf = append(m.outputBuf, outLine{text: "(press Ctrl+C again to quit, or type 'exit')", kind: lineWarn})
+	}
+	return m, nil
+}
+
+// finishBoot ends the boot sequence and shows anything ~/.rootshrc
+// printed, if it is done; otherwise that shows when it is.
+func (m *session) finishBoot() {
+	m.booting = false
+	m.outputBuf = append(m.outputBuf, outLine{})
+	m.showRC()
+}
+
+func (m *session) showRC() {
+	if m.rc.Output == "" {
+		return
+	}
+	for _, l := range strings.Split(m.rc.Output, "\n") {
+		m.outputBuf = append(m.outputBuf, outLine{text: sanitizeForUI(l), kind: resultKind(m.rc)})
+	}
+}
+
+func (m *session) setPasswordEcho(hidden bool) {
+	if hidden {
+		m.input.EchoMode = textinput.EchoPassword
+	} else {
+		m.input.EchoMode = textinput.EchoNormal
+	}
+	m.input.EchoCharacter = '*'
+}
+
+func (m session) View() string {
 	sb := &strings.Builder{}
 	art := centerArt(m.ascii, m.width)
 	sb.WriteString(artStyle.Render(art))
 	sb.WriteString("\n")
 
-	maxLines := m.height - 8
-	if maxLines < 6 {
-		maxLines = 6
+	start, end := m.visible()
+	if start == 0 && m.spilled > 0 {
+		sb.WriteString(footerStyle.Render(m.spillNote()) + "\n")
 	}
-	start := 0
-	if len(m.outputBuf) > maxLines {
-		start = len(m.outputBuf) - maxLines
-	}
-
-	for _, line := range m.outputBuf[start:] {
-		sb.WriteString(outputStyle.Render(sanitizeForUI(line)) + "\n")
+	for i := start; i < end; i++ {
+		sb.WriteString(m.renderLine(i, m.outputBuf[i].kind.style()) + "\n")
 	}
 
-	if m.booting || m.printing {
-		sb.WriteString("\n" + promptStyle.Render("> ") + "(initializing...)" + "\n\n")
+	sb.WriteString(m.renderPopup())
+	if m.rsearching {
+		sb.WriteString("\n" + promptStyle.Render("history: ") + m.rsearch.View() + "\n\n")
+	} else if m.searching {
+		sb.WriteString("\n" + promptStyle.Render("/ ") + m.search.View() + "\n\n")
+	} else if m.prompt != nil {
+		sb.WriteString("\n" + promptStyle.Render(m.promptLabel()) + m.input.View() + "\n\n")
+	} else if m.running {
+		sb.WriteString("\n" + promptStyle.Render("> ") + "(running... Ctrl+C to cancel)" + "\n\n")
+	} else if m.booting {
+		sb.WriteString("\n" + promptStyle.Render("> ") + "(initializing... any key skips)" + "\n\n")
+	} else if m.printing {
+		sb.WriteString("\n" + promptStyle.Render("> ") + "(printing... any key skips)" + "\n\n")
 	} else {
 		sb.WriteString("\n" + promptStyle.Render("> ") + m.input.View() + "\n\n")
 	}
 
-	sb.WriteString(footerStyle.Render("0xRootShell — type 'help' — press ESC or Ctrl+C to quit"))
+	if f := m.scrollFooter(); f != "" {
+		sb.WriteString(footerStyle.Render(f))
+		return sb.String()
+	}
+	if len(m.statusSegs) > 0 {
+		sb.WriteString(footerStyle.Render(m.statusBar(time.Now())))
+		return sb.String()
+	}
+	sb.WriteString(footerStyle.Render("0xRootShell — type 'help' — Ctrl+C cancels — 'exit' or Ctrl+C twice to quit — ↑/Ctrl+R history, PgUp scrolls, / searches"))
 	return sb.String()
 }
 
@@ -267,42 +675,53 @@ func bootTickCmd() tea.Cmd {
 	})
 }
 
-func printTickCmd() tea.Cmd {
-	return tea.Tick(18*time.Millisecond, func(t time.Time) tea.Msg {
-		return tickMsg(t)
-	})
+// listenCmd waits for the next line from a background command, until
+// the session is closed.
+func listenCmd(ch <-chan string, closed <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case s, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			return asyncMsg(s)
+		case <-closed:
+			return nil
+		}
+	}
 }
 
-func listenCmd(ch <-chan string) tea.Cmd {
+// listenPrompts waits for the next question; only one is open at a time.
+func listenPrompts(ch <-chan *engine.PromptRequest, closed <-chan struct{}) tea.Cmd {
 	return func() tea.Msg {
-		s, ok := <-ch
-		if !ok {
+		select {
+		case r := <-ch:
+			return promptMsg{r}
+		case <-closed:
 			return nil
 		}
-		return asyncMsg(s)
 	}
 }
 
-func (m Model) handleTick() (tea.Model, tea.Cmd) {
+func (m session) handleTick() (tea.Model, tea.Cmd) {
 	if m.booting {
 		if m.bootLineIndex >= len(m.bootLines) {
-			m.booting = false
-			m.outputBuf = append(m.outputBuf, "")
+			m.finishBoot()
 			return m, nil
 		}
 		line := m.bootLines[m.bootLineIndex]
 		runes := []rune(line)
 		if m.bootCharIndex == 0 {
-			m.outputBuf = append(m.outputBuf, "")
+			m.outputBuf = append(m.outputBuf, outLine{})
 		}
 		if len(m.outputBuf) == 0 {
-			m.outputBuf = append(m.outputBuf, "")
+			m.outputBuf = append(m.outputBuf, outLine{})
 		}
 		lastIdx := len(m.outputBuf) - 1
-		cur := []rune(m.outputBuf[lastIdx])
+		cur := []rune(m.outputBuf[lastIdx].text)
 		if m.bootCharIndex < len(runes) {
 			cur = append(cur, runes[m.bootCharIndex])
-			m.outputBuf[lastIdx] = string(cur)
+			m.outputBuf[lastIdx].text = string(cur)
 			m.bootCharIndex++
 			return m, bootTickCmd()
 		}
@@ -311,47 +730,5 @@ func (m Model) handleTick() (tea.Model, tea.Cmd) {
 		return m, tea.Tick(300*time.Millisecond, func(t time.Time) tea.Msg { return tickMsg(t) })
 	}
 
-	if m.printing {
-		if len(m.printLines) == 0 {
-			m.printing = false
-			m.printPlaceholderIdx = -1
-			return m, nil
-		}
-		if m.printLineIndex >= len(m.printLines) {
-			m.printing = false
-			m.printPlaceholderIdx = -1
-			return m, nil
-		}
-
-		currLine := m.printLines[m.printLineIndex]
-		runes := []rune(currLine)
-
-		placeholderIdx := m.printPlaceholderIdx + m.printLineIndex
-
-		for placeholderIdx >= len(m.outputBuf) {
-			m.outputBuf = append(m.outputBuf, "")
-		}
-
-		curRunes := []rune(m.outputBuf[placeholderIdx])
-		if m.printCharIndex < len(runes) {
-			curRunes = append(curRunes, runes[m.printCharIndex])
-			m.outputBuf[placeholderIdx] = string(curRunes)
-			m.printCharIndex++
-			return m, printTickCmd()
-		}
-
-		m.printLineIndex++
-		m.printCharIndex = 0
-
-		if m.printLineIndex < len(m.printLines) {
-			m.outputBuf = append(m.outputBuf, "")
-			return m, tea.Tick(120*time.Millisecond, func(t time.Time) tea.Msg { return tickMsg(t) })
-		}
-
-		m.printing = false
-		m.printPlaceholderIdx = -1
-		return m, nil
-	}
-
 	return m, nil
 }
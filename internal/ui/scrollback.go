@@ -0,0 +1,331 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// spillDir is where scrollback that no longer fits under the cap is
+// written, one file per session. A file is removed when its tab closes;
+// spillKeep is how long one left behind by a crash is kept.
+var spillDir = filepath.Join("data", "scrollback")
+
+const spillKeep = 24 * time.Hour
+
+var matchStyle = lipgloss.NewStyle().Reverse(true)
+
+// viewHeight is how many lines of scrollback fit on screen.
+func (m session) viewHeight() int {
+	h := m.height - 8 - m.popupHeight()
+	if h < 6 {
+		return 6
+	}
+	return h
+}
+
+// capScrollback moves the oldest lines beyond the cap to the spill file,
+// a tenth of the cap at a time so it does not happen on every line. It
+// must not run while output is typed out, which refers to the buffer by
+// index.
+func (m *session) capScrollback() {
+	if m.scrollCap <= 0 || len(m.outputBuf) <= m.scrollCap {
+		return
+	}
+	n := len(m.outputBuf) - m.scrollCap + m.scrollCap/10
+	if err := m.spill(m.outputBuf[:n]); err != nil && m.spillErr == nil {
+		m.spillErr = err
+	}
+	m.outputBuf = append([]outLine(nil), m.outputBuf[n:]...)
+	m.spilled += n
+}
+
+func (m *session) spill(lines []outLine) error {
+	if m.spillFile == "" {
+		if err := os.MkdirAll(spillDir, 0755); err != nil {
+			return err
+		}
+		f, err := os.CreateTemp(spillDir, time.Now().Format("2006-01-02_150405")+"-*.log")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		m.spillFile = f.Name()
+	}
+	f, err := os.OpenFile(m.spillFile, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, l := range lines {
+		w.WriteString(l.text + "\n")
+	}
+	err = w.Flush()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// dropSpill removes the spill file, once the tab is closed.
+func (m *session) dropSpill() {
+	if m.spillFile != "" {
+		os.Remove(m.spillFile)
+		m.spillFile = ""
+	}
+}
+
+// pruneSpills removes spill files older than spillKeep, which only a
+// rootshell that did not close its tabs leaves behind.
+func pruneSpills() {
+	entries, err := os.ReadDir(spillDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || time.Since(info.ModTime()) < spillKeep {
+			continue
+		}
+		os.Remove(filepath.Join(spillDir, e.Name()))
+	}
+}
+
+// spillNote is shown above the oldest line kept in memory.
+func (m session) spillNote() string {
+	if m.spillErr != nil {
+		return fmt.Sprintf("(%d older lines dropped: %v)", m.spilled, m.spillErr)
+	}
+	return fmt.Sprintf("(%d older lines saved to %s until this tab closes)", m.spilled, m.spillFile)
+}
+
+// visible returns the part of outputBuf on screen. The viewport follows
+// new output unless it is pinned, by scrolling or searching, to the line
+// numbered top; line numbers count spilled lines, so they stay put when
+// the buffer is capped.
+func (m session) visible() (start, end int) {
+	rows := m.viewHeight()
+	bottom := len(m.outputBuf) - rows
+	if bottom < 0 {
+		bottom = 0
+	}
+	start = bottom
+	if m.pinned {
+		start = min(max(m.top-m.spilled, 0), bottom)
+	}
+	if start == 0 && m.spilled > 0 && rows > 1 {
+		rows--
+	}
+	return start, min(start+rows, len(m.outputBuf))
+}
+
+// scrollBy moves the viewport n lines down, or up if n is negative.
+// Reaching the bottom follows new output again.
+func (m *session) scrollBy(n int) {
+	start, _ := m.visible()
+	m.scrollTo(start + n)
+}
+
+func (m *session) scrollTo(start int) {
+	bottom := len(m.outputBuf) - m.viewHeight()
+	if start >= bottom {
+		m.pinned = false
+		return
+	}
+	m.pinned, m.top = true, max(start, 0)+m.spilled
+}
+
+// startSearch opens the search prompt in place of the command prompt.
+func (m *session) startSearch() {
+	m.searching = true
+	m.query = nil
+	m.match = -1
+	m.search.SetValue("")
+	m.search.Focus()
+}
+
+func (m *session) endSearch() {
+	m.searching = false
+	m.query = nil
+	m.match = -1
+	m.search.Blur()
+}
+
+// setQuery compiles what is typed at the search prompt and jumps to the
+// newest match. Matching ignores case unless the query has capitals.
+func (m *session) setQuery(q string) {
+	m.query, m.match = nil, -1
+	if q == "" {
+		return
+	}
+	expr := regexp.QuoteMeta(q)
+	if strings.ToLower(q) == q {
+		expr = "(?i)" + expr
+	}
+	m.query = regexp.MustCompile(expr)
+	m.findMatch(len(m.outputBuf), -1)
+}
+
+// findMatch moves to the nearest line before from (dir -1) or after it
+// (dir 1) that matches the query, and scrolls it into the middle of the
+// viewport. It reports whether there was one.
+func (m *session) findMatch(from, dir int) bool {
+	if m.query == nil {
+		return false
+	}
+	for i := from + dir; i >= 0 && i < len(m.outputBuf); i += dir {
+		if m.query.MatchString(m.outputBuf[i].text) {
+			m.match = i + m.spilled
+			m.scrollTo(i - m.viewHeight()/2)
+			return true
+		}
+	}
+	return false
+}
+
+// nextMatch steps from the current match to an older (dir -1) or newer
+// (dir 1) one.
+func (m *session) nextMatch(dir int) {
+	from := len(m.outputBuf)
+	if m.match >= 0 {
+		from = max(m.match-m.spilled, -1)
+	}
+	m.findMatch(from, dir)
+}
+
+// matchCount returns how many lines match the query, and the position
+// of the current match among them, counted from the oldest.
+func (m session) matchCount() (n, cur int) {
+	if m.query == nil {
+		return 0, 0
+	}
+	for i, l := range m.outputBuf {
+		if m.query.MatchString(l.text) {
+			n++
+			if i+m.spilled == m.match {
+				cur = n
+			}
+		}
+	}
+	return n, cur
+}
+
+// renderLine styles one line of scrollback, highlighting search matches.
+func (m session) renderLine(i int, style lipgloss.Style) string {
+	text := sanitizeForUI(m.outputBuf[i].text)
+	if m.query == nil {
+		return style.Render(text)
+	}
+	locs := m.query.FindAllStringIndex(text, -1)
+	if len(locs) == 0 {
+		return style.Render(text)
+	}
+	hl := matchStyle
+	if i+m.spilled == m.match {
+		hl = curMatchStyle
+	}
+	sb := &strings.Builder{}
+	last := 0
+	for _, loc := range locs {
+		if loc[0] == loc[1] {
+			continue
+		}
+		sb.WriteString(style.Render(text[last:loc[0]]))
+		sb.WriteString(hl.Render(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	sb.WriteString(style.Render(text[last:]))
+	return sb.String()
+}
+
+// scrollFooter says where the viewport is when it is not at the bottom,
+// and how searching is going.
+func (m session) scrollFooter() string {
+	if m.rsearching {
+		return m.reverseSearchFooter()
+	}
+	if m.searching {
+		n, cur := m.matchCount()
+		switch {
+		case m.query == nil:
+			return "search: type to find — Esc closes"
+		case n == 0:
+			return "search: no matches — Esc closes"
+		}
+		return fmt.Sprintf("search: match %d of %d — Enter/↑ older, ↓ newer, Esc closes", cur, n)
+	}
+	if m.pinned {
+		start, end := m.visible()
+		return fmt.Sprintf("lines %d–%d of %d — PgUp/PgDn scroll, Ctrl+End returns", start+m.spilled+1, end+m.spilled, len(m.outputBuf)+m.spilled)
+	}
+	return ""
+}
+
+// scrollKey moves the viewport for the paging keys and reports whether
+// key was one. Home and End page only when there is no input for them
+// to move the cursor in.
+func (m *session) scrollKey(key string) bool {
+	page := m.viewHeight() - 1
+	switch key {
+	case "pgup":
+		m.scrollBy(-page)
+	case "pgdown":
+		m.scrollBy(page)
+	case "ctrl+home":
+		m.scrollTo(0)
+	case "ctrl+end":
+		m.pinned = false
+	case "home", "end":
+		if m.searching || m.input.Value() != "" {
+			return false
+		}
+		if key == "home" {
+			m.scrollTo(0)
+		} else {
+			m.pinned = false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// searchKey handles a key typed at the search prompt.
+func (m session) searchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.endSearch()
+		return m, nil
+	case "enter", "up", "ctrl+p":
+		m.nextMatch(-1)
+		return m, nil
+	case "down", "ctrl+n":
+		m.nextMatch(1)
+		return m, nil
+	}
+	prev := m.search.Value()
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	if m.search.Value() != prev {
+		m.setQuery(m.search.Value())
+	}
+	return m, cmd
+}
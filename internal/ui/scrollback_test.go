@@ -0,0 +1,136 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// feed appends n numbered lines to m, capping after each as the UI
+// does.
+func feed(m *session, from, n int) {
+	for i := from; i < from+n; i++ {
+		m.outputBuf = append(m.outputBuf, outLine{text: fmt.Sprintf("line %d", i)})
+		m.capScrollback()
+	}
+}
+
+func TestCapScrollbackSpills(t *testing.T) {
+	spillDir = t.TempDir()
+	m := &session{height: 28, scrollCap: 100}
+	defer m.dropSpill()
+	feed(m, 0, 250)
+	if len(m.outputBuf) > m.scrollCap {
+		t.Errorf("kept %d lines, cap is %d", len(m.outputBuf), m.scrollCap)
+	}
+	if m.spilled+len(m.outputBuf) != 250 {
+		t.Fatalf("spilled %d + kept %d != 250", m.spilled, len(m.outputBuf))
+	}
+	if got, want := m.outputBuf[0].text, fmt.Sprintf("line %d", m.spilled); got != want {
+		t.Errorf("oldest line kept is %q, want %q", got, want)
+	}
+	b, err := os.ReadFile(m.spillFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) != m.spilled {
+		t.Fatalf("spill file has %d lines, want %d", len(lines), m.spilled)
+	}
+	for i, l := range lines {
+		if l != fmt.Sprintf("line %d", i) {
+			t.Fatalf("spill file line %d is %q", i, l)
+		}
+	}
+	if note := m.spillNote(); !strings.Contains(note, fmt.Sprintf("%d older lines saved to %s", m.spilled, m.spillFile)) {
+		t.Errorf("spill note: %q", note)
+	}
+
+	file := m.spillFile
+	m.dropSpill()
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("dropSpill left %s: %v", file, err)
+	}
+}
+
+// TestCapScrollbackKeepsView checks that a pinned viewport and the
+// current search match stay on the same lines when older ones spill.
+func TestCapScrollbackKeepsView(t *testing.T) {
+	spillDir = t.TempDir()
+	m := &session{height: 28, scrollCap: 100}
+	defer m.dropSpill()
+	feed(m, 0, 100)
+	m.setQuery("line 95")
+	m.scrollTo(40)
+	start, _ := m.visible()
+	want := m.outputBuf[start].text
+	feed(m, 100, 30)
+	if m.spilled == 0 {
+		t.Fatal("nothing spilled")
+	}
+	start, _ = m.visible()
+	if got := m.outputBuf[start].text; got != want || !m.pinned {
+		t.Errorf("pinned view moved from %q to %q", want, got)
+	}
+	if got := m.outputBuf[m.match-m.spilled].text; got != "line 95" {
+		t.Errorf("current match is %q, want line 95", got)
+	}
+	if n, cur := m.matchCount(); n != 1 || cur != 1 {
+		t.Errorf("matchCount = %d, %d; want 1, 1", n, cur)
+	}
+	m.scrollBy(1000)
+	if m.pinned {
+		t.Errorf("scrolling to the bottom did not follow new output")
+	}
+}
+
+func TestSpillError(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	spillDir = filepath.Join(blocker, "scrollback")
+	m := &session{scrollCap: 10}
+	feed(m, 0, 20)
+	if m.spillErr == nil || len(m.outputBuf) > m.scrollCap {
+		t.Fatalf("spillErr = %v with %d lines kept", m.spillErr, len(m.outputBuf))
+	}
+	if note := m.spillNote(); !strings.Contains(note, "older lines dropped") {
+		t.Errorf("spill note: %q", note)
+	}
+}
+
+func TestPruneSpills(t *testing.T) {
+	spillDir = t.TempDir()
+	old, fresh := filepath.Join(spillDir, "old.log"), filepath.Join(spillDir, "fresh.log")
+	for _, p := range []string{old, fresh} {
+		if err := os.WriteFile(p, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-2 * spillKeep)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+	pruneSpills()
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("old spill file kept: %v", err)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("fresh spill file removed: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+	"github.com/0xrootAnon/0xRootShell/internal/engine"
+)
+
+// statusPoll is how often the status bar rereads what it takes files or
+// system calls to know: the focus session, reminders and the network.
+const statusPoll = 5 * time.Second
+
+// statusTickMsg redraws the status bar every second.
+type statusTickMsg time.Time
+
+// statusMsg is what a poll found.
+type statusMsg statusInfo
+
+type statusInfo struct {
+	at        time.Time
+	focusEnd  time.Time
+	remind    commands.Reminder
+	hasRemind bool
+	net       string
+}
+
+func statusTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return statusTickMsg(t) })
+}
+
+// pollStatus gathers the slow segments off the UI goroutine.
+func pollStatus() tea.Cmd {
+	return func() tea.Msg {
+		st := statusInfo{at: time.Now(), net: commands.NetStatus()}
+		st.focusEnd, _ = commands.FocusEnd()
+		st.remind, st.hasRemind = commands.NextReminder(st.at)
+		return statusMsg(st)
+	}
+}
+
+// statusUpdate handles the ticker: it only redraws, and polls when the
+// last poll is old, so typing is never interrupted. What a poll finds is
+// the same for every tab.
+func (m Model) statusUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case statusTickMsg:
+		if len(m.tabs[m.active].statusSegs) == 0 || m.statusPolling || time.Time(msg).Sub(m.status.at) < statusPoll {
+			return m, statusTick()
+		}
+		m.statusPolling = true
+		return m, tea.Batch(statusTick(), pollStatus())
+	case statusMsg:
+		m.status = statusInfo(msg)
+		m.statusPolling = false
+		for i := range m.tabs {
+			m.tabs[i].status = m.status
+		}
+	}
+	return m, nil
+}
+
+// loadStatus picks up the segments chosen with `set status`.
+func (m *session) loadStatus() {
+	m.statusSegs = nil
+	if v := m.engine.Setting("status"); v != "off" {
+		m.statusSegs = strings.Fields(v)
+	}
+}
+
+// statusBar renders the chosen segments that have something to show. The
+// working directory is shortened from the left to fit the width, down to
+// its last 16 characters; what still does not fit is cut off.
+func (m session) statusBar(now time.Time) string {
+	var parts []string
+	cwd := -1
+	for _, seg := range m.statusSegs {
+		s := m.statusSegment(seg, now)
+		if s == "" {
+			continue
+		}
+		if seg == "cwd" {
+			cwd = len(parts)
+		}
+		parts = append(parts, s)
+	}
+	line := strings.Join(parts, " │ ")
+	if over := utf8.RuneCountInString(line) - m.width; m.width > 0 && over > 0 {
+		if cwd >= 0 {
+			r := []rune(parts[cwd])
+			if cut := min(over+1, len(r)-16); cut > 0 {
+				parts[cwd] = "…" + string(r[cut:])
+			}
+			line = strings.Join(parts, " │ ")
+		}
+		if r := []rune(line); len(r) > m.width {
+			line = string(r[:m.width-1]) + "…"
+		}
+	}
+	return line
+}
+
+func (m session) statusSegment(seg string, now time.Time) string {
+	switch seg {
+	case "cwd":
+		return tildePath(m.engine.Cwd())
+	case "clock":
+		return now.Format("15:04")
+	case "jobs":
+		n := 0
+		for _, j := range m.engine.Jobs() {
+			if j.State() == engine.JobRunning {
+				n++
+			}
+		}
+		switch n {
+		case 0:
+			return ""
+		case 1:
+			return "1 job"
+		}
+		return fmt.Sprintf("%d jobs", n)
+	case "focus":
+		left := m.status.focusEnd.Sub(now).Round(time.Second)
+		if left <= 0 {
+			return ""
+		}
+		if left >= time.Hour {
+			return fmt.Sprintf("focus %d:%02d:%02d", int(left.Hours()), int(left.Minutes())%60, int(left.Seconds())%60)
+		}
+		return fmt.Sprintf("focus %02d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+	case "remind":
+		if !m.status.hasRemind {
+			return ""
+		}
+		r := m.status.remind
+		text := r.Text
+		if utf8.RuneCountInString(text) > 24 {
+			text = string([]rune(text)[:23]) + "…"
+		}
+		due := r.Due.Local()
+		switch {
+		case due.Before(now):
+			return "remind: " + text + " (overdue)"
+		case due.YearDay() == now.YearDay() && due.Year() == now.Year():
+			return "remind: " + text + " at " + due.Format("15:04")
+		}
+		return "remind: " + text + " " + due.Format("Jan 2 15:04")
+	case "net":
+		if m.status.net == "" {
+			return ""
+		}
+		return "net: " + m.status.net
+	}
+	return ""
+}
+
+// tildePath writes a path under the home directory with ~.
+func tildePath(p string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return p
+	}
+	if p == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(p, home+string(filepath.Separator)); ok {
+		return "~" + string(filepath.Separator) + rest
+	}
+	return p
+}
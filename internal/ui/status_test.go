@@ -0,0 +1,128 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+	"github.com/0xrootAnon/0xRootShell/internal/engine"
+	"github.com/0xrootAnon/0xRootShell/internal/store"
+)
+
+// statusSession is a session in home/projects/rootshell with a command,
+// wait, that runs until the session closes.
+func statusSession(t *testing.T) session {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	dir := filepath.Join(home, "projects", "rootshell")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	st, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := engine.NewEngine(st, make(chan string, 100))
+	t.Cleanup(func() { e.Close(); st.Close() })
+	err = e.Registry().Register(commands.Command{
+		Name:  "wait",
+		Async: true,
+		Start: func(ctx context.Context, args []string, ch chan string) error {
+			<-ctx.Done()
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session{engine: e, statusSegs: engine.StatusSegments}
+}
+
+func TestStatusSegments(t *testing.T) {
+	m := statusSession(t)
+	now := time.Date(2025, 10, 21, 9, 5, 0, 0, time.Local)
+	sep := string(filepath.Separator)
+	for _, tc := range []struct {
+		seg, want string
+	}{
+		{"cwd", "~" + sep + "projects" + sep + "rootshell"},
+		{"clock", "09:05"},
+		{"jobs", ""},
+		{"focus", ""},
+		{"remind", ""},
+		{"net", ""},
+	} {
+		if got := m.statusSegment(tc.seg, now); got != tc.want {
+			t.Errorf("%s: %q, want %q", tc.seg, got, tc.want)
+		}
+	}
+
+	m.engine.Execute("wait &")
+	m.engine.Execute("wait &")
+	m.status = statusInfo{net: "wifi home", hasRemind: true}
+	for _, tc := range []struct {
+		seg      string
+		focusEnd time.Duration
+		due      time.Time
+		text     string
+		want     string
+	}{
+		{seg: "jobs", want: "2 jobs"},
+		{seg: "net", want: "net: wifi home"},
+		{seg: "focus", focusEnd: 25 * time.Minute, want: "focus 25:00"},
+		{seg: "focus", focusEnd: 90*time.Minute + 5*time.Second, want: "focus 1:30:05"},
+		{seg: "focus", focusEnd: -time.Second, want: ""},
+		{seg: "remind", text: "stand up", due: now.Add(time.Hour), want: "remind: stand up at 10:05"},
+		{seg: "remind", text: "stand up", due: now.Add(-time.Minute), want: "remind: stand up (overdue)"},
+		{seg: "remind", text: "renew the domain name!!", due: now.AddDate(0, 0, 3), want: "remind: renew the domain name!! Oct 24 09:05"},
+		{seg: "remind", text: "a reminder much too long to fit", due: now.Add(time.Hour), want: "remind: a reminder much too lon… at 10:05"},
+	} {
+		m.status.focusEnd = now.Add(tc.focusEnd)
+		m.status.remind = commands.Reminder{Text: tc.text, Due: tc.due}
+		if got := m.statusSegment(tc.seg, now); got != tc.want {
+			t.Errorf("%s: %q, want %q", tc.seg, got, tc.want)
+		}
+	}
+}
+
+func TestStatusBarWidth(t *testing.T) {
+	m := statusSession(t)
+	now := time.Date(2025, 10, 21, 9, 5, 0, 0, time.Local)
+	m.statusSegs = []string{"cwd", "clock", "jobs"}
+	sep := string(filepath.Separator)
+	cwd := "~" + sep + "projects" + sep + "rootshell"
+	if got, want := m.statusBar(now), cwd+" │ 09:05"; got != want {
+		t.Errorf("wide: %q, want %q", got, want)
+	}
+	// The directory gives way first, from the left.
+	m.width = 25
+	if got := m.statusBar(now); got != "…ojects"+sep+"rootshell │ 09:05" {
+		t.Errorf("width 25: %q", got)
+	}
+	// It keeps its last 16 characters; the line is cut after that.
+	m.width = 24
+	if got := m.statusBar(now); got != "…ojects"+sep+"rootshell │ 09:…" {
+		t.Errorf("width 24: %q", got)
+	}
+	m.statusSegs = nil
+	if got := m.statusBar(now); got != "" {
+		t.Errorf("no segments: %q", got)
+	}
+}
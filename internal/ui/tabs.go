@@ -0,0 +1,311 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/0xrootAnon/0xRootShell/internal/engine"
+	"github.com/0xrootAnon/0xRootShell/internal/store"
+)
+
+// Model is the rootshell window: one or more sessions shown as tabs, of
+// which one is active. The store, and so the history and settings, is
+// shared by all of them. Ctrl+T opens a tab, Ctrl+Tab and Ctrl+Shift+Tab
+// go to the next and previous one, as do Ctrl+PgDn and Ctrl+PgUp in
+// terminals that send Ctrl+Tab as a plain Tab, Alt+1..9 to a given one,
+// and Ctrl+W closes the active one.
+type Model struct {
+	store  *store.Store
+	ascii  string
+	tabs   []session
+	active int
+	nextID int
+
+	width  int
+	height int
+
+	// status is what the last status poll found, for every tab.
+	// closeArmed is set by a first Ctrl+W.
+	status        statusInfo
+	statusPolling bool
+	closeArmed    bool
+}
+
+// ctrlTabKeys are the sequences of terminals that tell Ctrl+Tab and
+// Ctrl+Shift+Tab from Tab, in the CSI u and the xterm modifyOtherKeys
+// encodings, with the way they move between tabs. bubbletea does not
+// know them and passes each on as an unknown sequence, which it prints
+// as csiName does.
+var ctrlTabKeys = map[string]int{
+	csiName("9;5u"):    1,
+	csiName("27;5;9~"): 1,
+	csiName("9;6u"):    -1,
+	csiName("27;6;9~"): -1,
+}
+
+func csiName(params string) string {
+	return fmt.Sprintf("?CSI%+v?", []byte(params))
+}
+
+// tabMsg is a message for the tab with the given id, so the result of a
+// command reaches its tab even if another is active by then.
+type tabMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+func NewModel(st *store.Store, ascii string) Model {
+	pruneSpills()
+	m := Model{store: st, ascii: ascii, nextID: 1, statusPolling: true}
+	s := newSession(st, ascii, "", true)
+	s.id = m.nextID
+	m.tabs = []session{s}
+	return m
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(tagged(m.tabs[0].id, m.tabs[0].Init()), statusTick(), pollStatus())
+}
+
+// tagged wraps cmd so that what it returns goes to tab id.
+func tagged(id int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case tea.BatchMsg:
+			out := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				out[i] = tagged(id, c)
+			}
+			return out
+		case tea.QuitMsg:
+			return msg
+		default:
+			return tabMsg{id: id, msg: msg}
+		}
+	}
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if len(m.tabs) == 0 {
+		return m, nil // quitting
+	}
+	switch msg := msg.(type) {
+	case tabMsg:
+		for i := range m.tabs {
+			if m.tabs[i].id != msg.id {
+				continue
+			}
+			if i != m.active {
+				switch msg.msg.(type) {
+				case asyncMsg, execDoneMsg, promptMsg:
+					m.tabs[i].activity = true
+				}
+			}
+			return m.forward(i, msg.msg)
+		}
+		return m, nil // the tab was closed
+	case statusTickMsg, statusMsg:
+		return m.statusUpdate(msg)
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() != "ctrl+w" {
+			m.closeArmed = false
+		}
+		switch msg.String() {
+		case "ctrl+t":
+			return m.openTab()
+		case "ctrl+pgdown":
+			return m.switchTab(m.active + 1), nil
+		case "ctrl+pgup":
+			return m.switchTab(m.active - 1), nil
+		case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+			if n := int(msg.String()[4] - '1'); n < len(m.tabs) {
+				return m.switchTab(n), nil
+			}
+			return m, nil
+		case "ctrl+w":
+			if m.tabs[m.active].input.Value() == "" {
+				return m.closeKey()
+			}
+		}
+	case fmt.Stringer:
+		if dir, ok := ctrlTabKeys[msg.String()]; ok {
+			m.closeArmed = false
+			return m.switchTab(m.active + dir), nil
+		}
+	}
+	return m.forward(m.active, msg)
+}
+
+// forward hands msg to tab i. A command that has finished may have
+// changed settings, which the other tabs then pick up too.
+func (m Model) forward(i int, msg tea.Msg) (tea.Model, tea.Cmd) {
+	s, cmd := m.tabs[i].Update(msg)
+	m.tabs[i] = s.(session)
+	cmd = tagged(m.tabs[i].id, cmd)
+	switch msg.(type) {
+	case execDoneMsg, rcDoneMsg:
+		for j := range m.tabs {
+			if j != i {
+				m.tabs[j].loadSettings()
+			}
+		}
+	}
+	if m.tabs[i].closed {
+		return m.closeTab(i, cmd)
+	}
+	return m, cmd
+}
+
+// openTab starts a session in the working directory of the active one.
+func (m Model) openTab() (tea.Model, tea.Cmd) {
+	m.nextID++
+	s := newSession(m.store, m.ascii, m.tabs[m.active].engine.Cwd(), false)
+	s.id = m.nextID
+	s.status = m.status
+	m.tabs = append(m.tabs, s)
+	m = m.switchTab(len(m.tabs) - 1)
+	m.resize()
+	if len(m.tabs) == 2 {
+		t := &m.tabs[m.active]
+		t.outputBuf = append(t.outputBuf, outLine{text: "(Ctrl+Tab, Ctrl+PgUp/PgDn or Alt+1..9 switch tabs, Ctrl+W closes one)", kind: lineWarn})
+	}
+	return m, tagged(s.id, s.Init())
+}
+
+// closeKey closes the active tab on a second Ctrl+W, saying first what
+// closing it stops.
+func (m Model) closeKey() (tea.Model, tea.Cmd) {
+	if m.closeArmed {
+		return m.closeTab(m.active, nil)
+	}
+	m.closeArmed = true
+	s := &m.tabs[m.active]
+	note := "(press Ctrl+W again to close this tab)"
+	if len(m.tabs) == 1 {
+		note = "(press Ctrl+W again to quit)"
+	}
+	if n := s.busy(); n > 0 {
+		note = fmt.Sprintf("(press Ctrl+W again to close this tab and stop %d running command(s))", n)
+	}
+	s.outputBuf = append(s.outputBuf, outLine{text: note, kind: lineWarn})
+	return m, nil
+}
+
+// closeTab stops what tab i runs, which also ends its wait for output
+// and questions, and removes it with its spilled scrollback; closing the
+// last one quits.
+func (m Model) closeTab(i int, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	m.closeArmed = false
+	m.tabs[i].engine.Close()
+	m.tabs[i].dropSpill()
+	m.tabs = append(m.tabs[:i:i], m.tabs[i+1:]...)
+	if len(m.tabs) == 0 {
+		return m, tea.Quit
+	}
+	if m.active > i || m.active == len(m.tabs) {
+		m.active--
+	}
+	m = m.switchTab(m.active)
+	m.resize()
+	return m, cmd
+}
+
+// switchTab makes tab i active, wrapping around at either end.
+func (m Model) switchTab(i int) Model {
+	m.active = (i + len(m.tabs)) % len(m.tabs)
+	m.tabs[m.active].activity = false
+	return m
+}
+
+// resize gives each tab the window, less a line for the tab bar when
+// there is more than one.
+func (m *Model) resize() {
+	h := m.height
+	if len(m.tabs) > 1 {
+		h--
+	}
+	for i := range m.tabs {
+		m.tabs[i].width, m.tabs[i].height = m.width, h
+		m.tabs[i].inTabs = len(m.tabs) > 1
+	}
+}
+
+// busy counts the commands the session is running, in the foreground and
+// in the background.
+func (m session) busy() int {
+	n := 0
+	if m.running {
+		n++
+	}
+	for _, j := range m.engine.Jobs() {
+		if j.State() == engine.JobRunning {
+			n++
+		}
+	}
+	return n
+}
+
+func (m Model) View() string {
+	switch len(m.tabs) {
+	case 0:
+		return ""
+	case 1:
+		return m.tabs[0].View()
+	}
+	return m.tabBar() + "\n" + m.tabs[m.active].View()
+}
+
+// tabBar names each tab after its working directory. A dot marks output
+// that arrived in the background, an ellipsis a tab still running
+// something.
+func (m Model) tabBar() string {
+	sb := &strings.Builder{}
+	used := 0
+	for i, s := range m.tabs {
+		name := filepath.Base(tildePath(s.engine.Cwd()))
+		label := fmt.Sprintf(" %d %s", i+1, name)
+		if s.busy() > 0 {
+			label += " …"
+		}
+		if s.activity {
+			label += " ●"
+		}
+		label += " "
+		if used += utf8.RuneCountInString(label) + 1; m.width > 0 && used > m.width {
+			break
+		}
+		style := footerStyle
+		switch {
+		case i == m.active:
+			style = popupSelStyle
+		case s.activity:
+			style = warnStyle
+		}
+		sb.WriteString(style.Render(label) + " ")
+	}
+	return sb.String()
+}
@@ -0,0 +1,120 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/0xrootAnon/0xRootShell/internal/store"
+)
+
+// testTabs is a window with n tabs, the first active.
+func testTabs(t *testing.T, n int) Model {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	st, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	m := NewModel(st, "")
+	for len(m.tabs) < n {
+		next, _ := m.openTab()
+		m = next.(Model)
+	}
+	t.Cleanup(func() {
+		for _, s := range m.tabs {
+			s.engine.Close()
+		}
+	})
+	return m.switchTab(0)
+}
+
+// recorder keeps the messages a program reads from its input, other than
+// keys, until it has want of them.
+type recorder struct {
+	want int
+	msgs *[]tea.Msg
+}
+
+func (r recorder) Init() tea.Cmd { return nil }
+func (r recorder) View() string  { return "" }
+
+func (r recorder) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg.(type) {
+	case tea.KeyMsg, tea.WindowSizeMsg:
+		return r, nil
+	}
+	*r.msgs = append(*r.msgs, msg)
+	if len(*r.msgs) == r.want {
+		return r, tea.Quit
+	}
+	return r, nil
+}
+
+// TestCtrlTab reads Ctrl+Tab and Ctrl+Shift+Tab as terminals that report
+// them send them, and checks that they switch tabs.
+func TestCtrlTab(t *testing.T) {
+	seqs := []string{"\x1b[9;5u", "\x1b[9;5u", "\x1b[9;6u", "\x1b[27;5;9~", "\x1b[27;6;9~", "\x1b[27;6;9~"}
+	var msgs []tea.Msg
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	p := tea.NewProgram(recorder{want: len(seqs), msgs: &msgs}, tea.WithContext(ctx),
+		tea.WithInput(strings.NewReader(strings.Join(seqs, ""))), tea.WithoutRenderer(), tea.WithoutSignalHandler())
+	if _, err := p.Run(); err != nil {
+		t.Fatalf("reading the keys: %v (got %d messages)", err, len(msgs))
+	}
+
+	m := testTabs(t, 3)
+	want := []int{1, 2, 1, 2, 1, 0}
+	for i, msg := range msgs {
+		next, _ := m.Update(msg)
+		m = next.(Model)
+		if m.active != want[i] {
+			t.Errorf("after %q: tab %d active, want %d", seqs[i], m.active, want[i])
+		}
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if next.(Model).active != 0 {
+		t.Error("Shift+Tab switched tabs")
+	}
+}
+
+// TestCloseTabListeners checks that closing a tab ends the commands that
+// wait for its output and questions.
+func TestCloseTabListeners(t *testing.T) {
+	m := testTabs(t, 2)
+	s := m.tabs[1]
+	ended := make(chan tea.Msg, 2)
+	go func() { ended <- listenCmd(s.asyncCh, s.engine.Closed())() }()
+	go func() { ended <- listenPrompts(s.engine.Prompts(), s.engine.Closed())() }()
+	next, _ := m.closeTab(1, nil)
+	if len(next.(Model).tabs) != 1 {
+		t.Fatal("tab not closed")
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-ended:
+			if msg != nil {
+				t.Errorf("listener returned %#v", msg)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("a listener is still waiting after its tab closed")
+		}
+	}
+}
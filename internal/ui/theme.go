@@ -0,0 +1,326 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/0xrootAnon/0xRootShell/internal/commands"
+	"github.com/0xrootAnon/0xRootShell/internal/store"
+)
+
+// themeFile holds user palettes, keyed by name. Each may name a built-in
+// theme as its base and override any of its colors, given as "#rrggbb"
+// or as {"true": "#rrggbb", "256": "208", "16": "11"} to choose what
+// terminals with fewer colors show.
+const themeFile = "data/themes.json"
+
+const defaultTheme = "matrix"
+
+// Theme is the palette the TUI is drawn with.
+type Theme struct {
+	Base    string     `json:"base,omitempty"`
+	Art     themeColor `json:"art"`
+	Output  themeColor `json:"output"`
+	Prompt  themeColor `json:"prompt"`
+	Footer  themeColor `json:"footer"`
+	Error   themeColor `json:"error"`
+	Warning themeColor `json:"warning"`
+	Async   themeColor `json:"async"`
+	Accent  themeColor `json:"accent"`
+	Select  themeColor `json:"select"`
+	Match   themeColor `json:"match"`
+}
+
+// themeColor is a color with the values to use at 256 and 16 colors, so
+// a palette degrades the way it was meant to rather than to the nearest
+// match.
+type themeColor struct {
+	lipgloss.CompleteColor
+}
+
+func rgb(hex, c256, c16 string) themeColor {
+	return themeColor{lipgloss.CompleteColor{TrueColor: hex, ANSI256: c256, ANSI: c16}}
+}
+
+func (c *themeColor) UnmarshalJSON(b []byte) error {
+	var hex string
+	if err := json.Unmarshal(b, &hex); err == nil {
+		if termenv.TrueColor.Color(hex) == nil {
+			return fmt.Errorf("invalid color %q", hex)
+		}
+		c.TrueColor = hex
+		c.ANSI256 = colorIndex(termenv.ANSI256.Color(hex))
+		c.ANSI = colorIndex(termenv.ANSI.Color(hex))
+		return nil
+	}
+	var full struct {
+		True string `json:"true"`
+		C256 string `json:"256"`
+		C16  string `json:"16"`
+	}
+	if err := json.Unmarshal(b, &full); err != nil {
+		return errors.New("a color is \"#rrggbb\" or {\"true\", \"256\", \"16\"}")
+	}
+	if err := c.UnmarshalJSON([]byte(strconv.Quote(full.True))); err != nil {
+		return err
+	}
+	if full.C256 != "" {
+		c.ANSI256 = full.C256
+	}
+	if full.C16 != "" {
+		c.ANSI = full.C16
+	}
+	return nil
+}
+
+func colorIndex(c termenv.Color) string {
+	switch c := c.(type) {
+	case termenv.ANSI256Color:
+		return strconv.Itoa(int(c))
+	case termenv.ANSIColor:
+		return strconv.Itoa(int(c))
+	}
+	return ""
+}
+
+var builtinThemes = map[string]Theme{
+	"matrix": {
+		Art:     rgb("#68FF6B", "83", "10"),
+		Output:  rgb("#A8FF60", "155", "10"),
+		Prompt:  rgb("#B2FF9E", "157", "10"),
+		Footer:  rgb("#6BFFB8", "85", "14"),
+		Error:   rgb("#FF5F5F", "203", "9"),
+		Warning: rgb("#FFD75F", "221", "11"),
+		Async:   rgb("#7FFFD4", "122", "14"),
+		Accent:  rgb("#6BFFB8", "85", "14"),
+		Select:  rgb("#68FF6B", "83", "10"),
+		Match:   rgb("#FFD75F", "221", "11"),
+	},
+	"amber": {
+		Art:     rgb("#FFCC00", "220", "11"),
+		Output:  rgb("#FFB000", "214", "11"),
+		Prompt:  rgb("#FFD966", "221", "11"),
+		Footer:  rgb("#CC8400", "172", "3"),
+		Error:   rgb("#FF5F3F", "203", "9"),
+		Warning: rgb("#FFF0A0", "229", "15"),
+		Async:   rgb("#E6A23C", "179", "3"),
+		Accent:  rgb("#FFB000", "214", "11"),
+		Select:  rgb("#FFB000", "214", "11"),
+		Match:   rgb("#FFF2B3", "230", "15"),
+	},
+	"solarized": {
+		Art:     rgb("#268BD2", "32", "4"),
+		Output:  rgb("#93A1A1", "247", "7"),
+		Prompt:  rgb("#2AA198", "36", "6"),
+		Footer:  rgb("#586E75", "242", "8"),
+		Error:   rgb("#DC322F", "160", "1"),
+		Warning: rgb("#B58900", "136", "3"),
+		Async:   rgb("#6C71C4", "62", "5"),
+		Accent:  rgb("#268BD2", "32", "4"),
+		Select:  rgb("#268BD2", "32", "4"),
+		Match:   rgb("#B58900", "136", "3"),
+	},
+	"high-contrast": {
+		Art:     rgb("#FFFFFF", "15", "15"),
+		Output:  rgb("#FFFFFF", "15", "15"),
+		Prompt:  rgb("#FFFF00", "11", "11"),
+		Footer:  rgb("#00FFFF", "14", "14"),
+		Error:   rgb("#FF0000", "9", "9"),
+		Warning: rgb("#FFFF00", "11", "11"),
+		Async:   rgb("#00FFFF", "14", "14"),
+		Accent:  rgb("#FFFFFF", "15", "15"),
+		Select:  rgb("#FFFF00", "11", "11"),
+		Match:   rgb("#00FFFF", "14", "14"),
+	},
+}
+
+// loadThemes returns the built-in themes together with those in
+// themeFile, which may replace them.
+func loadThemes() (map[string]Theme, error) {
+	themes := make(map[string]Theme, len(builtinThemes))
+	for n, t := range builtinThemes {
+		themes[n] = t
+	}
+	b, err := os.ReadFile(themeFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return themes, nil
+	}
+	if err != nil {
+		return themes, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return themes, fmt.Errorf("%s: %v", themeFile, err)
+	}
+	for name, def := range raw {
+		var base struct {
+			Base string `json:"base"`
+		}
+		json.Unmarshal(def, &base)
+		if base.Base == "" {
+			base.Base = defaultTheme
+		}
+		t, ok := builtinThemes[base.Base]
+		if !ok {
+			return themes, fmt.Errorf("%s: %s: no built-in theme '%s'", themeFile, name, base.Base)
+		}
+		if err := json.Unmarshal(def, &t); err != nil {
+			return themes, fmt.Errorf("%s: %s: %v", themeFile, name, err)
+		}
+		themes[strings.ToLower(name)] = t
+	}
+	return themes, nil
+}
+
+var (
+	artStyle      lipgloss.Style
+	outputStyle   lipgloss.Style
+	promptStyle   lipgloss.Style
+	footerStyle   lipgloss.Style
+	errorStyle    lipgloss.Style
+	warnStyle     lipgloss.Style
+	asyncStyle    lipgloss.Style
+	popupStyle    lipgloss.Style
+	popupSelStyle lipgloss.Style
+	curMatchStyle lipgloss.Style
+)
+
+func init() {
+	applyTheme(builtinThemes[defaultTheme])
+}
+
+// detectedProfile is how many colors the terminal said it has, before
+// `set colors` overrides it.
+var detectedProfile = lipgloss.ColorProfile()
+
+var colorProfiles = map[string]termenv.Profile{
+	"16":        termenv.ANSI,
+	"256":       termenv.ANSI256,
+	"truecolor": termenv.TrueColor,
+	"none":      termenv.Ascii,
+}
+
+func setColorProfile(name string) {
+	p, ok := colorProfiles[name]
+	if !ok {
+		p = detectedProfile
+	}
+	lipgloss.SetColorProfile(p)
+}
+
+// applyTheme restyles the TUI.
+func applyTheme(t Theme) {
+	black := rgb("#000000", "16", "0")
+	artStyle = lipgloss.NewStyle().Foreground(t.Art).Bold(true)
+	outputStyle = lipgloss.NewStyle().Foreground(t.Output)
+	promptStyle = lipgloss.NewStyle().Foreground(t.Prompt)
+	footerStyle = lipgloss.NewStyle().Foreground(t.Footer).Italic(true)
+	errorStyle = lipgloss.NewStyle().Foreground(t.Error)
+	warnStyle = lipgloss.NewStyle().Foreground(t.Warning)
+	asyncStyle = lipgloss.NewStyle().Foreground(t.Async)
+	popupStyle = lipgloss.NewStyle().Foreground(t.Accent)
+	popupSelStyle = lipgloss.NewStyle().Foreground(black).Background(t.Select)
+	curMatchStyle = lipgloss.NewStyle().Foreground(black).Background(t.Match)
+}
+
+// setTheme switches to the saved theme if it has changed. A theme that
+// cannot be used is reported once and matrix is used instead.
+func (m *session) setTheme(name string) {
+	if name == "" {
+		name = defaultTheme
+	}
+	if name == m.theme {
+		return
+	}
+	m.theme = name
+	themes, err := loadThemes()
+	if err != nil {
+		m.outputBuf = append(m.outputBuf, outLine{text: "theme: " + err.Error(), kind: lineError})
+	}
+	t, ok := themes[name]
+	if !ok {
+		m.outputBuf = append(m.outputBuf, outLine{text: fmt.Sprintf("theme: no theme '%s', using %s", name, defaultTheme), kind: lineWarn})
+		t = builtinThemes[defaultTheme]
+	}
+	applyTheme(t)
+}
+
+// themeCommand is `theme`, which saves the theme to use; the model picks
+// it up once the command is done, as with other settings.
+func themeCommand(st *store.Store) commands.Command {
+	names := func() ([]string, error) {
+		themes, err := loadThemes()
+		var names []string
+		for n := range themes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return names, err
+	}
+	return commands.Command{
+		Name:        "theme",
+		Subcommands: []string{"list", "set"},
+		Usage:       "theme [list | set <name>]",
+		Summary:     "Change the color theme; add your own in " + themeFile,
+		Examples:    []string{"theme list", "theme set amber", "theme set high-contrast", "set colors 256"},
+		Run: func(ctx context.Context, args []string) commands.Result {
+			cur, _ := st.Setting("theme")
+			if cur == "" {
+				cur = defaultTheme
+			}
+			all, err := names()
+			if err != nil {
+				return commands.Fail("theme: " + err.Error())
+			}
+			if len(args) == 0 || strings.EqualFold(args[0], "list") {
+				lines := make([]string, len(all))
+				for i, n := range all {
+					mark := "  "
+					if n == cur {
+						mark = "* "
+					}
+					lines[i] = mark + n
+				}
+				return commands.OK(strings.Join(lines, "\n"))
+			}
+			if !strings.EqualFold(args[0], "set") || len(args) != 2 {
+				return commands.Fail("theme: usage: theme [list | set <name>]")
+			}
+			name := strings.ToLower(args[1])
+			if i := sort.SearchStrings(all, name); i == len(all) || all[i] != name {
+				return commands.Failf("theme: no theme '%s' (try: %s)", args[1], strings.Join(all, ", "))
+			}
+			if err := st.SetSetting("theme", name); err != nil {
+				return commands.Fail("theme: " + err.Error())
+			}
+			return commands.OK("theme set to " + name)
+		},
+		Complete: func(ctx context.Context, args []string) []commands.Candidate {
+			if len(args) == 1 && strings.EqualFold(args[0], "set") {
+				all, _ := names()
+				return commands.Words(all...)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,119 @@
+// 0xRootShell — A minimalist, aesthetic terminal for creators
+// Copyright (c) 2025 Khwahish Sharma (aka 0xRootAnon)
+//
+// Licensed under the GNU General Public License v3.0 or later (GPLv3+).
+// You may obtain a copy of the License at
+// https://www.gnu.org/licenses/gpl-3.0.html
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func writeThemes(t *testing.T, src string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(themeFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(themeFile, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadThemes(t *testing.T) {
+	writeThemes(t, `{
+		"Dusk": {"base": "amber", "output": "#FF8800", "error": {"true": "#CC0000", "256": "124", "16": "1"}},
+		"plain": {"prompt": "#0000FF"}
+	}`)
+	themes, err := loadThemes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := range builtinThemes {
+		if _, ok := themes[n]; !ok {
+			t.Errorf("built-in theme %s missing", n)
+		}
+	}
+	dusk, ok := themes["dusk"]
+	if !ok {
+		t.Fatal("theme Dusk not loaded under its lower-case name")
+	}
+	if dusk.Art != builtinThemes["amber"].Art {
+		t.Errorf("dusk art = %+v, want amber's", dusk.Art)
+	}
+	// A bare hex color gets the nearest 256- and 16-color values.
+	if want := rgb("#FF8800", "208", "9"); dusk.Output != want {
+		t.Errorf("dusk output = %+v, want %+v", dusk.Output, want)
+	}
+	if want := rgb("#CC0000", "124", "1"); dusk.Error != want {
+		t.Errorf("dusk error = %+v, want %+v", dusk.Error, want)
+	}
+	plain := themes["plain"]
+	if plain.Output != builtinThemes[defaultTheme].Output || plain.Prompt.TrueColor != "#0000FF" {
+		t.Errorf("plain = %+v, want matrix with a blue prompt", plain)
+	}
+	if builtinThemes["amber"].Output.TrueColor != "#FFB000" {
+		t.Error("loading themes changed the built-in amber")
+	}
+}
+
+func TestLoadThemesErrors(t *testing.T) {
+	for _, tc := range []struct{ src, err string }{
+		{`{"x": {"base": "nope"}}`, "no built-in theme 'nope'"},
+		{`{"x": {"output": "green"}}`, `invalid color "green"`},
+		{`{"x": {"output": 12}}`, `a color is "#rrggbb"`},
+		{`{"x": {"output": {"256": "12"}}}`, `invalid color ""`},
+		{`[1]`, themeFile},
+	} {
+		writeThemes(t, tc.src)
+		themes, err := loadThemes()
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: error %v, want %q", tc.src, err, tc.err)
+		}
+		if _, ok := themes[defaultTheme]; !ok {
+			t.Errorf("%s: built-in themes not returned with the error", tc.src)
+		}
+	}
+
+	t.Chdir(t.TempDir())
+	if themes, err := loadThemes(); err != nil || len(themes) != len(builtinThemes) {
+		t.Errorf("without %s: %d themes, %v", themeFile, len(themes), err)
+	}
+}
+
+// TestColorProfile checks that each color depth draws a theme color with
+// the value the theme gives for it.
+func TestColorProfile(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	defer applyTheme(builtinThemes[defaultTheme])
+	applyTheme(builtinThemes["amber"])
+	for _, tc := range []struct{ name, want string }{
+		{"truecolor", "38;2;255;176;0m"},
+		{"256", "38;5;214m"},
+		{"16", "93m"},
+	} {
+		setColorProfile(tc.name)
+		if got := outputStyle.Render("x"); !strings.Contains(got, tc.want) {
+			t.Errorf("colors %s: %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	setColorProfile("none")
+	if got := outputStyle.Render("x"); got != "x" {
+		t.Errorf("colors none: %q", got)
+	}
+	setColorProfile("bogus")
+	if lipgloss.ColorProfile() != detectedProfile {
+		t.Errorf("unknown depth: profile %v, want the detected %v", lipgloss.ColorProfile(), detectedProfile)
+	}
+}